@@ -3,19 +3,35 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 
+	"dbear/internal/client"
 	"dbear/internal/connection"
+	"dbear/internal/redact"
 	"dbear/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
+var connectClient string
+
 var connectCmd = &cobra.Command{
 	Use:   "connect",
 	Short: "Connect to a database",
-	Long:  "Connect to a database using the specified connection name, or select from a list if no name is provided",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Connect to a database using the specified connection name, or the
+default_connection of the config, or select from a list if neither is set.
+
+The session runs in the client set by --client, the "client" of the connection
+or the "client" of the config: usql, psql, pgcli, mysql, mycli, sqlite3,
+litecli, or a command template such as "pgcli {url}". Templates may use the
+{url}, {host}, {port}, {socket}, {database}, {user}, {password} and {file}
+placeholders. {url} leaves the password out, which clients get from
+PGPASSWORD or MYSQL_PWD instead; {password} puts it on the command line.
+
+Without a client, the first installed one of usql and the clients of the
+database type is used, falling back to the client of the official Docker
+image for PostgreSQL and MySQL. Read-only SQLite connections are opened with
+-readonly, which litecli lacks.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var connectionName string
 		var err error
@@ -23,6 +39,15 @@ var connectCmd = &cobra.Command{
 		manager := connection.NewManager(configManager)
 
 		if len(args) == 0 {
+			connectionName, err = manager.DefaultConnection()
+			if err != nil {
+				return fmt.Errorf("failed to load default connection: %w", err)
+			}
+		} else {
+			connectionName = args[0]
+		}
+
+		if connectionName == "" {
 			connections, err := manager.List()
 			if err != nil {
 				return fmt.Errorf("failed to load connections: %w", err)
@@ -32,8 +57,6 @@ var connectCmd = &cobra.Command{
 			if err != nil {
 				return err
 			}
-		} else {
-			connectionName = args[0]
 		}
 
 		conn, err := manager.Get(connectionName)
@@ -45,17 +68,35 @@ var connectCmd = &cobra.Command{
 			return fmt.Errorf("connection '%s' not found", connectionName)
 		}
 
-		connString, err := connection.BuildConnectionString(*conn)
+		if conn.Type == connection.TypeSQLite {
+			if _, err := connection.CheckSQLiteFile(*conn); err != nil {
+				return err
+			}
+		}
+
+		globalClient, err := manager.Client()
 		if err != nil {
-			return fmt.Errorf("failed to build connection string: %w", err)
+			return fmt.Errorf("failed to load client: %w", err)
 		}
 
-		usqlCmd := exec.Command("usql", connString)
-		usqlCmd.Stdin = os.Stdin
-		usqlCmd.Stdout = os.Stdout
-		usqlCmd.Stderr = os.Stderr
+		clientCmd, secret, err := client.Command(*conn, firstNonEmpty(connectClient, conn.Client, globalClient), ui.IsInteractive())
+		if err != nil {
+			return err
+		}
+		defer secret.Remove()
+		clientCmd.Stdin = os.Stdin
+		clientCmd.Stdout = os.Stdout
+		// Clients print the failed connection strings and options to stderr.
+		// A terminal gets it as is, keeping line editing and colours in the
+		// client; anything else gets it masked a line at a time.
+		clientCmd.Stderr = os.Stderr
+		if !ui.IsTerminal(os.Stderr) {
+			clientStderr := redact.NewLineWriter(redactor, stderr)
+			defer clientStderr.Flush()
+			clientCmd.Stderr = clientStderr
+		}
 
-		if err := usqlCmd.Run(); err != nil {
+		if err := clientCmd.Run(); err != nil {
 			return fmt.Errorf("failed to connect: %w", err)
 		}
 
@@ -64,5 +105,6 @@ var connectCmd = &cobra.Command{
 }
 
 func init() {
+	connectCmd.Flags().StringVar(&connectClient, "client", "", "client to run, overriding the configured one")
 	rootCmd.AddCommand(connectCmd)
 }
@@ -34,12 +34,16 @@ var connectionsCreateCmd = &cobra.Command{
 			return fmt.Errorf("invalid database type: %s", conn.Type)
 		}
 
+		if !config.IsValidSSLMode(conn.SSLMode) {
+			return fmt.Errorf("invalid ssl mode: %s", conn.SSLMode)
+		}
+
 		manager := connection.NewManager(configManager)
 		if err := manager.Create(*conn); err != nil {
 			return fmt.Errorf("failed to save connection: %w", err)
 		}
 
-		fmt.Printf("Connection '%s' created successfully.\n", conn.Name)
+		fmt.Fprintf(stdout, "Connection '%s' created successfully.\n", conn.Name)
 		return nil
 	},
 }
@@ -55,7 +59,7 @@ var connectionsListCmd = &cobra.Command{
 			return fmt.Errorf("failed to load connections: %w", err)
 		}
 
-		if err := ui.DisplayConnectionsList(connections); err != nil {
+		if err := ui.DisplayConnectionsList(stdout, connections); err != nil {
 			return fmt.Errorf("failed to display connections: %w", err)
 		}
 
@@ -68,4 +72,5 @@ func init() {
 	connectionsCmd.AddCommand(connectionsCreateCmd)
 	connectionsCmd.AddCommand(connectionsListCmd)
 	connectionsCmd.AddCommand(importCmd)
+	connectionsCmd.AddCommand(exportCmd)
 }
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"dbear/internal/connection"
+	"dbear/internal/query"
+
+	"github.com/spf13/cobra"
+)
+
+var dataExportTable string
+var dataExportQuery string
+var dataExportFormat string
+var dataExportOutput string
+var dataExportTimeout time.Duration
+
+var dataExportCmd = &cobra.Command{
+	Use:   "export <connection>",
+	Short: "Export a table or query results to a CSV, JSON lines or Parquet file",
+	Long: `Export the rows of a table, or of a query, to a file without dumping the
+whole database. Rows are streamed, so exports of any size run in constant
+memory.
+
+Values are written according to their column type: timestamps in RFC 3339,
+binary columns (bytea, blob) in base64 in CSV and JSON lines, and NUMERIC and
+DECIMAL values as strings so that no precision is lost. Parquet files keep
+integers, floats, booleans, timestamps and binary values typed.
+
+The format is taken from --format, or from the extension of --output, and
+defaults to CSV. Without --output, the rows are written to stdout.
+
+Connection bundles, pgpass and other connection files are exported with
+"connections export".`,
+	Example: `  dbear export local --table users -o users.csv
+  dbear export staging --query "SELECT * FROM orders WHERE created_at > now() - interval '7 days'" -o orders.parquet`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if (dataExportTable == "") == (dataExportQuery == "") {
+			return fmt.Errorf("give either --table or --query")
+		}
+
+		format, err := dataExportFileFormat(dataExportFormat, dataExportOutput)
+		if err != nil {
+			return err
+		}
+
+		manager := connection.NewManager(configManager)
+		conn, err := loadConnection(manager, args[0], "export")
+		if err != nil {
+			return err
+		}
+
+		statement := dataExportQuery
+		if dataExportTable != "" {
+			statement = "SELECT * FROM " + query.QuoteIdentifier(conn.Type, dataExportTable)
+		}
+
+		ctx := context.Background()
+		if dataExportTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, dataExportTimeout)
+			defer cancel()
+		}
+
+		db, err := connection.OpenDBContext(ctx, *conn)
+		if err != nil {
+			return fmt.Errorf("failed to connect to '%s': %w", conn.Name, err)
+		}
+		defer db.Close()
+
+		var out io.Writer = os.Stdout
+		toFile := dataExportOutput != "" && dataExportOutput != "-"
+		var file *os.File
+		if toFile {
+			file, err = os.OpenFile(dataExportOutput, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to create export file: %w", err)
+			}
+			out = file
+		}
+
+		writer, err := query.NewRowWriter(format, out)
+		if err == nil {
+			var count int64
+			count, err = query.Export(ctx, db, statement, writer)
+			if err == nil && toFile {
+				fmt.Fprintf(stderr, "Exported %d rows to %s\n", count, dataExportOutput)
+			}
+		}
+
+		if file != nil {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				// A partial export is worse than none
+				os.Remove(dataExportOutput)
+			}
+		}
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("export timed out after %s: %w", dataExportTimeout, err)
+			}
+			return fmt.Errorf("export failed: %w", err)
+		}
+
+		return nil
+	},
+}
+
+// dataExportFileFormat returns the format given, or the one matching the
+// extension of output
+func dataExportFileFormat(format, output string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(output)) {
+		case ".jsonl", ".ndjson":
+			format = query.ExportJSONL
+		case ".parquet":
+			format = query.ExportParquet
+		default:
+			format = query.ExportCSV
+		}
+	}
+
+	for _, valid := range query.ExportFormats {
+		if format == valid {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("invalid format '%s', expected one of %s", format, strings.Join(query.ExportFormats, ", "))
+}
+
+func init() {
+	dataExportCmd.Flags().StringVarP(&dataExportTable, "table", "t", "", "table to export, optionally qualified by its schema")
+	dataExportCmd.Flags().StringVarP(&dataExportQuery, "query", "q", "", "query whose results to export")
+	dataExportCmd.Flags().StringVar(&dataExportFormat, "format", "", "file format: csv, jsonl or parquet (default: from the output extension, else csv)")
+	dataExportCmd.Flags().StringVarP(&dataExportOutput, "output", "o", "", "output file path, - for stdout (default: stdout)")
+	dataExportCmd.Flags().DurationVar(&dataExportTimeout, "timeout", 0, "cancel the export after this long, e.g. 10m (default: none)")
+	rootCmd.AddCommand(dataExportCmd)
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"dbear/internal/catalog"
+	"dbear/internal/connection"
+	"dbear/internal/diff"
+	"dbear/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var diffSchemas string
+var diffSQL bool
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare two databases",
+	Long:  "Compare the structure of two databases of the same type",
+}
+
+var diffSchemaCmd = &cobra.Command{
+	Use:   "schema <source> <destination>",
+	Short: "Compare the schemas of two databases",
+	Long: `Compare the tables, columns, types, defaults, indexes, constraints, views and
+functions of two databases of the same type, as read from their catalogs.
+
+Differences are shown as a tree of what the destination has to change to
+match the source: + for what it lacks, - for what it has in excess and ~ for
+what differs. With --sql, the statements making the destination match the
+source are printed instead, for review before running them with dbear query.
+SQLite cannot alter columns and constraints in place, which is left as
+comments.`,
+	Example: `  dbear diff schema staging local
+  dbear diff schema staging local --schemas public --sql > migrate.sql`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager := connection.NewManager(configManager)
+		sourceConn, err := loadConnection(manager, args[0], "source")
+		if err != nil {
+			return err
+		}
+		destConn, err := loadConnection(manager, args[1], "destination")
+		if err != nil {
+			return err
+		}
+		if sourceConn.Type != destConn.Type {
+			return fmt.Errorf("cannot compare '%s' (%s) with '%s' (%s): both databases must be of the same type",
+				sourceConn.Name, sourceConn.Type, destConn.Name, destConn.Type)
+		}
+
+		source, err := inspectConnection(*sourceConn)
+		if err != nil {
+			return err
+		}
+		dest, err := inspectConnection(*destConn)
+		if err != nil {
+			return err
+		}
+
+		changes, err := diff.Schemas(source, dest, parseCommaSeparatedSchemas(diffSchemas))
+		if err != nil {
+			return err
+		}
+
+		if diffSQL {
+			fmt.Fprint(os.Stdout, diff.SQL(sourceConn.Type, changes))
+			return nil
+		}
+		if len(changes) == 0 {
+			fmt.Fprintf(stdout, "The schemas of '%s' and '%s' are the same.\n", sourceConn.Name, destConn.Name)
+			return nil
+		}
+		ui.DisplaySchemaDiff(stdout, changes)
+		return nil
+	},
+}
+
+// inspectConnection reads the catalog of every schema of conn
+func inspectConnection(conn connection.Connection) (*catalog.Catalog, error) {
+	db, err := connection.OpenDB(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to '%s': %w", conn.Name, err)
+	}
+	defer db.Close()
+
+	c, err := catalog.Inspect(context.Background(), db, conn.Type, catalog.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect '%s': %w", conn.Name, err)
+	}
+	return c, nil
+}
+
+func init() {
+	diffSchemaCmd.Flags().StringVarP(&diffSchemas, "schemas", "s", "", "comma-separated list of schemas to compare (default: all). PostgreSQL only.")
+	diffSchemaCmd.Flags().BoolVar(&diffSQL, "sql", false, "print the statements making the destination match the source")
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.AddCommand(diffSchemaCmd)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"dbear/internal/catalog"
+	"dbear/internal/config"
+	"dbear/internal/connection"
+	"dbear/internal/docs"
+
+	"github.com/spf13/cobra"
+)
+
+var docsSchemas string
+var docsOutput string
+var docsFormat string
+var docsTitle string
+var docsNoStats bool
+
+var docsCmd = &cobra.Command{
+	Use:   "docs <connection>",
+	Short: "Generate documentation of the tables of a database",
+	Long: `Generate a data dictionary from the catalog of a database: one Markdown or
+HTML page per table, with its columns, types, defaults, constraints, indexes,
+comments, row estimate, and the tables it references and is referenced by,
+under an index page linking them all (README.md, or index.html).
+
+Pages are rewritten on every run, and the pages of tables that no longer
+exist are removed, so the output can be committed next to migrations for
+reviewers to see schema changes. Files in the directory that dbear did not
+generate are left alone. Row estimates and sizes change with the data rather
+than the schema: leave them out with --no-stats to keep such diffs quiet.`,
+	Example: `  dbear docs local -o docs/db/
+  dbear docs staging --schemas public --format html -o site/db --no-stats`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !docs.IsValidFormat(docsFormat) {
+			return fmt.Errorf("invalid format '%s', expected markdown or html", docsFormat)
+		}
+
+		manager := connection.NewManager(configManager)
+		conn, err := loadConnection(manager, args[0], "docs")
+		if err != nil {
+			return err
+		}
+
+		db, err := connection.OpenDB(*conn)
+		if err != nil {
+			return fmt.Errorf("failed to connect to '%s': %w", conn.Name, err)
+		}
+		defer db.Close()
+
+		c, err := catalog.Inspect(context.Background(), db, conn.Type, catalog.Options{
+			Schemas: parseCommaSeparatedSchemas(docsSchemas),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to inspect '%s': %w", conn.Name, err)
+		}
+
+		title := docsTitle
+		if title == "" {
+			database := conn.Database
+			if conn.Type == config.TypeSQLite {
+				database = filepath.Base(database)
+			}
+			title = fmt.Sprintf("Database %s", firstNonEmpty(database, conn.Name))
+		}
+		result, err := docs.Generate(c, docsOutput, docs.Options{Format: docsFormat, Title: title, Stats: !docsNoStats})
+		if err != nil {
+			return fmt.Errorf("failed to generate documentation: %w", err)
+		}
+
+		fmt.Fprintf(stderr, "Wrote %d pages to %s\n", len(result.Written), docsOutput)
+		for _, file := range result.Removed {
+			fmt.Fprintf(stderr, "Removed %s, whose table is gone\n", file)
+		}
+		return nil
+	},
+}
+
+func init() {
+	docsCmd.Flags().StringVarP(&docsSchemas, "schemas", "s", "", "comma-separated list of schemas to document (default: all). PostgreSQL only.")
+	docsCmd.Flags().StringVarP(&docsOutput, "output", "o", "docs/db", "directory to write the pages to")
+	docsCmd.Flags().StringVar(&docsFormat, "format", docs.FormatMarkdown, "page format: markdown or html")
+	docsCmd.Flags().StringVar(&docsTitle, "title", "", "title of the index page (default: the name of the database)")
+	docsCmd.Flags().BoolVar(&docsNoStats, "no-stats", false, "leave out row estimates and sizes")
+	rootCmd.AddCommand(docsCmd)
+}
@@ -14,13 +14,22 @@ import (
 
 var dumpOutputPath string
 var dumpSchemas string
+var dumpTables string
+var dumpExcludeTables string
+var dumpYes bool
 
 var dumpCmd = &cobra.Command{
-	Use:   "dump",
+	Use:   "dump [connection]",
 	Short: "Dump a database to a file",
-	Long:  "Dump the selected source database to a file. Uses Docker for PostgreSQL/MySQL and native sqlite3 for SQLite.",
+	Long: `Dump a database to a file. Uses Docker for PostgreSQL/MySQL and native sqlite3 for SQLite.
+
+The connection is asked for unless given as an argument, which scripts and
+cron jobs must do. In a terminal, the schemas and tables to dump are picked
+from a list, unless --schemas, --tables or --exclude-tables is given or --yes
+dumps them all.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("Loading connections...")
+		fmt.Fprintln(stdout, "Loading connections...")
 		manager := connection.NewManager(configManager)
 
 		connections, err := manager.List()
@@ -32,48 +41,66 @@ var dumpCmd = &cobra.Command{
 			return fmt.Errorf("at least 1 connection is required for dump")
 		}
 
-		sourceName, err := ui.SelectConnectionWithTitle(connections, "Select source connection")
-		if err != nil {
-			return fmt.Errorf("failed to select source connection: %w", err)
+		sourceName := ""
+		if len(args) > 0 {
+			sourceName = args[0]
+		} else {
+			sourceName, err = pickConnection(connections, "source")
+			if err != nil {
+				return err
+			}
 		}
 
-		sourceConn, err := manager.Get(sourceName)
+		sourceConn, err := loadConnection(manager, sourceName, "source")
 		if err != nil {
-			return fmt.Errorf("failed to load source connection: %w", err)
+			return err
 		}
 
-		if sourceConn == nil {
-			return fmt.Errorf("source connection '%s' not found", sourceName)
+		options := transfer.DumpOptions{
+			Schemas:       parseCommaSeparatedSchemas(dumpSchemas),
+			Tables:        parseCommaSeparatedList(dumpTables),
+			ExcludeTables: parseCommaSeparatedList(dumpExcludeTables),
 		}
-
-		outputPath := dumpOutputPath
-		if outputPath == "" {
-			timestamp := time.Now().Format("20060102_150405")
-			extension := transfer.DumpFileExtension(sourceConn.Type)
-			outputPath = fmt.Sprintf("dump_%s_%s%s", sourceName, timestamp, extension)
+		if !dumpYes {
+			if err := pickTables(*sourceConn, &options); err != nil {
+				return err
+			}
 		}
+		return runDump(*sourceConn, dumpOutputPath, options)
+	},
+}
 
-		schemas := parseCommaSeparatedSchemas(dumpSchemas)
-		result, err := ui.RunWithSpinner("Dumping database...", func() (interface{}, error) {
-			return transfer.Dump(*sourceConn, schemas)
-		})
-		if err != nil {
-			return fmt.Errorf("dump failed: %w", err)
-		}
+// runDump dumps sourceConn into outputPath, or a timestamped file named after
+// the connection when outputPath is empty
+func runDump(sourceConn connection.Connection, outputPath string, options transfer.DumpOptions) error {
+	if outputPath == "" {
+		timestamp := time.Now().Format("20060102_150405")
+		extension := transfer.DumpFileExtension(sourceConn.Type)
+		outputPath = fmt.Sprintf("dump_%s_%s%s", sourceConn.Name, timestamp, extension)
+	}
 
-		dumpData := result.([]byte)
-		fmt.Println("Writing file...")
-		if err := os.WriteFile(outputPath, dumpData, 0600); err != nil {
-			return fmt.Errorf("failed to write dump file: %w", err)
-		}
+	result, err := ui.RunWithSpinner("Dumping database...", func() (interface{}, error) {
+		return transfer.Dump(sourceConn, options)
+	})
+	if err != nil {
+		return fmt.Errorf("dump failed: %w", err)
+	}
 
-		fmt.Printf("Dump written to %s\n", outputPath)
-		return nil
-	},
+	dumpData := result.([]byte)
+	fmt.Fprintln(stdout, "Writing file...")
+	if err := os.WriteFile(outputPath, dumpData, 0600); err != nil {
+		return fmt.Errorf("failed to write dump file: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "Dump written to %s\n", outputPath)
+	return nil
 }
 
 func init() {
 	dumpCmd.Flags().StringVarP(&dumpOutputPath, "output", "o", "", "output file path (default: dump_<connection>_<timestamp>.<ext>)")
 	dumpCmd.Flags().StringVarP(&dumpSchemas, "schemas", "s", "", "comma-separated list of schemas to include (default: all). PostgreSQL only.")
+	dumpCmd.Flags().StringVarP(&dumpTables, "tables", "t", "", "comma-separated list of tables to include (default: all), * and ? wildcards allowed")
+	dumpCmd.Flags().StringVar(&dumpExcludeTables, "exclude-tables", "", "comma-separated list of tables to leave out, * and ? wildcards allowed")
+	dumpCmd.Flags().BoolVarP(&dumpYes, "yes", "y", false, "dump every schema and table without picking them")
 	rootCmd.AddCommand(dumpCmd)
 }
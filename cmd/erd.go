@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"dbear/internal/catalog"
+	"dbear/internal/connection"
+	"dbear/internal/erd"
+
+	"github.com/spf13/cobra"
+)
+
+var erdSchemas string
+var erdFormat string
+var erdOutput string
+var erdFocus string
+var erdDepth int
+
+var erdCmd = &cobra.Command{
+	Use:   "erd <connection>",
+	Short: "Draw an entity relationship diagram of a database",
+	Long: `Draw the tables of a database, with their columns and keys, linked by their
+foreign keys, as read from its catalog.
+
+Diagrams are written as Mermaid, Graphviz dot or PlantUML source, which
+Markdown renderers and wikis draw, or as an SVG image rendered by the dot
+command of Graphviz, which must be installed for it.
+
+With --focus, only the given table and the tables within --depth foreign keys
+of it are drawn, whichever way the keys point.`,
+	Example: `  dbear erd local > schema.mmd
+  dbear erd staging --schemas public --format svg -o schema.svg
+  dbear erd local --focus orders --depth 1 --format plantuml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !erd.IsValidFormat(erdFormat) {
+			return fmt.Errorf("invalid format '%s', expected one of %s", erdFormat, strings.Join(erd.Formats, ", "))
+		}
+		if erdDepth < 0 {
+			return fmt.Errorf("--depth cannot be negative")
+		}
+
+		manager := connection.NewManager(configManager)
+		conn, err := loadConnection(manager, args[0], "erd")
+		if err != nil {
+			return err
+		}
+
+		db, err := connection.OpenDB(*conn)
+		if err != nil {
+			return fmt.Errorf("failed to connect to '%s': %w", conn.Name, err)
+		}
+		defer db.Close()
+
+		c, err := catalog.Inspect(context.Background(), db, conn.Type, catalog.Options{
+			Schemas: parseCommaSeparatedSchemas(erdSchemas),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to inspect '%s': %w", conn.Name, err)
+		}
+
+		diagram, err := erd.New(c, erdFocus, erdDepth)
+		if err != nil {
+			return err
+		}
+		if diagram.Tables() == 0 {
+			return fmt.Errorf("no tables found in '%s'", conn.Name)
+		}
+
+		// The diagram is written whole, so that a failed render leaves no
+		// partial file behind
+		var out bytes.Buffer
+		if err := diagram.Write(&out, erdFormat); err != nil {
+			return err
+		}
+		if erdOutput == "" {
+			_, err = os.Stdout.Write(out.Bytes())
+			return err
+		}
+		if err := os.WriteFile(erdOutput, out.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write diagram: %w", err)
+		}
+		fmt.Fprintf(stderr, "Wrote a diagram of %d tables to %s\n", diagram.Tables(), erdOutput)
+		return nil
+	},
+}
+
+func init() {
+	erdCmd.Flags().StringVarP(&erdSchemas, "schemas", "s", "", "comma-separated list of schemas to draw (default: all). PostgreSQL only.")
+	erdCmd.Flags().StringVar(&erdFormat, "format", erd.FormatMermaid, "diagram format: mermaid, dot, plantuml or svg")
+	erdCmd.Flags().StringVarP(&erdOutput, "output", "o", "", "file to write the diagram to (default: stdout)")
+	erdCmd.Flags().StringVar(&erdFocus, "focus", "", "draw only this table and its neighbours")
+	erdCmd.Flags().IntVar(&erdDepth, "depth", 2, "number of foreign keys to follow from the --focus table")
+	rootCmd.AddCommand(erdCmd)
+}
@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+	"dbear/internal/exporter"
+	"dbear/internal/importer"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export database connections to files",
+	Long: `Export database connections into the configuration files of other database tools.
+
+With --bundle, connections are written into a shareable team bundle instead:
+a JSON file holding connection definitions without passwords or parameters
+holding secrets, such as sslpassword, meant to be committed and imported by
+teammates with "connections import bundle". Each connection can point at
+where its password is found with --password-sources,
+as name=source pairs where source is env:<VARIABLE>, pgpass or prompt.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bundlePath, _ := cmd.Flags().GetString("bundle")
+		if bundlePath == "" {
+			return cmd.Help()
+		}
+		namesFlag, _ := cmd.Flags().GetString("names")
+		sourcesFlag, _ := cmd.Flags().GetString("password-sources")
+
+		sources := map[string]string{}
+		for _, pair := range parseCommaSeparatedList(sourcesFlag) {
+			name, source, found := strings.Cut(pair, "=")
+			if !found {
+				return fmt.Errorf("invalid password source %q, expected name=source", pair)
+			}
+			sources[strings.TrimSpace(name)] = strings.TrimSpace(source)
+		}
+
+		connections, err := selectExportConnections(parseCommaSeparatedList(namesFlag), "")
+		if err != nil {
+			return err
+		}
+		for name := range sources {
+			if !containsConnection(connections, name) {
+				return fmt.Errorf("password source given for connection '%s', which is not exported", name)
+			}
+		}
+
+		bundleExporter := exporter.NewBundleExporter(sources)
+		if err := bundleExporter.Export(bundlePath, connections); err != nil {
+			return fmt.Errorf("failed to export connections: %w", err)
+		}
+
+		for _, conn := range connections {
+			fmt.Fprintf(stdout, "Connection '%s' exported to %s.\n", conn.Name, bundlePath)
+			for _, key := range bundleExporter.Stripped()[conn.Name] {
+				fmt.Fprintf(stderr, "  Parameter '%s' left out, it holds a secret.\n", key)
+			}
+		}
+		return nil
+	},
+}
+
+// containsConnection reports whether a connection named name is in connections
+func containsConnection(connections []connection.Connection, name string) bool {
+	for _, conn := range connections {
+		if conn.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fileExport describes a `connections export <kind>` subcommand backed by an
+// exporter.Exporter.
+type fileExport struct {
+	kind        string
+	short       string
+	long        string
+	dbType      string
+	defaultPath string
+	newExporter func() exporter.Exporter
+}
+
+var fileExports = []fileExport{
+	{
+		kind:  "pgpass",
+		short: "Export connections to a libpq password file",
+		long: `Write one line per PostgreSQL connection into a libpq password file. Lines
+for the same host, port, database and user are replaced.`,
+		dbType:      config.TypePostgreSQL,
+		defaultPath: importer.PgpassPath(),
+		newExporter: func() exporter.Exporter { return exporter.NewPgpassExporter() },
+	},
+	{
+		kind:  "pgservice",
+		short: "Export connections to a libpq service file",
+		long: `Write one [service] per PostgreSQL connection into a libpq connection service
+file, so "psql service=<name>" reaches the same database as dbear. Existing
+services are updated in place.`,
+		dbType:      config.TypePostgreSQL,
+		defaultPath: importer.PgServicePath(),
+		newExporter: func() exporter.Exporter { return exporter.NewPgServiceExporter() },
+	},
+	{
+		kind:  "mycnf",
+		short: "Export connections to a MySQL option file",
+		long: `Write a [client-<name>] and [mysql-<name>] group per MySQL connection into a
+MySQL option file, for use with "mysql --defaults-group-suffix=-<name>".
+Existing groups are updated in place.`,
+		dbType:      config.TypeMySQL,
+		defaultPath: importer.MyCnfPath(),
+		newExporter: func() exporter.Exporter { return exporter.NewMyCnfExporter() },
+	},
+}
+
+func newFileExportCmd(fe fileExport) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   fe.kind,
+		Short: fe.short,
+		Long:  fe.long + "\n\nThe file is written with 0600 permissions.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filePath, _ := cmd.Flags().GetString("file")
+			namesFlag, _ := cmd.Flags().GetString("names")
+
+			connections, err := selectExportConnections(parseCommaSeparatedList(namesFlag), fe.dbType)
+			if err != nil {
+				return err
+			}
+
+			if err := fe.newExporter().Export(filePath, connections); err != nil {
+				return fmt.Errorf("failed to export connections: %w", err)
+			}
+
+			for _, conn := range connections {
+				fmt.Fprintf(stdout, "Connection '%s' exported to %s.\n", conn.Name, filePath)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("file", fe.defaultPath, "file to write")
+	cmd.Flags().String("names", "", "comma-separated list of connections to export (default: all of the matching type)")
+	return cmd
+}
+
+// selectExportConnections loads the named connections, or all connections of
+// dbType when names is empty. Named connections of another type are an error.
+func selectExportConnections(names []string, dbType string) ([]connection.Connection, error) {
+	manager := connection.NewManager(configManager)
+
+	if len(names) == 0 {
+		all, err := manager.List()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load connections: %w", err)
+		}
+
+		connections := []connection.Connection{}
+		for _, conn := range all {
+			if dbType == "" || conn.Type == dbType {
+				connections = append(connections, conn)
+			}
+		}
+		if len(connections) == 0 && dbType == "" {
+			return nil, fmt.Errorf("no connections to export")
+		}
+		if len(connections) == 0 {
+			return nil, fmt.Errorf("no %s connections to export", dbType)
+		}
+		return connections, nil
+	}
+
+	connections := make([]connection.Connection, 0, len(names))
+	for _, name := range names {
+		conn, err := manager.Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load connection: %w", err)
+		}
+		if conn == nil {
+			return nil, fmt.Errorf("connection '%s' not found", name)
+		}
+		if dbType != "" && conn.Type != dbType {
+			return nil, fmt.Errorf("connection '%s' is a %s connection, expected %s", name, conn.Type, dbType)
+		}
+		connections = append(connections, *conn)
+	}
+
+	return connections, nil
+}
+
+var exportEnvCmd = &cobra.Command{
+	Use:   "env <name>",
+	Short: "Export a connection to a .env file",
+	Long: `Write a connection into the variables of a .env file, the reverse of
+"connections import env". Variables that already exist are updated in place, so
+comments, ordering and other variables are kept; missing ones are appended.
+
+Styles:
+- url: DATABASE_URL
+- laravel: DB_CONNECTION, DB_HOST, DB_PORT, DB_SOCKET, DB_DATABASE,
+  DB_USERNAME, DB_PASSWORD
+- custom-keys: the variables given with --keys, as field=KEY pairs among url,
+  type, host, port, socket, database, username and password, for instance
+  --keys url=READ_DATABASE_URL or --keys host=PGHOST,port=PGPORT,database=PGDATABASE`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filePath, _ := cmd.Flags().GetString("file")
+		style, _ := cmd.Flags().GetString("style")
+		keysFlag, _ := cmd.Flags().GetString("keys")
+
+		var keys exporter.EnvKeys
+		switch style {
+		case "url":
+			keys = exporter.URLEnvKeys
+		case "laravel":
+			keys = exporter.LaravelEnvKeys
+		case "custom-keys":
+			parsed, err := parseEnvKeys(keysFlag)
+			if err != nil {
+				return err
+			}
+			keys = parsed
+		default:
+			return fmt.Errorf("invalid style: %s (expected url, laravel or custom-keys)", style)
+		}
+		if keysFlag != "" && style != "custom-keys" {
+			return fmt.Errorf("--keys requires --style custom-keys")
+		}
+
+		connections, err := selectExportConnections(args, "")
+		if err != nil {
+			return err
+		}
+
+		if err := exporter.NewEnvExporter(keys).Export(filePath, connections); err != nil {
+			return fmt.Errorf("failed to export connection: %w", err)
+		}
+
+		fmt.Fprintf(stdout, "Connection '%s' exported to %s.\n", args[0], filePath)
+		return nil
+	},
+}
+
+// parseEnvKeys parses the field=KEY pairs of the --keys flag
+func parseEnvKeys(spec string) (exporter.EnvKeys, error) {
+	var keys exporter.EnvKeys
+	pairs := parseCommaSeparatedList(spec)
+	if len(pairs) == 0 {
+		return keys, fmt.Errorf("--keys is required with --style custom-keys")
+	}
+
+	fields := map[string]*string{
+		"url":      &keys.URL,
+		"type":     &keys.Type,
+		"host":     &keys.Host,
+		"port":     &keys.Port,
+		"socket":   &keys.Socket,
+		"database": &keys.Database,
+		"username": &keys.Username,
+		"password": &keys.Password,
+	}
+	for _, pair := range pairs {
+		field, key, found := strings.Cut(pair, "=")
+		target, known := fields[strings.TrimSpace(field)]
+		if !found || !known || strings.TrimSpace(key) == "" {
+			return keys, fmt.Errorf("invalid key mapping %q, expected field=KEY with field among url, type, host, port, socket, database, username, password", pair)
+		}
+		*target = strings.TrimSpace(key)
+	}
+
+	return keys, nil
+}
+
+func init() {
+	exportCmd.Flags().String("bundle", "", "write a shareable bundle without passwords to this file")
+	exportCmd.Flags().String("names", "", "comma-separated list of connections to bundle (default: all)")
+	exportCmd.Flags().String("password-sources", "", "comma-separated name=source pairs, source being env:<VARIABLE>, pgpass or prompt")
+
+	exportEnvCmd.Flags().String("file", ".env", "file to write")
+	exportEnvCmd.Flags().String("style", "url", "variables to write: url, laravel or custom-keys")
+	exportEnvCmd.Flags().String("keys", "", "comma-separated field=KEY pairs for --style custom-keys")
+	exportCmd.AddCommand(exportEnvCmd)
+
+	for _, fe := range fileExports {
+		exportCmd.AddCommand(newFileExportCmd(fe))
+	}
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"dbear/internal/connection"
 	"dbear/internal/importer"
@@ -10,6 +12,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var importOnConflict string
+
 var importCmd = &cobra.Command{
 	Use:   "import",
 	Short: "Import database connections from files",
@@ -27,11 +31,23 @@ The form will guide you through:
 3. Configuring the environment variable keys
 
 By default, when using multiple variables, it follows Laravel conventions:
-- DB_HOST, DB_PORT, DB_DATABASE, DB_USERNAME, DB_PASSWORD`,
+- DB_HOST, DB_PORT, DB_DATABASE, DB_USERNAME, DB_PASSWORD
+
+With --scan, every connection of the file is detected instead: each group of
+variables sharing a prefix (DB_*, READ_DB_*, ANALYTICS_DB_*, DATABASE_*,
+POSTGRES_*, MYSQL_*) and each variable holding a connection URL
+(DATABASE_URL, READ_DATABASE_URL...). Connections are named after the
+directory of the file followed by their prefix, and can be picked from a
+preview before being imported.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		filePath := args[0]
 
+		scan, _ := cmd.Flags().GetBool("scan")
+		if scan {
+			return scanEnvFile(filePath)
+		}
+
 		// Get import configuration from interactive form
 		envOptions, err := ui.ImportEnvForm()
 		if err != nil {
@@ -52,19 +68,390 @@ By default, when using multiple variables, it follows Laravel conventions:
 		}
 
 		// Save connections
-		manager := connection.NewManager(configManager)
-		for _, conn := range connections {
-			if err := manager.Create(conn); err != nil {
-				return fmt.Errorf("failed to save connection '%s': %w", conn.Name, err)
+		return saveImportedConnections(connections)
+	},
+}
+
+// scanEnvFile imports the connections detected in an env file, after a preview
+func scanEnvFile(filePath string) error {
+	envImporter := importer.NewEnvImporter()
+	connections, err := envImporter.Scan(filePath, importer.ImportOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", filePath, err)
+	}
+
+	printSkippedEntries(envImporter)
+
+	if len(connections) == 0 {
+		return fmt.Errorf("no connections found in %s", filePath)
+	}
+
+	connections, err = ui.SelectConnectionsToImport(connections)
+	if err != nil {
+		return fmt.Errorf("failed to select connections: %w", err)
+	}
+
+	return saveImportedConnections(connections)
+}
+
+var importBundleCmd = &cobra.Command{
+	Use:   "bundle <filepath>",
+	Short: "Import connections from a team bundle",
+	Long: `Import the connections of a bundle written by "connections export --bundle".
+
+Passwords are taken from each connection's password source: an environment
+variable for env:<VARIABLE> sources, the libpq password file for pgpass
+sources. Passwords that cannot be resolved are asked for once the connections
+to import have been picked; without a terminal to ask in, the import fails
+unless --allow-missing-passwords is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filePath := args[0]
+
+		bundleImporter := importer.NewBundleImporter()
+		connections, err := bundleImporter.Import(filePath, importer.ImportOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", filePath, err)
+		}
+
+		printSkippedEntries(bundleImporter)
+
+		if len(connections) == 0 {
+			return fmt.Errorf("no connections found in %s", filePath)
+		}
+
+		connections, err = ui.SelectConnectionsToImport(connections)
+		if err != nil {
+			return fmt.Errorf("failed to select connections: %w", err)
+		}
+
+		missing := []int{}
+		missingNames := []string{}
+		for i, conn := range connections {
+			if importer.NeedsPassword(conn) {
+				missing = append(missing, i)
+				missingNames = append(missingNames, conn.Name)
 			}
-			fmt.Printf("Connection '%s' imported successfully.\n", conn.Name)
 		}
+		allowMissing, _ := cmd.Flags().GetBool("allow-missing-passwords")
+		if len(missing) == 0 || allowMissing {
+			return saveImportedConnections(connections)
+		}
+		if !ui.IsInteractive() {
+			return fmt.Errorf("connections %s have no password, and stdin is not a terminal to ask for them: set their password sources or pass --allow-missing-passwords", strings.Join(missingNames, ", "))
+		}
+		if err := ui.PromptPasswords(connections, missing); err != nil {
+			return fmt.Errorf("failed to get passwords: %w", err)
+		}
+
+		return saveImportedConnections(connections)
+	},
+}
+
+// fileImport describes a `connections import <kind>` subcommand backed by an
+// importer.Importer that reads a single project file.
+type fileImport struct {
+	kind         string
+	short        string
+	long         string
+	defaultPaths []string
+	newImporter  func() importer.Importer
+	// passwordless sources keep passwords out of the file and get an
+	// --allow-missing-passwords flag
+	passwordless bool
+}
+
+var fileImports = []fileImport{
+	{
+		kind:  "compose",
+		short: "Import connections from a docker compose file",
+		long: `Import one connection per database service defined in a docker compose file.
+
+Services are detected by image name (postgres, postgis, timescaledb, mysql,
+mariadb, percona). Credentials come from the POSTGRES_* or MYSQL_*/MARIADB_*
+environment of each service, including env_file entries and ${VAR}
+interpolation from the shell and the project's .env file. Each connection
+points at the port the service publishes on localhost.`,
+		defaultPaths: importer.ComposeFileNames,
+		newImporter:  func() importer.Importer { return importer.NewComposeImporter() },
+	},
+	{
+		kind:  "rails",
+		short: "Import connections from a Rails database.yml file",
+		long: `Import one connection per environment defined in a Rails config/database.yml,
+or one per database for environments with several databases.
+
+ERB tags reading ENV["..."] and ENV.fetch(...) are resolved against the shell
+environment and the application's .env file. Anchored templates such as
+"default: &default" are not imported on their own.`,
+		defaultPaths: []string{"config/database.yml"},
+		newImporter:  func() importer.Importer { return importer.NewRailsImporter() },
+	},
+	{
+		kind:  "prisma",
+		short: "Import connections from a Prisma schema",
+		long: `Import the datasource blocks of a Prisma schema.prisma file.
+
+env("...") URLs are resolved against the shell environment, the project's
+.env file and a .env file next to the schema.`,
+		defaultPaths: []string{"prisma/schema.prisma", "schema.prisma"},
+		newImporter:  func() importer.Importer { return importer.NewPrismaImporter() },
+	},
+	{
+		kind:  "django",
+		short: "Import connections from a Django settings.py file",
+		long: `Import one connection per alias of the DATABASES setting of a Django settings
+module.
+
+Settings must be literals or environment lookups: os.environ, os.getenv,
+python-decouple, django-environ and dj_database_url lookups are resolved
+against the shell environment and the project's .env file. Aliases using any
+other expression, such as BASE_DIR / "db.sqlite3", are reported as skipped.`,
+		defaultPaths: []string{"settings.py", "*/settings.py", "*/settings/base.py"},
+		newImporter:  func() importer.Importer { return importer.NewDjangoImporter() },
+	},
+	{
+		kind:  "pgpass",
+		short: "Import connections from a libpq password file",
+		long: `Import one PostgreSQL connection per line of a libpq password file.
+
+Lines with a * wildcard in the host, database or user field are skipped.`,
+		defaultPaths: []string{importer.PgpassPath()},
+		newImporter:  func() importer.Importer { return importer.NewPgpassImporter() },
+	},
+	{
+		kind:  "pgservice",
+		short: "Import connections from a libpq service file",
+		long: `Import one PostgreSQL connection per [service] of a libpq connection service
+file, named after the service.
+
+Passwords missing from the service file are looked up in the password file.`,
+		defaultPaths: []string{importer.PgServicePath()},
+		newImporter:  func() importer.Importer { return importer.NewPgServiceImporter() },
+	},
+	{
+		kind:  "mycnf",
+		short: "Import connections from a MySQL option file",
+		long: `Import one MySQL connection per [client] or [client-<suffix>] group of a MySQL
+option file. The plain [client] group is named "mycnf", suffixed groups are
+named after their suffix. Databases are read from the matching [mysql] or
+[mysql-<suffix>] group.`,
+		defaultPaths: []string{importer.MyCnfPath()},
+		newImporter:  func() importer.Importer { return importer.NewMyCnfImporter() },
+	},
+	{
+		kind:  "dbeaver",
+		short: "Import connections from DBeaver",
+		long: `Import the PostgreSQL, MySQL, MariaDB and SQLite connections of a DBeaver
+data-sources.json file. Folders are kept as tags.
+
+Credentials are decrypted from the credentials-config.json file next to it.
+Connections whose password is not saved there, for instance because a master
+password or the system's secure storage is used, are skipped unless
+--allow-missing-passwords is given. Connections going through an SSH tunnel are
+skipped.`,
+		defaultPaths: importer.DBeaverPaths(),
+		newImporter:  func() importer.Importer { return importer.NewDBeaverImporter() },
+		passwordless: true,
+	},
+	{
+		kind:  "datagrip",
+		short: "Import connections from a DataGrip or IntelliJ project",
+		long: `Import the PostgreSQL, MySQL, MariaDB and SQLite data sources of a JetBrains
+project's .idea/dataSources.xml file. Data source groups are kept as tags and
+user names are read from dataSources.local.xml.
+
+JetBrains IDEs keep passwords in the system keychain, so connections that need
+one are skipped unless --allow-missing-passwords is given. Data sources going
+through an SSH tunnel are skipped.`,
+		defaultPaths: []string{".idea/dataSources.xml"},
+		newImporter:  func() importer.Importer { return importer.NewDataGripImporter() },
+		passwordless: true,
+	},
+	{
+		kind:  "tableplus",
+		short: "Import connections from TablePlus",
+		long: `Import the PostgreSQL, MySQL, MariaDB and SQLite connections of TablePlus'
+Connections.plist file. Groups are kept as tags.
 
-		return nil
+TablePlus keeps passwords in the macOS keychain, so connections that need one
+are skipped unless --allow-missing-passwords is given. Binary property lists
+must be converted first with plutil -convert xml1.`,
+		defaultPaths: []string{importer.TablePlusPath()},
+		newImporter:  func() importer.Importer { return importer.NewTablePlusImporter() },
+		passwordless: true,
 	},
 }
 
+func newFileImportCmd(fi fileImport) *cobra.Command {
+	long := fi.long + "\n\nWhen no file is given, these paths are looked up:\n"
+	for _, path := range fi.defaultPaths {
+		long += "- " + path + "\n"
+	}
+
+	var allowMissingPasswords bool
+	cmd := &cobra.Command{
+		Use:   fi.kind + " [filepath]",
+		Short: fi.short,
+		Long:  long,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filePath := ""
+			if len(args) == 1 {
+				filePath = args[0]
+			} else {
+				found, err := findImportFile(fi.defaultPaths)
+				if err != nil {
+					return err
+				}
+				filePath = found
+			}
+
+			fileImporter := fi.newImporter()
+			connections, err := fileImporter.Import(filePath, importer.ImportOptions{
+				AllowMissingPasswords: allowMissingPasswords,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to import %s: %w", filePath, err)
+			}
+
+			printSkippedEntries(fileImporter)
+
+			if len(connections) == 0 {
+				return fmt.Errorf("no connections found in %s", filePath)
+			}
+
+			connections, err = ui.SelectConnectionsToImport(connections)
+			if err != nil {
+				return fmt.Errorf("failed to select connections: %w", err)
+			}
+
+			return saveImportedConnections(connections)
+		},
+	}
+
+	if fi.passwordless {
+		cmd.Flags().BoolVar(&allowMissingPasswords, "allow-missing-passwords", false, "import connections whose password is not available, leaving it empty")
+	}
+
+	return cmd
+}
+
+// findImportFile returns the first existing path among patterns, which may
+// contain glob wildcards. Several matches for one pattern are ambiguous.
+func findImportFile(patterns []string) (string, error) {
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return "", err
+		}
+		if len(matches) == 1 {
+			return matches[0], nil
+		}
+		if len(matches) > 1 {
+			return "", fmt.Errorf("several files match %s, pass one explicitly: %s", pattern, strings.Join(matches, ", "))
+		}
+	}
+
+	return "", fmt.Errorf("no file found (looked for %s)", strings.Join(patterns, ", "))
+}
+
+// printSkippedEntries reports what an importer could not convert, if it tracks that.
+func printSkippedEntries(imp importer.Importer) {
+	reporter, ok := imp.(importer.SkipReporter)
+	if !ok {
+		return
+	}
+
+	for _, entry := range reporter.Skipped() {
+		fmt.Fprintf(stdout, "Skipped '%s': %s\n", entry.Name, redactor.String(entry.Reason))
+	}
+}
+
+// saveImportedConnections stores imported connections and reports each one.
+// Connections whose name is taken are renamed, overwrite the existing one or
+// are skipped, as --on-conflict says or, in a terminal, as the user chooses.
+func saveImportedConnections(connections []connection.Connection) error {
+	if !isValidConflictAction(importOnConflict) {
+		return fmt.Errorf("invalid --on-conflict '%s', expected skip, overwrite or rename", importOnConflict)
+	}
+
+	manager := connection.NewManager(configManager)
+	existing, err := manager.List()
+	if err != nil {
+		return fmt.Errorf("failed to load connections: %w", err)
+	}
+
+	names := make(map[string]bool, len(existing))
+	for _, conn := range existing {
+		names[conn.Name] = true
+	}
+	taken := func(name string) bool { return names[name] }
+
+	for _, conn := range connections {
+		if taken(conn.Name) {
+			action, newName, err := resolveNameCollision(conn, taken)
+			if err != nil {
+				return err
+			}
+
+			switch action {
+			case ui.CollisionSkip:
+				fmt.Fprintf(stdout, "Connection '%s' skipped.\n", conn.Name)
+				continue
+			case ui.CollisionRename:
+				fmt.Fprintf(stdout, "Connection '%s' renamed to '%s'.\n", conn.Name, newName)
+				conn.Name = newName
+			}
+		}
+
+		if err := manager.Create(conn); err != nil {
+			return fmt.Errorf("failed to save connection '%s': %w", conn.Name, err)
+		}
+		names[conn.Name] = true
+		fmt.Fprintf(stdout, "Connection '%s' imported successfully.\n", conn.Name)
+	}
+
+	return nil
+}
+
+func isValidConflictAction(action string) bool {
+	switch action {
+	case "", ui.CollisionSkip, ui.CollisionOverwrite, ui.CollisionRename:
+		return true
+	default:
+		return false
+	}
+}
+
+// resolveNameCollision applies --on-conflict to a connection whose name is
+// taken, and asks what to do without it. Scripts cannot be asked, so they must
+// pass the flag.
+func resolveNameCollision(conn connection.Connection, taken func(name string) bool) (string, string, error) {
+	switch importOnConflict {
+	case ui.CollisionRename:
+		return ui.CollisionRename, ui.SuggestConnectionName(conn.Name, taken), nil
+	case ui.CollisionSkip, ui.CollisionOverwrite:
+		return importOnConflict, "", nil
+	}
+
+	if !ui.IsInteractive() {
+		return "", "", fmt.Errorf("connection '%s' already exists, pass --on-conflict=skip, overwrite or rename", conn.Name)
+	}
+	action, newName, err := ui.ResolveNameCollision(conn, taken)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve name collision: %w", err)
+	}
+	return action, newName, nil
+}
+
 func init() {
+	importCmd.PersistentFlags().StringVar(&importOnConflict, "on-conflict", "", "what to do with connections whose name is taken: skip, overwrite or rename (default: ask)")
+	importEnvCmd.Flags().Bool("scan", false, "detect every connection defined in the file")
 	importCmd.AddCommand(importEnvCmd)
+	importBundleCmd.Flags().Bool("allow-missing-passwords", false, "import connections whose password cannot be resolved, leaving it empty")
+	importCmd.AddCommand(importBundleCmd)
+	for _, fi := range fileImports {
+		importCmd.AddCommand(newFileImportCmd(fi))
+	}
 }
-
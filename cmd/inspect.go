@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"dbear/internal/catalog"
+	"dbear/internal/connection"
+	"dbear/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var inspectSchemas string
+var inspectFormat string
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect <connection>",
+	Short: "List the schemas, tables, columns, indexes and views of a database",
+	Long: `Read the structure of a database from its catalog: schemas, tables with their
+estimated row counts and sizes on disk, columns with their types, nullability
+and defaults, indexes, foreign keys and views.
+
+Row counts come from the statistics PostgreSQL and MySQL keep, which are
+estimates, and are counted on SQLite. With --format json, the structure is
+printed as a single JSON document for other tools to read.`,
+	Example: `  dbear inspect local
+  dbear inspect staging --schemas public,audit --format json > schema.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if inspectFormat != "text" && inspectFormat != "json" {
+			return fmt.Errorf("invalid format '%s', expected text or json", inspectFormat)
+		}
+
+		manager := connection.NewManager(configManager)
+		conn, err := loadConnection(manager, args[0], "inspect")
+		if err != nil {
+			return err
+		}
+
+		db, err := connection.OpenDB(*conn)
+		if err != nil {
+			return fmt.Errorf("failed to connect to '%s': %w", conn.Name, err)
+		}
+		defer db.Close()
+
+		c, err := catalog.Inspect(context.Background(), db, conn.Type, catalog.Options{
+			Schemas: parseCommaSeparatedSchemas(inspectSchemas),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to inspect '%s': %w", conn.Name, err)
+		}
+
+		if inspectFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(c)
+		}
+		ui.DisplayCatalog(stdout, c)
+		return nil
+	},
+}
+
+func init() {
+	inspectCmd.Flags().StringVarP(&inspectSchemas, "schemas", "s", "", "comma-separated list of schemas to inspect (default: all). PostgreSQL only.")
+	inspectCmd.Flags().StringVar(&inspectFormat, "format", "text", "output format: text or json")
+	rootCmd.AddCommand(inspectCmd)
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"dbear/internal/connection"
+	"dbear/internal/loader"
+
+	"github.com/spf13/cobra"
+)
+
+var loadTable string
+var loadFile string
+var loadFormat string
+var loadMapping map[string]string
+var loadCreate bool
+var loadTruncate bool
+var loadBatchSize int
+var loadTimeout time.Duration
+
+var loadCmd = &cobra.Command{
+	Use:   "load <connection>",
+	Short: "Bulk-load a CSV or JSON file into a table",
+	Long: `Load the records of a CSV or JSON file into a table in a single transaction, so
+that a failing row leaves the table as it was.
+
+CSV files start with a header row, and empty fields are loaded as NULL. JSON
+files hold an array of objects or one object per line, whose keys are the
+columns. File columns are loaded into the table columns of the same name, or
+of the name given with --map; mapping a column to nothing skips it.
+
+With --create, the table is created when it does not exist, with column types
+inferred from the first rows of the file.
+
+Rows are loaded with COPY on PostgreSQL, with LOAD DATA LOCAL INFILE on MySQL
+when the server enables local_infile and with batched INSERTs otherwise.
+
+As for transfers, only local databases can be loaded into, and read-only
+connections never are.`,
+	Example: `  dbear load local --table events --file events.csv
+  dbear load local -t events -f events.jsonl --create --truncate
+  dbear load local -t users -f export.csv --map "E-mail=email,Notes="`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if loadTable == "" {
+			return fmt.Errorf("no table given, pass it with --table")
+		}
+		if loadFile == "" {
+			return fmt.Errorf("no file given, pass it with --file")
+		}
+		format := loadFormat
+		if format == "" {
+			format = loader.DetectFormat(loadFile)
+		}
+		if format != loader.FormatCSV && format != loader.FormatJSON {
+			return fmt.Errorf("invalid format '%s', expected csv or json", format)
+		}
+
+		manager := connection.NewManager(configManager)
+		conn, err := loadConnection(manager, args[0], "load")
+		if err != nil {
+			return err
+		}
+
+		source, err := loader.OpenSource(loadFile, format)
+		if err != nil {
+			return err
+		}
+		defer source.Close()
+
+		ctx := context.Background()
+		if loadTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, loadTimeout)
+			defer cancel()
+		}
+
+		db, err := connection.OpenDBContext(ctx, *conn)
+		if err != nil {
+			return fmt.Errorf("failed to connect to '%s': %w", conn.Name, err)
+		}
+		defer db.Close()
+
+		count, err := loader.Load(ctx, db, *conn, source, loader.Options{
+			Table:     loadTable,
+			Mapping:   loadMapping,
+			Create:    loadCreate,
+			Truncate:  loadTruncate,
+			BatchSize: loadBatchSize,
+		})
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("load timed out after %s: %w", loadTimeout, err)
+			}
+			return fmt.Errorf("load failed: %w", err)
+		}
+
+		fmt.Fprintf(stderr, "Loaded %d rows into %s\n", count, loadTable)
+		return nil
+	},
+}
+
+func init() {
+	loadCmd.Flags().StringVarP(&loadTable, "table", "t", "", "table to load into, optionally qualified by its schema")
+	loadCmd.Flags().StringVarP(&loadFile, "file", "f", "", "CSV or JSON file to load")
+	loadCmd.Flags().StringVar(&loadFormat, "format", "", "file format: csv or json (default: from the file extension, else csv)")
+	loadCmd.Flags().StringToStringVar(&loadMapping, "map", nil, "file columns to load into differently named table columns, e.g. Email=email,Notes= to skip Notes")
+	loadCmd.Flags().BoolVar(&loadCreate, "create", false, "create the table from the inferred column types if it does not exist")
+	loadCmd.Flags().BoolVar(&loadTruncate, "truncate", false, "delete the rows of the table before loading")
+	loadCmd.Flags().IntVar(&loadBatchSize, "batch-size", loader.DefaultBatchSize, "rows per INSERT when COPY and LOAD DATA are not used")
+	loadCmd.Flags().DurationVar(&loadTimeout, "timeout", 0, "cancel the load after this long, e.g. 10m (default: none)")
+	rootCmd.AddCommand(loadCmd)
+}
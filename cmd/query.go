@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"dbear/internal/connection"
+	"dbear/internal/query"
+
+	"github.com/spf13/cobra"
+)
+
+var queryFile string
+var queryFormat string
+var queryLimit int
+var queryTimeout time.Duration
+
+var queryCmd = &cobra.Command{
+	Use:   "query <connection> [sql]",
+	Short: "Run SQL and print the results",
+	Long: `Run SQL statements on a connection through the drivers built into dbear, without
+any client installed, and print the rows they return.
+
+The SQL is given as an argument or read from a file with --file, or from stdin
+with --file -. Statements separated by semicolons run one after the other on
+the same connection, and the first failing one stops the run.
+
+Results are printed as an aligned table, CSV, JSON lines or a Markdown table.`,
+	Example: `  dbear query local "SELECT id, email FROM users" --limit 10
+  dbear query staging -f report.sql --format csv > report.csv`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !query.IsValidFormat(queryFormat) {
+			return fmt.Errorf("invalid format '%s', expected one of %s", queryFormat, strings.Join(query.Formats, ", "))
+		}
+
+		script, err := readQueryScript(args[1:])
+		if err != nil {
+			return err
+		}
+
+		manager := connection.NewManager(configManager)
+		conn, err := loadConnection(manager, args[0], "query")
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		if queryTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, queryTimeout)
+			defer cancel()
+		}
+
+		db, err := connection.OpenDBContext(ctx, *conn)
+		if err != nil {
+			return fmt.Errorf("failed to connect to '%s': %w", conn.Name, err)
+		}
+		defer db.Close()
+
+		results, runErr := query.Run(ctx, db, conn.Type, script, queryLimit)
+		for _, result := range results {
+			if err := printQueryResult(result); err != nil {
+				return err
+			}
+		}
+		if runErr != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("query timed out after %s: %w", queryTimeout, runErr)
+			}
+			return fmt.Errorf("query failed: %w", runErr)
+		}
+
+		return nil
+	},
+}
+
+// readQueryScript returns the SQL given as argument or read from --file
+func readQueryScript(args []string) (string, error) {
+	switch {
+	case len(args) > 0 && queryFile != "":
+		return "", fmt.Errorf("give the SQL either as an argument or with --file, not both")
+	case len(args) > 0:
+		return args[0], nil
+	case queryFile == "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read SQL from stdin: %w", err)
+		}
+		return string(data), nil
+	case queryFile != "":
+		data, err := os.ReadFile(queryFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read SQL file: %w", err)
+		}
+		return string(data), nil
+	default:
+		return "", fmt.Errorf("no SQL given, pass it as an argument or with --file")
+	}
+}
+
+// printQueryResult prints the rows of result to stdout. Notes about the run go
+// to stderr when the output is meant for other programs.
+func printQueryResult(result query.Result) error {
+	notes := stderr
+	if queryFormat == query.FormatTable {
+		notes = stdout
+	}
+
+	if !result.HasRows() {
+		if result.RowsAffected == 1 {
+			fmt.Fprintln(notes, "1 row affected")
+		} else {
+			fmt.Fprintf(notes, "%d rows affected\n", result.RowsAffected)
+		}
+		return nil
+	}
+
+	if err := query.Write(os.Stdout, queryFormat, result); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	if result.Truncated {
+		fmt.Fprintf(stderr, "Stopped after %d rows, raise --limit to see more\n", queryLimit)
+	}
+	return nil
+}
+
+func init() {
+	queryCmd.Flags().StringVarP(&queryFile, "file", "f", "", "read the SQL from a file, - for stdin")
+	queryCmd.Flags().StringVar(&queryFormat, "format", query.FormatTable, "output format: table, csv, jsonl or markdown")
+	queryCmd.Flags().IntVarP(&queryLimit, "limit", "l", 0, "maximum number of rows to print per statement (default: all)")
+	queryCmd.Flags().DurationVar(&queryTimeout, "timeout", 0, "cancel the run after this long, e.g. 30s (default: none)")
+	rootCmd.AddCommand(queryCmd)
+}
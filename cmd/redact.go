@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"dbear/internal/config"
+	"dbear/internal/redact"
+)
+
+// redactor masks the secrets of every connection dbear has loaded or saved
+// in the errors and output of the commands
+var redactor = redact.New()
+
+// stdout and stderr carry the messages of the commands, masked by redactor.
+// Data meant for other programs, such as query results, exports and diagrams,
+// goes to os.Stdout unchanged.
+var (
+	stdout io.Writer = redact.NewWriter(redactor, os.Stdout)
+	stderr io.Writer = redact.NewWriter(redactor, os.Stderr)
+)
+
+// redactingManager teaches redactor the secrets of the connections going
+// through a config manager
+type redactingManager struct {
+	config.Manager
+}
+
+func (m *redactingManager) Load() (*config.Config, error) {
+	cfg, err := m.Manager.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	addSecrets(cfg.Connections)
+	return cfg, nil
+}
+
+func (m *redactingManager) Save(cfg *config.Config) error {
+	addSecrets(cfg.Connections)
+	return m.Manager.Save(cfg)
+}
+
+// addSecrets makes redactor mask the passwords of connections, including
+// those passed as driver parameters
+func addSecrets(connections []config.Connection) {
+	for _, conn := range connections {
+		redactor.Add(conn.Password)
+		for key, value := range conn.Params {
+			if config.IsSecretParam(key) {
+				redactor.Add(value)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dbear/internal/config"
+	"dbear/internal/redact"
+	"dbear/internal/testutil"
+	"dbear/internal/transfer"
+)
+
+// fakeCommands stand in for the clients and docker, failing after printing
+// the password they were handed to stderr
+var fakeCommands = map[string]string{
+	"psql": `echo "psql: error: connection failed with PGPASSWORD=$PGPASSWORD" >&2
+exit 2`,
+	"mysql": `cat "${1#--defaults-extra-file=}" >&2
+echo "ERROR 1045 (28000): Access denied" >&2
+exit 1`,
+	"leak": `echo "leak: cannot open the database with key $1" >&2
+exit 1`,
+	"docker": `while [ $# -gt 0 ]; do
+  case "$1" in
+    --env-file) cat "$2" >&2 ;;
+    -v) case "$2" in *client.cnf*) cat "${2%%:*}" >&2 ;; esac ;;
+  esac
+  shift
+done
+echo "FATAL: password authentication failed" >&2
+exit 1`,
+}
+
+var testConnections = []config.Connection{
+	{Name: "pg", Type: config.TypePostgreSQL, Host: "127.0.0.1", Port: 1, Username: "app", Password: testutil.Sentinel, Database: "app", SSLMode: "disable"},
+	{Name: "my", Type: config.TypeMySQL, Host: "127.0.0.1", Port: 1, Username: "app", Password: testutil.Sentinel, Database: "app"},
+	{Name: "lite", Type: config.TypeSQLite, Password: testutil.Sentinel},
+}
+
+// setupRedactionTest writes a config holding testConnections, puts the fake
+// commands first in PATH and captures the output of the commands
+func setupRedactionTest(t *testing.T) (configFile string, output *bytes.Buffer) {
+	t.Helper()
+	dir := t.TempDir()
+
+	bin := filepath.Join(dir, "bin")
+	if err := os.Mkdir(bin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, script := range fakeCommands {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	connections := append([]config.Connection{}, testConnections...)
+	connections[2].Database = filepath.Join(dir, "test.db")
+	if err := os.WriteFile(connections[2].Database, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(config.Config{Connections: connections})
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile = filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configFile, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	output = &bytes.Buffer{}
+	savedStdout, savedStderr := stdout, stderr
+	stdout = redact.NewWriter(redactor, output)
+	stderr = redact.NewWriter(redactor, output)
+	t.Cleanup(func() {
+		stdout, stderr = savedStdout, savedStderr
+	})
+	return configFile, output
+}
+
+func TestFailingCommandsDoNotPrintPasswords(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		// masked tells whether the command prints the password, which must
+		// then show up masked
+		masked bool
+	}{
+		{"psql", []string{"connect", "pg", "--client", "psql"}, true},
+		{"mysql", []string{"connect", "my", "--client", "mysql"}, true},
+		{"sqlite template", []string{"connect", "lite", "--client", "leak {password}"}, true},
+		{"postgresql driver", []string{"query", "pg", "SELECT 1"}, false},
+		{"mysql driver", []string{"query", "my", "SELECT 1"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configFile, output := setupRedactionTest(t)
+			rootCmd.SetArgs(append(test.args, "--config", configFile))
+			err := Execute()
+			if err == nil {
+				t.Fatal("expected the command to fail")
+			}
+			if strings.Contains(err.Error(), testutil.Sentinel) {
+				t.Fatalf("password in the error: %s", err)
+			}
+			if strings.Contains(output.String(), testutil.Sentinel) {
+				t.Fatalf("password in the output: %s", output)
+			}
+			if test.masked && !strings.Contains(output.String(), redact.Mask) {
+				t.Fatalf("no masked password in the output: %s", output)
+			}
+		})
+	}
+}
+
+func TestFailingDockerDumpsDoNotPrintPasswords(t *testing.T) {
+	for _, conn := range testConnections[:2] {
+		t.Run(conn.Type, func(t *testing.T) {
+			setupRedactionTest(t)
+			addSecrets([]config.Connection{conn})
+
+			_, err := transfer.DumpDatabase(conn, "image:latest", transfer.DumpOptions{})
+			if err == nil {
+				t.Fatal("expected the dump to fail")
+			}
+			if !strings.Contains(err.Error(), testutil.Sentinel) {
+				t.Fatalf("the fake docker did not print the password: %s", err)
+			}
+			if message := redactor.Error(err).Error(); strings.Contains(message, testutil.Sentinel) {
+				t.Fatalf("password in the error: %s", message)
+			}
+		})
+	}
+}
@@ -15,7 +15,15 @@ var configManager config.Manager
 var rootCmd = &cobra.Command{
 	Use:   "dbear",
 	Short: "Database connection manager",
-	Long:  "A CLI tool for managing database connections",
+	Long: `A CLI tool for managing database connections.
+
+Connections are stored in the global config file. Projects can add their own
+in a .dbear.json or .dbear.yaml file, looked up from the current directory
+upward and merged over the global config; a project connection replaces a
+global one of the same name. A project config can also name the default source
+and destination of "transfer":
+
+  {"transfer": {"source": "staging", "destination": "local"}}`,
 }
 
 func init() {
@@ -31,11 +39,25 @@ func init() {
 	cobra.OnInitialize(initConfig)
 }
 
+// initConfig layers the project configs found from the current directory
+// upward over the global config file
 func initConfig() {
-	configManager = config.NewJSONManager(configPath)
+	projectConfigs, err := config.FindProjectConfigs(".")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error looking up project config: %v\n", err)
+		os.Exit(1)
+	}
+
+	configManager = &redactingManager{Manager: config.NewLayeredManager(configPath, projectConfigs)}
 }
 
+// Execute runs the command line. Errors, messages and cobra's own output are
+// masked by redactor, so passwords echoed by clients or drivers stay out of the
+// terminal.
 func Execute() error {
-	return rootCmd.Execute()
+	rootCmd.SetOut(stdout)
+	rootCmd.SetErr(stderr)
+
+	return redactor.Error(rootCmd.Execute())
 }
 
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+	"dbear/internal/transfer"
+	"dbear/internal/verify"
+
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run [profile]",
+	Short: "Run a saved profile",
+	Long: `Run a saved transfer or dump profile without asking anything. Without a
+profile name, the saved profiles are listed.
+
+Profiles are declared in the "profiles" section of the global or project config:
+
+  "profiles": {
+    "refresh-local": {
+      "source": "staging",
+      "destination": "local",
+      "schemas": ["public"],
+      "exclude_tables": ["audit_*"]
+    },
+    "backup-staging": {"command": "dump", "source": "staging", "output": "staging.dump"}
+  }
+
+Transfer profiles without a source or destination fall back on the defaults of
+the "transfer" section, and the row counts of the copied tables are checked
+afterwards as with dbear transfer.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager := connection.NewManager(configManager)
+
+		if len(args) == 0 {
+			return listProfiles(manager)
+		}
+
+		return runProfile(manager, args[0], "")
+	},
+}
+
+// listProfiles prints the saved profiles
+func listProfiles(manager *connection.Manager) error {
+	profiles, err := manager.Profiles()
+	if err != nil {
+		return fmt.Errorf("failed to load profiles: %w", err)
+	}
+
+	if len(profiles) == 0 {
+		fmt.Fprintln(stdout, "No profiles found.")
+		return nil
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		profile := profiles[name]
+		if profileCommand(profile) == config.ProfileDump {
+			fmt.Fprintf(stdout, "%s: dump %s\n", name, profile.Source)
+		} else {
+			fmt.Fprintf(stdout, "%s: transfer %s to %s\n", name, profile.Source, profile.Destination)
+		}
+	}
+	return nil
+}
+
+// runProfile runs the named profile. A non-empty command restricts which kind
+// of profile is accepted.
+func runProfile(manager *connection.Manager, name string, command string) error {
+	profiles, err := manager.Profiles()
+	if err != nil {
+		return fmt.Errorf("failed to load profiles: %w", err)
+	}
+
+	profile, found := profiles[name]
+	if !found {
+		return fmt.Errorf("profile '%s' not found", name)
+	}
+	if command != "" && profileCommand(profile) != command {
+		return fmt.Errorf("profile '%s' is a %s profile, not a %s profile", name, profileCommand(profile), command)
+	}
+
+	options := transfer.DumpOptions{
+		Schemas:       profile.Schemas,
+		Tables:        profile.Tables,
+		ExcludeTables: profile.ExcludeTables,
+	}
+
+	switch profileCommand(profile) {
+	case config.ProfileTransfer:
+		defaults, err := manager.TransferDefaults()
+		if err != nil {
+			return fmt.Errorf("failed to load transfer defaults: %w", err)
+		}
+
+		sourceConn, err := loadConnection(manager, firstNonEmpty(profile.Source, defaults.Source), "source")
+		if err != nil {
+			return err
+		}
+		destConn, err := loadConnection(manager, firstNonEmpty(profile.Destination, defaults.Destination), "destination")
+		if err != nil {
+			return err
+		}
+		if sourceConn.Name == destConn.Name {
+			return fmt.Errorf("profile '%s' transfers '%s' onto itself", name, sourceConn.Name)
+		}
+
+		return runTransfer(*sourceConn, *destConn, options, false, &verify.Options{})
+	case config.ProfileDump:
+		sourceConn, err := loadConnection(manager, profile.Source, "source")
+		if err != nil {
+			return err
+		}
+
+		return runDump(*sourceConn, profile.Output, options)
+	default:
+		return fmt.Errorf("profile '%s' has an unknown command '%s' (expected %s)", name, profile.Command,
+			strings.Join([]string{config.ProfileTransfer, config.ProfileDump}, " or "))
+	}
+}
+
+// profileCommand returns the command of a profile, transfer by default
+func profileCommand(profile config.Profile) string {
+	if profile.Command == "" {
+		return config.ProfileTransfer
+	}
+	return profile.Command
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+}
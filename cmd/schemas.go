@@ -5,6 +5,12 @@ import "strings"
 // parseCommaSeparatedSchemas returns nil when flagValue is empty (meaning "all schemas"),
 // otherwise returns trimmed non-empty schema names.
 func parseCommaSeparatedSchemas(flagValue string) []string {
+	return parseCommaSeparatedList(flagValue)
+}
+
+// parseCommaSeparatedList returns nil when flagValue is empty, otherwise returns
+// trimmed non-empty items.
+func parseCommaSeparatedList(flagValue string) []string {
 	trimmed := strings.TrimSpace(flagValue)
 	if trimmed == "" {
 		return nil
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"dbear/internal/catalog"
+	"dbear/internal/config"
+	"dbear/internal/connection"
+	"dbear/internal/transfer"
+	"dbear/internal/ui"
+
+	"github.com/lib/pq"
+)
+
+// loadConnection loads a connection a command needs in the given role
+func loadConnection(manager *connection.Manager, name string, role string) (*connection.Connection, error) {
+	if name == "" {
+		return nil, fmt.Errorf("no %s connection given", role)
+	}
+
+	conn, err := manager.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s connection: %w", role, err)
+	}
+	if conn == nil {
+		return nil, fmt.Errorf("%s connection '%s' not found", role, name)
+	}
+	return conn, nil
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// pickConnection lets the user pick a connection for the given role. Without a
+// terminal it fails instead of waiting for input that never comes.
+func pickConnection(connections []connection.Connection, role string) (string, error) {
+	if !ui.IsInteractive() {
+		return "", fmt.Errorf("no %s connection given, and stdin is not a terminal to pick one: pass it as an argument", role)
+	}
+
+	name, err := ui.SelectConnectionWithTitle(connections, fmt.Sprintf("Select %s connection", role))
+	if err != nil {
+		return "", fmt.Errorf("failed to select %s connection: %w", role, err)
+	}
+	return name, nil
+}
+
+// pickTables lets the user narrow down the schemas and tables of conn that
+// options copy. Options given with --schemas, --tables or --exclude-tables are
+// taken as the whole selection, and without a terminal everything is copied,
+// as before.
+func pickTables(conn connection.Connection, options *transfer.DumpOptions) error {
+	if !ui.IsInteractive() || len(options.Schemas) > 0 || len(options.Tables) > 0 || len(options.ExcludeTables) > 0 {
+		return nil
+	}
+
+	db, err := connection.OpenDB(conn)
+	if err != nil {
+		return fmt.Errorf("failed to connect to '%s' to list its tables: %w", conn.Name, err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	if conn.Type == config.TypePostgreSQL {
+		schemas, err := catalog.ListSchemas(ctx, db, conn.Type)
+		if err != nil {
+			return fmt.Errorf("failed to list schemas: %w", err)
+		}
+		if len(schemas) > 1 {
+			chosen, err := ui.SelectNames("Schemas to include", schemas)
+			if err != nil {
+				return fmt.Errorf("failed to select schemas: %w", err)
+			}
+			if len(chosen) < len(schemas) {
+				options.Schemas = chosen
+			}
+		}
+	}
+
+	tables, err := catalog.ListTables(ctx, db, conn.Type, catalog.Options{Schemas: options.Schemas})
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	if len(tables) < 2 {
+		return nil
+	}
+	chosen, err := ui.SelectNames("Tables to include", tables)
+	if err != nil {
+		return fmt.Errorf("failed to select tables: %w", err)
+	}
+	if len(chosen) == len(tables) {
+		return nil
+	}
+
+	for _, table := range chosen {
+		if conn.Type == config.TypePostgreSQL {
+			// pg_dump folds unquoted patterns to lower case
+			schema, name, _ := strings.Cut(table, ".")
+			table = pq.QuoteIdentifier(schema) + "." + pq.QuoteIdentifier(name)
+		}
+		options.Tables = append(options.Tables, table)
+	}
+	return nil
+}
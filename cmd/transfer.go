@@ -3,23 +3,54 @@ package cmd
 import (
 	"fmt"
 
+	"dbear/internal/config"
 	"dbear/internal/connection"
 	"dbear/internal/transfer"
 	"dbear/internal/ui"
+	"dbear/internal/verify"
 
 	"github.com/spf13/cobra"
 )
 
 var transferSchemas string
+var transferTables string
+var transferExcludeTables string
+var transferProfile string
+var transferYes bool
+var transferNoVerify bool
+var transferChecksums bool
 
 var transferCmd = &cobra.Command{
-	Use:   "transfer",
+	Use:   "transfer [source] [destination]",
 	Short: "Transfer data between databases",
-	Long:  "Transfer data from a source database to a destination database using Docker containers or native tools",
+	Long: `Transfer data from a source database to a destination database using Docker containers or native tools.
+
+The source and destination are taken from the arguments, then from the
+defaults in the "transfer" section of the config, and are asked for otherwise.
+In a terminal, the schemas and tables to copy are picked from a list unless
+--schemas, --tables or --exclude-tables is given, and the transfer is confirmed
+before it starts; --yes skips both. With --profile, a saved transfer profile
+runs without any question.
+
+Once the data is copied, the rows of every copied table are counted on both
+sides, and the transfer fails if any table differs, unless --no-verify is
+given. With --checksums, the rows themselves are compared, as with dbear
+verify.
+
+Without a terminal, as in cron jobs or CI, nothing is asked: missing
+connections and a missing --yes are errors.`,
+	Args: cobra.MaximumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("Loading connections...")
+		fmt.Fprintln(stdout, "Loading connections...")
 		manager := connection.NewManager(configManager)
 
+		if transferProfile != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("connections cannot be given together with --profile")
+			}
+			return runProfile(manager, transferProfile, config.ProfileTransfer)
+		}
+
 		connections, err := manager.List()
 		if err != nil {
 			return fmt.Errorf("failed to load connections: %w", err)
@@ -29,18 +60,28 @@ var transferCmd = &cobra.Command{
 			return fmt.Errorf("at least 2 connections are required for transfer")
 		}
 
-		sourceName, err := ui.SelectConnectionWithTitle(connections, "Select source connection")
+		defaults, err := manager.TransferDefaults()
 		if err != nil {
-			return fmt.Errorf("failed to select source connection: %w", err)
+			return fmt.Errorf("failed to load transfer defaults: %w", err)
+		}
+
+		sourceName := ""
+		switch {
+		case len(args) > 0:
+			sourceName = args[0]
+		case defaults.Source != "":
+			sourceName = defaults.Source
+			fmt.Fprintf(stdout, "Using default source connection '%s'.\n", sourceName)
+		default:
+			sourceName, err = pickConnection(connections, "source")
+			if err != nil {
+				return err
+			}
 		}
 
-		sourceConn, err := manager.Get(sourceName)
+		sourceConn, err := loadConnection(manager, sourceName, "source")
 		if err != nil {
-			return fmt.Errorf("failed to load source connection: %w", err)
-		}
-
-		if sourceConn == nil {
-			return fmt.Errorf("source connection '%s' not found", sourceName)
+			return err
 		}
 
 		filteredConnections := []connection.Connection{}
@@ -50,39 +91,74 @@ var transferCmd = &cobra.Command{
 			}
 		}
 
-		destName, err := ui.SelectConnectionWithTitle(filteredConnections, "Select destination connection")
-		if err != nil {
-			return fmt.Errorf("failed to select destination connection: %w", err)
+		destName := ""
+		switch {
+		case len(args) > 1:
+			destName = args[1]
+		case defaults.Destination != "":
+			destName = defaults.Destination
+			fmt.Fprintf(stdout, "Using default destination connection '%s'.\n", destName)
+		default:
+			destName, err = pickConnection(filteredConnections, "destination")
+			if err != nil {
+				return err
+			}
+		}
+		if destName == sourceName {
+			return fmt.Errorf("the destination '%s' is also the source", destName)
 		}
 
-		destConn, err := manager.Get(destName)
+		destConn, err := loadConnection(manager, destName, "destination")
 		if err != nil {
-			return fmt.Errorf("failed to load destination connection: %w", err)
+			return err
 		}
 
-		if destConn == nil {
-			return fmt.Errorf("destination connection '%s' not found", destName)
+		options := transfer.DumpOptions{
+			Schemas:       parseCommaSeparatedSchemas(transferSchemas),
+			Tables:        parseCommaSeparatedList(transferTables),
+			ExcludeTables: parseCommaSeparatedList(transferExcludeTables),
 		}
-
-		if err := transfer.ValidateDestination(*destConn); err != nil {
-			return err
+		if !transferYes {
+			if err := pickTables(*sourceConn, &options); err != nil {
+				return err
+			}
 		}
-
-		if sourceConn.Type != destConn.Type {
-			return fmt.Errorf("source and destination databases must be of the same type (source: %s, destination: %s)", sourceConn.Type, destConn.Type)
+		var check *verify.Options
+		if !transferNoVerify {
+			check = &verify.Options{Checksums: transferChecksums}
 		}
+		return runTransfer(*sourceConn, *destConn, options, !transferYes, check)
+	},
+}
 
-		sourceVersion, err := transfer.DetectVersion(*sourceConn)
-		if err != nil {
-			return fmt.Errorf("failed to detect source database version: %w", err)
-		}
+// runTransfer copies source into dest, asking for confirmation first when
+// confirm is set, then checks that the copied tables match unless check is
+// nil
+func runTransfer(sourceConn, destConn connection.Connection, options transfer.DumpOptions, confirm bool, check *verify.Options) error {
+	if err := transfer.ValidateDestination(destConn); err != nil {
+		return err
+	}
 
-		destVersion, err := transfer.DetectVersion(*destConn)
-		if err != nil {
-			return fmt.Errorf("failed to detect destination database version: %w", err)
+	if sourceConn.Type != destConn.Type {
+		return fmt.Errorf("source and destination databases must be of the same type (source: %s, destination: %s)", sourceConn.Type, destConn.Type)
+	}
+
+	sourceVersion, err := transfer.DetectVersion(sourceConn)
+	if err != nil {
+		return fmt.Errorf("failed to detect source database version: %w", err)
+	}
+
+	destVersion, err := transfer.DetectVersion(destConn)
+	if err != nil {
+		return fmt.Errorf("failed to detect destination database version: %w", err)
+	}
+
+	if confirm {
+		if !ui.IsInteractive() {
+			return fmt.Errorf("stdin is not a terminal to confirm the transfer: pass --yes to run it")
 		}
 
-		confirmed, err := ui.ConfirmTransfer(*sourceConn, *destConn, sourceVersion, destVersion)
+		confirmed, err := ui.ConfirmTransfer(sourceConn, destConn, sourceVersion, destVersion)
 		if err != nil {
 			return fmt.Errorf("failed to get confirmation: %w", err)
 		}
@@ -90,22 +166,48 @@ var transferCmd = &cobra.Command{
 		if !confirmed {
 			return fmt.Errorf("transfer cancelled by user")
 		}
+	} else {
+		fmt.Fprintf(stdout, "Transferring '%s' (%s %s) to '%s' (%s %s).\n",
+			sourceConn.Name, sourceConn.Type, sourceVersion, destConn.Name, destConn.Type, destVersion)
+	}
 
-		schemas := parseCommaSeparatedSchemas(transferSchemas)
-		_, err = ui.RunWithSpinner("Transferring data...", func() (interface{}, error) {
-			return nil, transfer.Transfer(*sourceConn, *destConn, schemas)
-		})
-		if err != nil {
-			return fmt.Errorf("transfer failed: %w", err)
-		}
+	_, err = ui.RunWithSpinner("Transferring data...", func() (interface{}, error) {
+		return nil, transfer.Transfer(sourceConn, destConn, options)
+	})
+	if err != nil {
+		return fmt.Errorf("transfer failed: %w", err)
+	}
 
-		fmt.Printf("Transfer completed successfully from '%s' to '%s'\n", sourceName, destName)
+	if check == nil {
+		fmt.Fprintf(stdout, "Transfer completed successfully from '%s' to '%s'\n", sourceConn.Name, destConn.Name)
 		return nil
-	},
+	}
+
+	// Restores may succeed while leaving tables empty, such as when triggers
+	// could not be disabled
+	output, err := ui.RunWithSpinner("Verifying the copied tables...", func() (interface{}, error) {
+		return checkTables(sourceConn, destConn, options, *check)
+	})
+	if err != nil {
+		return fmt.Errorf("transfer finished but could not be verified: %w", err)
+	}
+	results := output.([]verify.Result)
+	if differing := countDiffering(results); differing > 0 {
+		ui.DisplayVerification(stdout, results)
+		return fmt.Errorf("transfer finished but %d of %d tables differ between '%s' and '%s'", differing, len(results), sourceConn.Name, destConn.Name)
+	}
+
+	fmt.Fprintf(stdout, "Transfer completed successfully from '%s' to '%s', %d tables verified\n", sourceConn.Name, destConn.Name, len(results))
+	return nil
 }
 
 func init() {
 	transferCmd.Flags().StringVarP(&transferSchemas, "schemas", "s", "", "comma-separated list of schemas to include (default: all). PostgreSQL only.")
+	transferCmd.Flags().StringVarP(&transferTables, "tables", "t", "", "comma-separated list of tables to include (default: all), * and ? wildcards allowed")
+	transferCmd.Flags().StringVar(&transferExcludeTables, "exclude-tables", "", "comma-separated list of tables to leave out, * and ? wildcards allowed")
+	transferCmd.Flags().StringVar(&transferProfile, "profile", "", "run a saved transfer profile without asking anything")
+	transferCmd.Flags().BoolVar(&transferNoVerify, "no-verify", false, "skip counting the rows of the copied tables on both sides afterwards")
+	transferCmd.Flags().BoolVar(&transferChecksums, "checksums", false, "also compare the rows of the copied tables chunk by chunk afterwards")
+	transferCmd.Flags().BoolVarP(&transferYes, "yes", "y", false, "start the transfer without asking for confirmation")
 	rootCmd.AddCommand(transferCmd)
 }
-
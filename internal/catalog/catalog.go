@@ -0,0 +1,288 @@
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"dbear/internal/config"
+)
+
+// Catalog describes the schemas of a database as read from its system
+// catalog. MySQL databases have a single schema, named after the database,
+// and SQLite files a single one named main.
+type Catalog struct {
+	Type      string     `json:"type"`
+	Schemas   []string   `json:"schemas"`
+	Tables    []Table    `json:"tables"`
+	Views     []View     `json:"views"`
+	Functions []Function `json:"functions"`
+}
+
+// Table is a table and its structure
+type Table struct {
+	Schema  string `json:"schema"`
+	Name    string `json:"name"`
+	Comment string `json:"comment,omitempty"`
+	// RowEstimate is the row count kept in the catalog statistics, exact for
+	// SQLite, or -1 when it is unknown
+	RowEstimate int64 `json:"row_estimate"`
+	// Size is the size on disk of the table and its indexes in bytes, or -1
+	// when it is unknown
+	Size        int64        `json:"size"`
+	Columns     []Column     `json:"columns"`
+	PrimaryKey  []string     `json:"primary_key,omitempty"`
+	Indexes     []Index      `json:"indexes,omitempty"`
+	ForeignKeys []ForeignKey `json:"foreign_keys,omitempty"`
+	Constraints []Constraint `json:"constraints,omitempty"`
+	// Definition is the CREATE TABLE statement SQLite keeps
+	Definition string `json:"definition,omitempty"`
+}
+
+// Column is a column of a table or view
+type Column struct {
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Nullable bool    `json:"nullable"`
+	Default  *string `json:"default,omitempty"`
+	// Identity is set for AUTO_INCREMENT and identity columns, whose values
+	// are generated by the database
+	Identity bool   `json:"identity,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// Index is an index of a table, including the one backing its primary key
+type Index struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+	Primary bool     `json:"primary,omitempty"`
+	// Definition is the statement creating the index, when the database
+	// keeps it
+	Definition string `json:"definition,omitempty"`
+}
+
+// ForeignKey is a foreign key of a table
+type ForeignKey struct {
+	Name              string   `json:"name,omitempty"`
+	Columns           []string `json:"columns"`
+	ReferencedSchema  string   `json:"referenced_schema"`
+	ReferencedTable   string   `json:"referenced_table"`
+	ReferencedColumns []string `json:"referenced_columns"`
+	OnUpdate          string   `json:"on_update,omitempty"`
+	OnDelete          string   `json:"on_delete,omitempty"`
+}
+
+// Constraint is a unique or check constraint of a table. Unique constraints of
+// MySQL are read as unique indexes.
+type Constraint struct {
+	Name string `json:"name"`
+	// Type is UNIQUE or CHECK
+	Type string `json:"type"`
+	// Definition is the constraint as written after ADD CONSTRAINT name
+	Definition string `json:"definition"`
+}
+
+// View is a view, or a materialized view on PostgreSQL
+type View struct {
+	Schema       string   `json:"schema"`
+	Name         string   `json:"name"`
+	Materialized bool     `json:"materialized,omitempty"`
+	Definition   string   `json:"definition"`
+	Columns      []Column `json:"columns"`
+}
+
+// Function is a function or procedure, other than those of extensions
+type Function struct {
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	// Arguments tells apart overloads on PostgreSQL
+	Arguments string `json:"arguments"`
+	// Kind is function or procedure
+	Kind string `json:"kind"`
+	// Definition is the statement creating the function, when the user may
+	// read it
+	Definition string `json:"definition,omitempty"`
+}
+
+// Options narrows what Inspect reads
+type Options struct {
+	// Schemas limits PostgreSQL catalogs to these schemas, all of them when
+	// empty
+	Schemas []string
+}
+
+// Inspect reads the schemas, tables, columns, indexes, constraints, views and
+// functions of the database behind db
+func Inspect(ctx context.Context, db *sql.DB, dbType string, options Options) (*Catalog, error) {
+	c := &Catalog{Type: dbType, Schemas: []string{}, Tables: []Table{}, Views: []View{}, Functions: []Function{}}
+
+	var err error
+	switch dbType {
+	case config.TypePostgreSQL:
+		err = inspectPostgreSQL(ctx, db, c, options)
+	case config.TypeMySQL:
+		err = inspectMySQL(ctx, db, c)
+	case config.TypeSQLite:
+		err = inspectSQLite(ctx, db, c)
+	default:
+		err = fmt.Errorf("unsupported database type for inspection: %s", dbType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.sort()
+	return c, nil
+}
+
+// ListSchemas returns the names of the schemas of the database behind db
+func ListSchemas(ctx context.Context, db *sql.DB, dbType string) ([]string, error) {
+	switch dbType {
+	case config.TypePostgreSQL:
+		return postgreSQLSchemas(ctx, db, nil)
+	case config.TypeMySQL:
+		var database string
+		if err := db.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&database); err != nil {
+			return nil, err
+		}
+		return []string{database}, nil
+	case config.TypeSQLite:
+		return []string{sqliteSchema}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database type for inspection: %s", dbType)
+	}
+}
+
+// ListTables returns the names of the tables of the database behind db, as
+// given by QualifiedName, without reading their structure
+func ListTables(ctx context.Context, db *sql.DB, dbType string, options Options) ([]string, error) {
+	var statement string
+	var args []any
+	switch dbType {
+	case config.TypePostgreSQL:
+		schemas, err := postgreSQLSchemas(ctx, db, options.Schemas)
+		if err != nil {
+			return nil, err
+		}
+		statement = `SELECT schemaname || '.' || tablename FROM pg_catalog.pg_tables
+			WHERE schemaname = ANY($1) ORDER BY schemaname, tablename`
+		args = []any{postgreSQLArray(schemas)}
+	case config.TypeMySQL:
+		statement = `SELECT TABLE_NAME FROM information_schema.TABLES
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME`
+	case config.TypeSQLite:
+		statement = `SELECT name FROM sqlite_master
+			WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`
+	default:
+		return nil, fmt.Errorf("unsupported database type for inspection: %s", dbType)
+	}
+
+	return queryStrings(ctx, db, statement, args...)
+}
+
+// QualifiedName names a table or view the way dbear shows it: qualified by
+// its schema on PostgreSQL only
+func (c *Catalog) QualifiedName(schema, name string) string {
+	if c.Type == config.TypePostgreSQL {
+		return schema + "." + name
+	}
+	return name
+}
+
+// Table returns the table named schema.name, or nil
+func (c *Catalog) Table(schema, name string) *Table {
+	for i := range c.Tables {
+		if c.Tables[i].Schema == schema && c.Tables[i].Name == name {
+			return &c.Tables[i]
+		}
+	}
+	return nil
+}
+
+func (c *Catalog) sort() {
+	sort.Strings(c.Schemas)
+	sort.Slice(c.Tables, func(i, j int) bool {
+		if c.Tables[i].Schema != c.Tables[j].Schema {
+			return c.Tables[i].Schema < c.Tables[j].Schema
+		}
+		return c.Tables[i].Name < c.Tables[j].Name
+	})
+	sort.Slice(c.Views, func(i, j int) bool {
+		if c.Views[i].Schema != c.Views[j].Schema {
+			return c.Views[i].Schema < c.Views[j].Schema
+		}
+		return c.Views[i].Name < c.Views[j].Name
+	})
+	sort.Slice(c.Functions, func(i, j int) bool {
+		if c.Functions[i].Schema != c.Functions[j].Schema {
+			return c.Functions[i].Schema < c.Functions[j].Schema
+		}
+		if c.Functions[i].Name != c.Functions[j].Name {
+			return c.Functions[i].Name < c.Functions[j].Name
+		}
+		return c.Functions[i].Arguments < c.Functions[j].Arguments
+	})
+	for i := range c.Tables {
+		table := &c.Tables[i]
+		sort.Slice(table.Indexes, func(a, b int) bool { return table.Indexes[a].Name < table.Indexes[b].Name })
+		sort.Slice(table.Constraints, func(a, b int) bool { return table.Constraints[a].Name < table.Constraints[b].Name })
+	}
+}
+
+// queryStrings returns the single string column of the rows of statement
+func queryStrings(ctx context.Context, db *sql.DB, statement string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// tableIndex finds tables and views by schema and name while they are read
+type tableIndex struct {
+	catalog *Catalog
+	tables  map[[2]string]int
+	views   map[[2]string]int
+}
+
+func newTableIndex(c *Catalog) *tableIndex {
+	index := &tableIndex{catalog: c, tables: map[[2]string]int{}, views: map[[2]string]int{}}
+	for i, table := range c.Tables {
+		index.tables[[2]string{table.Schema, table.Name}] = i
+	}
+	for i, view := range c.Views {
+		index.views[[2]string{view.Schema, view.Name}] = i
+	}
+	return index
+}
+
+// table returns the table named schema.name, or nil when it was not read,
+// such as tables of other schemas
+func (index *tableIndex) table(schema, name string) *Table {
+	if i, ok := index.tables[[2]string{schema, name}]; ok {
+		return &index.catalog.Tables[i]
+	}
+	return nil
+}
+
+// addColumn appends column to the table or view named schema.name
+func (index *tableIndex) addColumn(schema, name string, column Column) {
+	key := [2]string{schema, name}
+	if i, ok := index.tables[key]; ok {
+		index.catalog.Tables[i].Columns = append(index.catalog.Tables[i].Columns, column)
+	} else if i, ok := index.views[key]; ok {
+		index.catalog.Views[i].Columns = append(index.catalog.Views[i].Columns, column)
+	}
+}
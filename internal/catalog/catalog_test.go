@@ -0,0 +1,170 @@
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"dbear/internal/config"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchemaSQL = `CREATE TABLE customers (
+	id integer PRIMARY KEY,
+	email text NOT NULL UNIQUE,
+	name varchar(100) DEFAULT 'anonymous'
+);
+CREATE TABLE orders (
+	customer_id integer NOT NULL REFERENCES customers ON DELETE CASCADE,
+	number integer,
+	total numeric(10, 2),
+	PRIMARY KEY (customer_id, number)
+);
+CREATE INDEX orders_total ON orders (total);
+CREATE INDEX customers_lower_email ON customers (lower(email));
+CREATE VIEW big_orders AS SELECT customer_id, total FROM orders WHERE total > 100;
+INSERT INTO customers (id, email) VALUES (1, 'ada@example.com'), (2, 'grace@example.com');
+INSERT INTO orders VALUES (1, 1, 120), (1, 2, 80), (2, 1, 10);
+`
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for _, statement := range strings.Split(sqliteSchemaSQL, ";\n") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("%s: %s", statement, err)
+		}
+	}
+	return db
+}
+
+func TestInspectSQLite(t *testing.T) {
+	db := openSQLite(t)
+
+	c, err := Inspect(context.Background(), db, config.TypeSQLite, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(c.Schemas, []string{"main"}) || len(c.Tables) != 2 || len(c.Views) != 1 {
+		t.Fatalf("unexpected catalog: %+v", c)
+	}
+
+	customers := c.Table("main", "customers")
+	if customers == nil || customers.RowEstimate != 2 || !reflect.DeepEqual(customers.PrimaryKey, []string{"id"}) {
+		t.Fatalf("unexpected customers table: %+v", customers)
+	}
+	columns := []struct {
+		name         string
+		columnType   string
+		nullable     bool
+		defaultValue string
+	}{
+		{"id", "INTEGER", false, ""},
+		{"email", "TEXT", false, ""},
+		{"name", "varchar(100)", true, "'anonymous'"},
+	}
+	if len(customers.Columns) != len(columns) {
+		t.Fatalf("got columns %+v", customers.Columns)
+	}
+	for i, want := range columns {
+		column := customers.Columns[i]
+		defaultValue := ""
+		if column.Default != nil {
+			defaultValue = *column.Default
+		}
+		if column.Name != want.name || column.Type != want.columnType || column.Nullable != want.nullable || defaultValue != want.defaultValue {
+			t.Errorf("got column %+v with default %q, expected %+v", column, defaultValue, want)
+		}
+	}
+
+	indexes := map[string]Index{}
+	for _, index := range customers.Indexes {
+		indexes[index.Name] = index
+	}
+	if expression, ok := indexes["customers_lower_email"]; !ok || expression.Unique || !reflect.DeepEqual(expression.Columns, []string{""}) ||
+		!strings.Contains(expression.Definition, "lower(email)") {
+		t.Errorf("unexpected expression index: %+v", expression)
+	}
+	unique := 0
+	for _, index := range customers.Indexes {
+		if index.Unique && reflect.DeepEqual(index.Columns, []string{"email"}) {
+			unique++
+		}
+	}
+	if unique != 1 {
+		t.Errorf("expected a unique index on email, got %+v", customers.Indexes)
+	}
+
+	orders := c.Table("main", "orders")
+	if orders == nil || !reflect.DeepEqual(orders.PrimaryKey, []string{"customer_id", "number"}) || orders.RowEstimate != 3 {
+		t.Fatalf("unexpected orders table: %+v", orders)
+	}
+	if len(orders.ForeignKeys) != 1 {
+		t.Fatalf("got foreign keys %+v", orders.ForeignKeys)
+	}
+	key := orders.ForeignKeys[0]
+	if !reflect.DeepEqual(key.Columns, []string{"customer_id"}) || key.ReferencedTable != "customers" ||
+		!reflect.DeepEqual(key.ReferencedColumns, []string{"id"}) || key.OnDelete != "CASCADE" {
+		t.Errorf("unexpected foreign key: %+v", key)
+	}
+
+	view := c.Views[0]
+	if view.Name != "big_orders" || len(view.Columns) != 2 || !strings.HasPrefix(view.Definition, "CREATE VIEW") {
+		t.Errorf("unexpected view: %+v", view)
+	}
+}
+
+func TestListSQLite(t *testing.T) {
+	db := openSQLite(t)
+
+	tables, err := ListTables(context.Background(), db, config.TypeSQLite, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tables, []string{"customers", "orders"}) {
+		t.Fatalf("got tables %v", tables)
+	}
+
+	schemas, err := ListSchemas(context.Background(), db, config.TypeSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(schemas, []string{"main"}) {
+		t.Fatalf("got schemas %v", schemas)
+	}
+
+	if _, err := Inspect(context.Background(), db, "oracle", Options{}); err == nil {
+		t.Fatal("expected an error for an unsupported database type")
+	}
+}
+
+func TestQualifiedName(t *testing.T) {
+	tests := []struct {
+		dbType   string
+		expected string
+	}{
+		{config.TypePostgreSQL, "public.users"},
+		{config.TypeMySQL, "users"},
+		{config.TypeSQLite, "users"},
+	}
+
+	for _, test := range tests {
+		c := &Catalog{Type: test.dbType}
+		if name := c.QualifiedName("public", "users"); name != test.expected {
+			t.Errorf("%s: got %s, expected %s", test.dbType, name, test.expected)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+func inspectMySQL(ctx context.Context, db *sql.DB, c *Catalog) error {
+	var database string
+	if err := db.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&database); err != nil {
+		return err
+	}
+	c.Schemas = []string{database}
+
+	// TABLE_ROWS is exact for MyISAM and estimated for InnoDB
+	rows, err := db.QueryContext(ctx, `SELECT t.TABLE_NAME, t.TABLE_TYPE, COALESCE(t.TABLE_COMMENT, ''),
+			COALESCE(t.TABLE_ROWS, -1), COALESCE(t.DATA_LENGTH + t.INDEX_LENGTH, -1),
+			COALESCE(v.VIEW_DEFINITION, '')
+		FROM information_schema.TABLES t
+		LEFT JOIN information_schema.VIEWS v ON v.TABLE_SCHEMA = t.TABLE_SCHEMA AND v.TABLE_NAME = t.TABLE_NAME
+		WHERE t.TABLE_SCHEMA = ?`, database)
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	for rows.Next() {
+		var name, kind, comment, definition string
+		var estimate, size int64
+		if err := rows.Scan(&name, &kind, &comment, &estimate, &size, &definition); err != nil {
+			rows.Close()
+			return err
+		}
+		if kind == "VIEW" {
+			c.Views = append(c.Views, View{Schema: database, Name: name, Definition: definition, Columns: []Column{}})
+			continue
+		}
+		c.Tables = append(c.Tables, Table{Schema: database, Name: name, Comment: comment, RowEstimate: estimate, Size: size, Columns: []Column{}})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	index := newTableIndex(c)
+
+	rows, err = db.QueryContext(ctx, `SELECT TABLE_NAME, COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE = 'YES',
+			COLUMN_DEFAULT, EXTRA LIKE '%auto_increment%', COALESCE(COLUMN_COMMENT, '')
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, ORDINAL_POSITION`, database)
+	if err != nil {
+		return fmt.Errorf("failed to list columns: %w", err)
+	}
+	for rows.Next() {
+		var table string
+		var column Column
+		var defaultValue sql.NullString
+		if err := rows.Scan(&table, &column.Name, &column.Type, &column.Nullable, &defaultValue, &column.Identity, &column.Comment); err != nil {
+			rows.Close()
+			return err
+		}
+		if defaultValue.Valid {
+			column.Default = &defaultValue.String
+		}
+		index.addColumn(database, table, column)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Functional indexes have no column name
+	rows, err = db.QueryContext(ctx, `SELECT TABLE_NAME, INDEX_NAME, NON_UNIQUE = 0, COALESCE(COLUMN_NAME, '')
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX`, database)
+	if err != nil {
+		return fmt.Errorf("failed to list indexes: %w", err)
+	}
+	for rows.Next() {
+		var table, name, column string
+		var unique bool
+		if err := rows.Scan(&table, &name, &unique, &column); err != nil {
+			rows.Close()
+			return err
+		}
+		t := index.table(database, table)
+		if t == nil {
+			continue
+		}
+		last := len(t.Indexes) - 1
+		if last < 0 || t.Indexes[last].Name != name {
+			t.Indexes = append(t.Indexes, Index{Name: name, Columns: []string{}, Unique: unique, Primary: name == "PRIMARY"})
+			last++
+		}
+		if column != "" {
+			t.Indexes[last].Columns = append(t.Indexes[last].Columns, column)
+		}
+		if name == "PRIMARY" {
+			t.PrimaryKey = t.Indexes[last].Columns
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = db.QueryContext(ctx, `SELECT k.TABLE_NAME, k.CONSTRAINT_NAME, k.COLUMN_NAME,
+			k.REFERENCED_TABLE_SCHEMA, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME,
+			r.UPDATE_RULE, r.DELETE_RULE
+		FROM information_schema.KEY_COLUMN_USAGE k
+		JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+			ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+			AND r.TABLE_NAME = k.TABLE_NAME
+		WHERE k.TABLE_SCHEMA = ? AND k.REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY k.TABLE_NAME, k.CONSTRAINT_NAME, k.ORDINAL_POSITION`, database)
+	if err != nil {
+		return fmt.Errorf("failed to list foreign keys: %w", err)
+	}
+	for rows.Next() {
+		var table, name, column, referencedSchema, referencedTable, referencedColumn, onUpdate, onDelete string
+		if err := rows.Scan(&table, &name, &column, &referencedSchema, &referencedTable, &referencedColumn, &onUpdate, &onDelete); err != nil {
+			rows.Close()
+			return err
+		}
+		t := index.table(database, table)
+		if t == nil {
+			continue
+		}
+		last := len(t.ForeignKeys) - 1
+		if last < 0 || t.ForeignKeys[last].Name != name {
+			t.ForeignKeys = append(t.ForeignKeys, ForeignKey{
+				Name:             name,
+				ReferencedSchema: referencedSchema,
+				ReferencedTable:  referencedTable,
+				OnUpdate:         onUpdate,
+				OnDelete:         onDelete,
+			})
+			last++
+		}
+		t.ForeignKeys[last].Columns = append(t.ForeignKeys[last].Columns, column)
+		t.ForeignKeys[last].ReferencedColumns = append(t.ForeignKeys[last].ReferencedColumns, referencedColumn)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := inspectMySQLChecks(ctx, db, database, index); err != nil {
+		return err
+	}
+	return inspectMySQLRoutines(ctx, db, database, c)
+}
+
+// inspectMySQLChecks reads the check constraints, which MySQL only enforces
+// since 8.0.16
+func inspectMySQLChecks(ctx context.Context, db *sql.DB, database string, index *tableIndex) error {
+	rows, err := db.QueryContext(ctx, `SELECT t.TABLE_NAME, c.CONSTRAINT_NAME, c.CHECK_CLAUSE
+		FROM information_schema.TABLE_CONSTRAINTS t
+		JOIN information_schema.CHECK_CONSTRAINTS c
+			ON c.CONSTRAINT_SCHEMA = t.CONSTRAINT_SCHEMA AND c.CONSTRAINT_NAME = t.CONSTRAINT_NAME
+		WHERE t.TABLE_SCHEMA = ? AND t.CONSTRAINT_TYPE = 'CHECK'`, database)
+	if err != nil {
+		// Older servers have no CHECK_CONSTRAINTS table
+		return nil
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table, name, clause string
+		if err := rows.Scan(&table, &name, &clause); err != nil {
+			return err
+		}
+		if !strings.HasPrefix(clause, "(") {
+			clause = "(" + clause + ")"
+		}
+		if t := index.table(database, table); t != nil {
+			t.Constraints = append(t.Constraints, Constraint{Name: name, Type: "CHECK", Definition: "CHECK " + clause})
+		}
+	}
+	return rows.Err()
+}
+
+// inspectMySQLRoutines reads the functions and procedures of database
+func inspectMySQLRoutines(ctx context.Context, db *sql.DB, database string, c *Catalog) error {
+	rows, err := db.QueryContext(ctx, `SELECT ROUTINE_NAME, ROUTINE_TYPE FROM information_schema.ROUTINES
+		WHERE ROUTINE_SCHEMA = ?`, database)
+	if err != nil {
+		return fmt.Errorf("failed to list routines: %w", err)
+	}
+	for rows.Next() {
+		var function Function
+		if err := rows.Scan(&function.Name, &function.Kind); err != nil {
+			rows.Close()
+			return err
+		}
+		function.Schema = database
+		function.Kind = strings.ToLower(function.Kind)
+		c.Functions = append(c.Functions, function)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range c.Functions {
+		function := &c.Functions[i]
+		statement := fmt.Sprintf("SHOW CREATE %s `%s`", strings.ToUpper(function.Kind), strings.ReplaceAll(function.Name, "`", "``"))
+		definition, err := showCreate(ctx, db, statement)
+		if err != nil {
+			return fmt.Errorf("failed to read %s %s: %w", function.Kind, function.Name, err)
+		}
+		function.Definition = definition
+	}
+	return nil
+}
+
+// showCreate returns the statement of a SHOW CREATE FUNCTION or PROCEDURE,
+// which is empty without the privilege to read it
+func showCreate(ctx context.Context, db *sql.DB, statement string) (string, error) {
+	rows, err := db.QueryContext(ctx, statement)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	if !rows.Next() {
+		return "", rows.Err()
+	}
+	values := make([]sql.NullString, len(columns))
+	pointers := make([]any, len(values))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := rows.Scan(pointers...); err != nil {
+		return "", err
+	}
+	// The statement is the third column, after the name and the SQL mode
+	return values[2].String, nil
+}
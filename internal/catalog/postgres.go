@@ -0,0 +1,224 @@
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// postgreSQLArray passes a string slice as a PostgreSQL array parameter
+func postgreSQLArray(values []string) any {
+	return pq.Array(values)
+}
+
+// postgreSQLSchemas lists the schemas holding user objects, or checks that
+// the wanted ones exist
+func postgreSQLSchemas(ctx context.Context, db *sql.DB, wanted []string) ([]string, error) {
+	schemas, err := queryStrings(ctx, db, `SELECT nspname FROM pg_catalog.pg_namespace
+		WHERE nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
+			AND nspname NOT LIKE 'pg_temp_%' AND nspname NOT LIKE 'pg_toast_temp_%'
+		ORDER BY nspname`)
+	if err != nil {
+		return nil, err
+	}
+	if len(wanted) == 0 {
+		return schemas, nil
+	}
+
+	existing := map[string]bool{}
+	for _, schema := range schemas {
+		existing[schema] = true
+	}
+	for _, schema := range wanted {
+		if !existing[schema] {
+			return nil, fmt.Errorf("schema '%s' does not exist", schema)
+		}
+	}
+	return wanted, nil
+}
+
+// postgreSQLActions names the foreign key actions of pg_constraint
+var postgreSQLActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+func inspectPostgreSQL(ctx context.Context, db *sql.DB, c *Catalog, options Options) error {
+	schemas, err := postgreSQLSchemas(ctx, db, options.Schemas)
+	if err != nil {
+		return err
+	}
+	c.Schemas = schemas
+	schemaArray := postgreSQLArray(schemas)
+
+	// reltuples is -1 for tables never vacuumed or analyzed since PostgreSQL 14
+	rows, err := db.QueryContext(ctx, `SELECT n.nspname, c.relname, c.relkind,
+			COALESCE(obj_description(c.oid, 'pg_class'), ''),
+			CASE WHEN c.reltuples < 0 THEN -1 ELSE c.reltuples::bigint END,
+			pg_total_relation_size(c.oid),
+			CASE WHEN c.relkind IN ('v', 'm') THEN pg_get_viewdef(c.oid, true) ELSE '' END
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'p', 'v', 'm') AND NOT c.relispartition AND n.nspname = ANY($1)`, schemaArray)
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	for rows.Next() {
+		var schema, name, kind, comment, definition string
+		var estimate, size int64
+		if err := rows.Scan(&schema, &name, &kind, &comment, &estimate, &size, &definition); err != nil {
+			rows.Close()
+			return err
+		}
+		if kind == "v" || kind == "m" {
+			c.Views = append(c.Views, View{Schema: schema, Name: name, Materialized: kind == "m", Definition: definition, Columns: []Column{}})
+			continue
+		}
+		c.Tables = append(c.Tables, Table{Schema: schema, Name: name, Comment: comment, RowEstimate: estimate, Size: size, Columns: []Column{}})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	index := newTableIndex(c)
+
+	rows, err = db.QueryContext(ctx, `SELECT n.nspname, c.relname, a.attname,
+			format_type(a.atttypid, a.atttypmod), NOT a.attnotnull,
+			pg_get_expr(d.adbin, d.adrelid), a.attidentity <> '',
+			COALESCE(col_description(c.oid, a.attnum), '')
+		FROM pg_catalog.pg_attribute a
+		JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attnum > 0 AND NOT a.attisdropped
+			AND c.relkind IN ('r', 'p', 'v', 'm') AND n.nspname = ANY($1)
+		ORDER BY n.nspname, c.relname, a.attnum`, schemaArray)
+	if err != nil {
+		return fmt.Errorf("failed to list columns: %w", err)
+	}
+	for rows.Next() {
+		var schema, table string
+		var column Column
+		var defaultValue sql.NullString
+		if err := rows.Scan(&schema, &table, &column.Name, &column.Type, &column.Nullable, &defaultValue, &column.Identity, &column.Comment); err != nil {
+			rows.Close()
+			return err
+		}
+		if defaultValue.Valid {
+			column.Default = &defaultValue.String
+		}
+		index.addColumn(schema, table, column)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Expression index columns are read as their expression
+	rows, err = db.QueryContext(ctx, `SELECT n.nspname, t.relname, i.relname, ix.indisunique, ix.indisprimary,
+			pg_get_indexdef(ix.indexrelid),
+			ARRAY(SELECT pg_get_indexdef(ix.indexrelid, k + 1, true)
+				FROM generate_subscripts(ix.indkey, 1) AS k
+				WHERE k < ix.indnkeyatts ORDER BY k)
+		FROM pg_catalog.pg_index ix
+		JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid
+		JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = ANY($1)`, schemaArray)
+	if err != nil {
+		return fmt.Errorf("failed to list indexes: %w", err)
+	}
+	for rows.Next() {
+		var schema, table string
+		var idx Index
+		if err := rows.Scan(&schema, &table, &idx.Name, &idx.Unique, &idx.Primary, &idx.Definition, pq.Array(&idx.Columns)); err != nil {
+			rows.Close()
+			return err
+		}
+		if t := index.table(schema, table); t != nil {
+			t.Indexes = append(t.Indexes, idx)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = db.QueryContext(ctx, `SELECT n.nspname, c.relname, con.conname, con.contype,
+			pg_get_constraintdef(con.oid),
+			ARRAY(SELECT a.attname FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord),
+			COALESCE(rn.nspname, ''), COALESCE(rc.relname, ''),
+			ARRAY(SELECT a.attname FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_catalog.pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+				ORDER BY k.ord),
+			con.confupdtype, con.confdeltype
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class c ON c.oid = con.conrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_catalog.pg_class rc ON rc.oid = con.confrelid
+		LEFT JOIN pg_catalog.pg_namespace rn ON rn.oid = rc.relnamespace
+		WHERE con.contype IN ('p', 'f', 'u', 'c') AND n.nspname = ANY($1)
+		ORDER BY n.nspname, c.relname, con.conname`, schemaArray)
+	if err != nil {
+		return fmt.Errorf("failed to list constraints: %w", err)
+	}
+	for rows.Next() {
+		var schema, table, name, kind, definition, onUpdate, onDelete string
+		var key ForeignKey
+		if err := rows.Scan(&schema, &table, &name, &kind, &definition, pq.Array(&key.Columns),
+			&key.ReferencedSchema, &key.ReferencedTable, pq.Array(&key.ReferencedColumns), &onUpdate, &onDelete); err != nil {
+			rows.Close()
+			return err
+		}
+		t := index.table(schema, table)
+		if t == nil {
+			continue
+		}
+		switch kind {
+		case "p":
+			t.PrimaryKey = key.Columns
+		case "u":
+			t.Constraints = append(t.Constraints, Constraint{Name: name, Type: "UNIQUE", Definition: definition})
+		case "c":
+			t.Constraints = append(t.Constraints, Constraint{Name: name, Type: "CHECK", Definition: definition})
+		case "f":
+			key.Name = name
+			key.OnUpdate = postgreSQLActions[onUpdate]
+			key.OnDelete = postgreSQLActions[onDelete]
+			t.ForeignKeys = append(t.ForeignKeys, key)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Functions created by extensions come and go with them
+	rows, err = db.QueryContext(ctx, `SELECT n.nspname, p.proname, pg_get_function_identity_arguments(p.oid),
+			CASE p.prokind WHEN 'p' THEN 'procedure' ELSE 'function' END,
+			pg_get_functiondef(p.oid)
+		FROM pg_catalog.pg_proc p
+		JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+		WHERE p.prokind IN ('f', 'p') AND n.nspname = ANY($1)
+			AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d
+				WHERE d.classid = 'pg_catalog.pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e')`, schemaArray)
+	if err != nil {
+		return fmt.Errorf("failed to list functions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var function Function
+		if err := rows.Scan(&function.Schema, &function.Name, &function.Arguments, &function.Kind, &function.Definition); err != nil {
+			return err
+		}
+		c.Functions = append(c.Functions, function)
+	}
+	return rows.Err()
+}
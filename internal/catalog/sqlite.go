@@ -0,0 +1,198 @@
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// sqliteSchema is the schema of the database file SQLite opened
+const sqliteSchema = "main"
+
+func inspectSQLite(ctx context.Context, db *sql.DB, c *Catalog) error {
+	c.Schemas = []string{sqliteSchema}
+
+	rows, err := db.QueryContext(ctx, `SELECT name, type, COALESCE(sql, '') FROM sqlite_master
+		WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%'`)
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	for rows.Next() {
+		var name, kind, definition string
+		if err := rows.Scan(&name, &kind, &definition); err != nil {
+			rows.Close()
+			return err
+		}
+		if kind == "view" {
+			c.Views = append(c.Views, View{Schema: sqliteSchema, Name: name, Definition: definition, Columns: []Column{}})
+			continue
+		}
+		c.Tables = append(c.Tables, Table{Schema: sqliteSchema, Name: name, Columns: []Column{}, Definition: definition})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range c.Tables {
+		if err := inspectSQLiteTable(ctx, db, &c.Tables[i]); err != nil {
+			return fmt.Errorf("failed to inspect table %s: %w", c.Tables[i].Name, err)
+		}
+	}
+	for i := range c.Views {
+		columns, _, err := sqliteColumns(ctx, db, c.Views[i].Name)
+		if err != nil {
+			return fmt.Errorf("failed to inspect view %s: %w", c.Views[i].Name, err)
+		}
+		c.Views[i].Columns = columns
+	}
+
+	// Foreign keys without columns reference the primary key
+	for i := range c.Tables {
+		for j := range c.Tables[i].ForeignKeys {
+			key := &c.Tables[i].ForeignKeys[j]
+			if len(key.ReferencedColumns) > 0 && key.ReferencedColumns[0] != "" {
+				continue
+			}
+			if referenced := c.Table(sqliteSchema, key.ReferencedTable); referenced != nil {
+				key.ReferencedColumns = referenced.PrimaryKey
+			}
+		}
+	}
+	return nil
+}
+
+func inspectSQLiteTable(ctx context.Context, db *sql.DB, table *Table) error {
+	columns, primaryKey, err := sqliteColumns(ctx, db, table.Name)
+	if err != nil {
+		return err
+	}
+	table.Columns = columns
+	table.PrimaryKey = primaryKey
+
+	// SQLite has no statistics to estimate from, and counting is cheap on
+	// local files
+	quoted := `"` + strings.ReplaceAll(table.Name, `"`, `""`) + `"`
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+quoted).Scan(&table.RowEstimate); err != nil {
+		return err
+	}
+	// dbstat is only there when SQLite is built with it
+	var size sql.NullInt64
+	if err := db.QueryRowContext(ctx, `SELECT SUM(pgsize) FROM dbstat
+		WHERE name = ? OR name IN (SELECT name FROM pragma_index_list(?))`, table.Name, table.Name).Scan(&size); err != nil || !size.Valid {
+		table.Size = -1
+	} else {
+		table.Size = size.Int64
+	}
+
+	indexes, err := sqliteIndexes(ctx, db, table.Name)
+	if err != nil {
+		return err
+	}
+	table.Indexes = indexes
+
+	rows, err := db.QueryContext(ctx, `SELECT id, "table", "from", COALESCE("to", ''), on_update, on_delete
+		FROM pragma_foreign_key_list(?) ORDER BY id, seq`, table.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	lastID := -1
+	for rows.Next() {
+		var id int
+		var referencedTable, column, referencedColumn, onUpdate, onDelete string
+		if err := rows.Scan(&id, &referencedTable, &column, &referencedColumn, &onUpdate, &onDelete); err != nil {
+			return err
+		}
+		if id != lastID {
+			table.ForeignKeys = append(table.ForeignKeys, ForeignKey{
+				ReferencedSchema: sqliteSchema,
+				ReferencedTable:  referencedTable,
+				OnUpdate:         onUpdate,
+				OnDelete:         onDelete,
+			})
+			lastID = id
+		}
+		key := &table.ForeignKeys[len(table.ForeignKeys)-1]
+		key.Columns = append(key.Columns, column)
+		key.ReferencedColumns = append(key.ReferencedColumns, referencedColumn)
+	}
+	return rows.Err()
+}
+
+// sqliteColumns returns the columns of a table or view and its primary key
+func sqliteColumns(ctx context.Context, db *sql.DB, name string) ([]Column, []string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name, type, "notnull", dflt_value, pk
+		FROM pragma_table_info(?) ORDER BY cid`, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	columns := []Column{}
+	positions := map[int]string{}
+	for rows.Next() {
+		var column Column
+		var notNull bool
+		var defaultValue sql.NullString
+		var position int
+		if err := rows.Scan(&column.Name, &column.Type, &notNull, &defaultValue, &position); err != nil {
+			return nil, nil, err
+		}
+		// SQLite lets primary key columns of rowid tables hold NULL for legacy
+		// reasons only, so they are shown as NOT NULL like on other engines
+		column.Nullable = !notNull && position == 0
+		if defaultValue.Valid {
+			column.Default = &defaultValue.String
+		}
+		if position > 0 {
+			positions[position] = column.Name
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var primaryKey []string
+	for i := 1; i <= len(positions); i++ {
+		primaryKey = append(primaryKey, positions[i])
+	}
+	return columns, primaryKey, nil
+}
+
+// sqliteIndexes returns the indexes of a table with their columns
+func sqliteIndexes(ctx context.Context, db *sql.DB, table string) ([]Index, error) {
+	rows, err := db.QueryContext(ctx, `SELECT l.name, l."unique", l.origin, COALESCE(m.sql, '')
+		FROM pragma_index_list(?) l
+		LEFT JOIN sqlite_master m ON m.type = 'index' AND m.name = l.name`, table)
+	if err != nil {
+		return nil, err
+	}
+	indexes := []Index{}
+	for rows.Next() {
+		var index Index
+		var origin string
+		if err := rows.Scan(&index.Name, &index.Unique, &origin, &index.Definition); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		index.Primary = origin == "pk"
+		indexes = append(indexes, index)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range indexes {
+		// Expression columns have no name
+		columns, err := queryStrings(ctx, db, `SELECT COALESCE(name, '') FROM pragma_index_info(?) ORDER BY seqno`, indexes[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i].Columns = columns
+	}
+	return indexes, nil
+}
@@ -0,0 +1,334 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+	"dbear/internal/transfer"
+)
+
+const (
+	Usql    = "usql"
+	Psql    = "psql"
+	Pgcli   = "pgcli"
+	MySQL   = "mysql"
+	Mycli   = "mycli"
+	SQLite3 = "sqlite3"
+	Litecli = "litecli"
+)
+
+// clientTypes lists the database types each known client supports
+var clientTypes = map[string][]string{
+	Usql:    {config.TypePostgreSQL, config.TypeMySQL, config.TypeSQLite},
+	Psql:    {config.TypePostgreSQL},
+	Pgcli:   {config.TypePostgreSQL},
+	MySQL:   {config.TypeMySQL},
+	Mycli:   {config.TypeMySQL},
+	SQLite3: {config.TypeSQLite},
+	Litecli: {config.TypeSQLite},
+}
+
+// defaultClients are tried in order when no client is configured
+var defaultClients = map[string][]string{
+	config.TypePostgreSQL: {Usql, Psql, Pgcli},
+	config.TypeMySQL:      {Usql, MySQL, Mycli},
+	config.TypeSQLite:     {Usql, SQLite3, Litecli},
+}
+
+// IsKnown reports whether name is one of the clients dbear knows how to run.
+// Any other value is a command template.
+func IsKnown(name string) bool {
+	_, ok := clientTypes[name]
+	return ok
+}
+
+// Command builds the command opening an interactive session on conn with
+// client, which is a known client name, a command template or empty.
+//
+// Without a client, the first installed one of usql and the clients of the
+// database type is used, and PostgreSQL and MySQL fall back to the client of
+// their official Docker image when none is installed. usql is passed over for
+// MySQL connections with certificate files, which it cannot be given, and
+// litecli for read-only connections, which it cannot open read-only.
+//
+// Templates are split on whitespace and may use the {url}, {host}, {port},
+// {socket}, {database}, {user}, {password} and {file} placeholders. {url}
+// leaves the password out.
+//
+// Passwords never appear on a command line, except through {password}: they
+// are passed in PGPASSWORD or MYSQL_PWD, or in the returned file, which the
+// caller removes once the client has exited.
+//
+// terminal tells whether the session runs in a terminal, which the Docker
+// client is then allocated.
+func Command(conn config.Connection, client string, terminal bool) (*exec.Cmd, *connection.SecretFile, error) {
+	switch {
+	case client == "":
+		for _, name := range defaultClients[conn.Type] {
+			if name == Usql && conn.Type == config.TypeMySQL && connection.HasCertFiles(conn) {
+				// usql cannot be given the certificates of MySQL connections
+				continue
+			}
+			if name == Litecli && conn.ReadOnly {
+				continue
+			}
+			if _, err := exec.LookPath(name); err == nil {
+				return knownCommand(conn, name)
+			}
+		}
+		installable := strings.Join(defaultClients[conn.Type], ", ")
+		if conn.Type == config.TypeSQLite {
+			return nil, nil, fmt.Errorf("no %s client found, install one of %s", conn.Type, installable)
+		}
+		if _, err := exec.LookPath("docker"); err != nil {
+			return nil, nil, fmt.Errorf("no %s client found, install one of %s, or docker", conn.Type, installable)
+		}
+		return transfer.DockerClientCommand(conn, terminal)
+	case IsKnown(client):
+		if !supports(client, conn.Type) {
+			return nil, nil, fmt.Errorf("client %s does not support %s connections", client, conn.Type)
+		}
+		if _, err := exec.LookPath(client); err != nil {
+			return nil, nil, fmt.Errorf("client %s is not installed", client)
+		}
+		return knownCommand(conn, client)
+	default:
+		cmd, err := templateCommand(conn, client)
+		return cmd, nil, err
+	}
+}
+
+func supports(client, dbType string) bool {
+	for _, supported := range clientTypes[client] {
+		if supported == dbType {
+			return true
+		}
+	}
+	return false
+}
+
+func knownCommand(conn config.Connection, client string) (*exec.Cmd, *connection.SecretFile, error) {
+	var args []string
+	var secret *connection.SecretFile
+	env := passwordEnv(conn)
+
+	switch client {
+	case Usql:
+		connString, err := connection.BuildConnectionString(withoutPassword(conn))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to build connection string: %w", err)
+		}
+		args = []string{connString}
+		if conn.Type == config.TypeMySQL && conn.Password != "" {
+			// The MySQL driver of usql reads no environment variable
+			secret, err = writeUsqlPassfile(conn)
+			if err != nil {
+				return nil, nil, err
+			}
+			env = []string{"USQLPASS=" + secret.Path}
+		}
+	case Psql, Pgcli:
+		args = []string{postgreSQLConninfo(conn)}
+	case MySQL:
+		if conn.Password != "" {
+			// --defaults-extra-file must be the first option
+			var err error
+			secret, err = connection.WriteMySQLOptionFile(conn)
+			if err != nil {
+				return nil, nil, err
+			}
+			args = append(args, "--defaults-extra-file="+secret.Path)
+			env = nil
+		}
+		args = append(args, mySQLClientArgs(conn)...)
+		args = append(args, mySQLClientSSLArgs(conn)...)
+	case Mycli:
+		// mycli has no --ssl-mode, only the certificate options
+		args = mySQLClientArgs(conn)
+		if conn.SSLRootCert != "" {
+			args = append(args, "--ssl-ca="+conn.SSLRootCert)
+		}
+		if conn.SSLCert != "" {
+			args = append(args, "--ssl-cert="+conn.SSLCert)
+		}
+		if conn.SSLKey != "" {
+			args = append(args, "--ssl-key="+conn.SSLKey)
+		}
+	case SQLite3, Litecli:
+		path, err := connection.CheckSQLiteFile(conn)
+		if err != nil {
+			return nil, nil, err
+		}
+		if conn.ReadOnly && client == Litecli {
+			return nil, nil, fmt.Errorf("litecli cannot open '%s' read-only, set the client to sqlite3 or usql", conn.Name)
+		}
+		if conn.ReadOnly {
+			args = append(args, "-readonly")
+		}
+		args = append(args, path)
+	}
+
+	cmd := exec.Command(client, args...)
+	cmd.Env = append(os.Environ(), env...)
+	return cmd, secret, nil
+}
+
+// withoutPassword returns conn with its password cleared, for the connection
+// strings placed on a command line
+func withoutPassword(conn config.Connection) config.Connection {
+	conn.Password = ""
+	return conn
+}
+
+// writeUsqlPassfile writes the password of conn into a passfile for usql, in
+// the protocol:host:port:dbname:username:password format of ~/.usqlpass
+func writeUsqlPassfile(conn config.Connection) (*connection.SecretFile, error) {
+	if strings.ContainsAny(conn.Password, ":\r\n") {
+		return nil, fmt.Errorf("usql cannot read passwords containing ':' or line breaks from a file, set the client to mysql or mycli")
+	}
+	return connection.WriteSecretFile("*:*:*:*:*:" + conn.Password + "\n")
+}
+
+func templateCommand(conn config.Connection, template string) (*exec.Cmd, error) {
+	fields := strings.Fields(template)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty client command")
+	}
+
+	placeholders := map[string]string{
+		"{host}":     conn.Host,
+		"{port}":     strconv.Itoa(conn.Port),
+		"{socket}":   conn.Socket,
+		"{database}": conn.Database,
+		"{user}":     conn.Username,
+		"{password}": conn.Password,
+	}
+	if strings.Contains(template, "{url}") {
+		connString, err := connection.BuildConnectionString(withoutPassword(conn))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build connection string: %w", err)
+		}
+		placeholders["{url}"] = connString
+	}
+	if strings.Contains(template, "{file}") {
+		path, err := connection.SQLitePath(conn)
+		if err != nil {
+			return nil, err
+		}
+		placeholders["{file}"] = path
+	}
+
+	pairs := []string{}
+	for placeholder, value := range placeholders {
+		pairs = append(pairs, placeholder, value)
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	args := make([]string, len(fields))
+	for i, field := range fields {
+		args[i] = replacer.Replace(field)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), passwordEnv(conn)...)
+	return cmd, nil
+}
+
+// passwordEnv returns the variable the clients of conn read its password from
+func passwordEnv(conn config.Connection) []string {
+	if conn.Password == "" {
+		return nil
+	}
+
+	switch conn.Type {
+	case config.TypePostgreSQL:
+		return []string{"PGPASSWORD=" + conn.Password}
+	case config.TypeMySQL:
+		return []string{"MYSQL_PWD=" + conn.Password}
+	default:
+		return nil
+	}
+}
+
+// postgreSQLConninfo builds the libpq keyword/value connection string psql and
+// pgcli take as their database argument. The password is left out.
+func postgreSQLConninfo(conn config.Connection) string {
+	host, port := conn.Host, conn.Port
+	if connection.UsesSocket(conn) {
+		host, port = connection.PostgreSQLSocketDir(conn)
+	}
+
+	values := map[string]string{}
+	for key, value := range conn.Params {
+		values[key] = value
+	}
+	values["host"] = host
+	values["port"] = strconv.Itoa(port)
+	values["dbname"] = conn.Database
+	values["user"] = conn.Username
+	values["sslmode"] = conn.SSLMode
+	values["sslrootcert"] = conn.SSLRootCert
+	values["sslcert"] = conn.SSLCert
+	values["sslkey"] = conn.SSLKey
+
+	keys := make([]string, 0, len(values))
+	for key, value := range values {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + "=" + quoteConninfoValue(values[key])
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteConninfoValue single-quotes values libpq would otherwise split
+func quoteConninfoValue(value string) string {
+	if !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+func mySQLClientArgs(conn config.Connection) []string {
+	args := []string{}
+	if connection.UsesSocket(conn) {
+		args = append(args, "-S", conn.Socket)
+	} else {
+		args = append(args, "-h", conn.Host, "-P", strconv.Itoa(conn.Port))
+	}
+	if conn.Username != "" {
+		args = append(args, "-u", conn.Username)
+	}
+	if conn.Database != "" {
+		args = append(args, "-D", conn.Database)
+	}
+	return args
+}
+
+func mySQLClientSSLArgs(conn config.Connection) []string {
+	args := []string{}
+	if mode := connection.MySQLClientSSLMode(conn.SSLMode); mode != "" {
+		args = append(args, "--ssl-mode="+mode)
+	}
+	if conn.SSLRootCert != "" {
+		args = append(args, "--ssl-ca="+conn.SSLRootCert)
+	}
+	if conn.SSLCert != "" {
+		args = append(args, "--ssl-cert="+conn.SSLCert)
+	}
+	if conn.SSLKey != "" {
+		args = append(args, "--ssl-key="+conn.SSLKey)
+	}
+	return args
+}
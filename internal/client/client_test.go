@@ -0,0 +1,121 @@
+package client
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+	"dbear/internal/testutil"
+)
+
+func testConnections(t *testing.T) map[string]config.Connection {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "test.db")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]config.Connection{
+		config.TypePostgreSQL: {
+			Name: "pg", Type: config.TypePostgreSQL, Host: "localhost", Port: 5432,
+			Username: "app", Password: testutil.Sentinel, Database: "app", SSLMode: "require",
+		},
+		config.TypeMySQL: {
+			Name: "my", Type: config.TypeMySQL, Host: "localhost", Port: 3306,
+			Username: "app", Password: testutil.Sentinel, Database: "app", SSLMode: "require",
+		},
+		config.TypeSQLite: {
+			Name: "lite", Type: config.TypeSQLite, Database: file, Password: testutil.Sentinel,
+		},
+	}
+}
+
+// checkPassword fails the test when the password is on the command line of
+// cmd, or when a password is expected and is neither in the variables cmd
+// sets nor in secret
+func checkPassword(t *testing.T, cmd *exec.Cmd, secret *connection.SecretFile, expected bool) {
+	t.Helper()
+	testutil.CheckCommandLine(t, cmd)
+
+	handed := testutil.EnvHoldsPassword(cmd) || testutil.SecretFileHoldsPassword(t, secret)
+	if expected && !handed {
+		t.Fatalf("password neither in the environment nor in a secret file: %q", testutil.AddedEnv(cmd))
+	}
+	if !expected && handed {
+		t.Fatalf("password handed to a client of a database without passwords")
+	}
+}
+
+func TestKnownCommandKeepsPasswordOffCommandLine(t *testing.T) {
+	connections := testConnections(t)
+	tests := []struct {
+		client   string
+		dbType   string
+		password bool
+	}{
+		{Usql, config.TypePostgreSQL, true},
+		{Usql, config.TypeMySQL, true},
+		{Usql, config.TypeSQLite, false},
+		{Psql, config.TypePostgreSQL, true},
+		{Pgcli, config.TypePostgreSQL, true},
+		{MySQL, config.TypeMySQL, true},
+		{Mycli, config.TypeMySQL, true},
+		{SQLite3, config.TypeSQLite, false},
+		{Litecli, config.TypeSQLite, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.client+"/"+test.dbType, func(t *testing.T) {
+			cmd, secret, err := knownCommand(connections[test.dbType], test.client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer secret.Remove()
+			checkPassword(t, cmd, secret, test.password)
+		})
+	}
+}
+
+func TestTemplateCommandKeepsPasswordOffCommandLine(t *testing.T) {
+	connections := testConnections(t)
+	tests := []struct {
+		template string
+		dbType   string
+		password bool
+	}{
+		{"pgcli {url}", config.TypePostgreSQL, true},
+		{"psql -h {host} -p {port} -U {user} -d {database}", config.TypePostgreSQL, true},
+		{"mycli {url}", config.TypeMySQL, true},
+		{"mysql -h {host} -P {port} -u {user} {database}", config.TypeMySQL, true},
+		{"sqlite3 {file}", config.TypeSQLite, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.template, func(t *testing.T) {
+			cmd, err := templateCommand(connections[test.dbType], test.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkPassword(t, cmd, nil, test.password)
+		})
+	}
+}
+
+func TestLitecliRefusesReadOnlyConnections(t *testing.T) {
+	conn := testConnections(t)[config.TypeSQLite]
+	conn.ReadOnly = true
+
+	if _, _, err := knownCommand(conn, Litecli); err == nil {
+		t.Fatal("expected litecli to be refused for a read-only connection")
+	}
+	cmd, _, err := knownCommand(conn, SQLite3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Args[1] != "-readonly" {
+		t.Fatalf("sqlite3 not opened read-only: %q", cmd.Args)
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BundleVersion is the format version written into connection bundles
+const BundleVersion = 1
+
+// Password sources a bundle can point each user at instead of carrying the
+// password itself
+const (
+	// PasswordSourceEnv reads the password from an environment variable, as in
+	// "env:STAGING_DB_PASSWORD"
+	PasswordSourceEnv = "env:"
+	// PasswordSourcePgpass looks the password up in the libpq password file
+	PasswordSourcePgpass = "pgpass"
+	// PasswordSourcePrompt asks for the password on import
+	PasswordSourcePrompt = "prompt"
+)
+
+// Bundle is a shareable set of connection definitions without secrets
+type Bundle struct {
+	Version     int                `json:"version"`
+	Connections []BundleConnection `json:"connections"`
+}
+
+// BundleConnection is a connection whose password is replaced by an optional
+// reference to where each user finds it. Its fields are listed explicitly so
+// that nothing secret is written unless it was deliberately put in the file.
+type BundleConnection struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Host        string            `json:"host"`
+	Port        int               `json:"port"`
+	Socket      string            `json:"socket,omitempty"`
+	Database    string            `json:"database"`
+	Username    string            `json:"username"`
+	SSLMode     string            `json:"ssl_mode,omitempty"`
+	SSLRootCert string            `json:"ssl_root_cert,omitempty"`
+	SSLCert     string            `json:"ssl_cert,omitempty"`
+	SSLKey      string            `json:"ssl_key,omitempty"`
+	Params      map[string]string `json:"params,omitempty"`
+	ReadOnly    bool              `json:"read_only,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Client      string            `json:"client,omitempty"`
+	// Password is never exported, but is read when written into a bundle by
+	// hand
+	Password       string `json:"password,omitempty"`
+	PasswordSource string `json:"password_source,omitempty"`
+}
+
+// NewBundleConnection converts a connection for a bundle, leaving out its
+// password and the parameters holding secrets, whose names it returns
+func NewBundleConnection(conn Connection, passwordSource string) (BundleConnection, []string) {
+	entry := BundleConnection{
+		Name:           conn.Name,
+		Type:           conn.Type,
+		Host:           conn.Host,
+		Port:           conn.Port,
+		Socket:         conn.Socket,
+		Database:       conn.Database,
+		Username:       conn.Username,
+		SSLMode:        conn.SSLMode,
+		SSLRootCert:    conn.SSLRootCert,
+		SSLCert:        conn.SSLCert,
+		SSLKey:         conn.SSLKey,
+		ReadOnly:       conn.ReadOnly,
+		Tags:           conn.Tags,
+		Client:         conn.Client,
+		PasswordSource: passwordSource,
+	}
+
+	stripped := []string{}
+	for key, value := range conn.Params {
+		if IsSecretParam(key) {
+			stripped = append(stripped, key)
+			continue
+		}
+		if entry.Params == nil {
+			entry.Params = map[string]string{}
+		}
+		entry.Params[key] = value
+	}
+	sort.Strings(stripped)
+	return entry, stripped
+}
+
+// Connection converts a bundle entry back into a connection, with the password
+// written into the bundle if any
+func (b BundleConnection) Connection() Connection {
+	return Connection{
+		Name:        b.Name,
+		Type:        b.Type,
+		Host:        b.Host,
+		Port:        b.Port,
+		Socket:      b.Socket,
+		Database:    b.Database,
+		Username:    b.Username,
+		Password:    b.Password,
+		SSLMode:     b.SSLMode,
+		SSLRootCert: b.SSLRootCert,
+		SSLCert:     b.SSLCert,
+		SSLKey:      b.SSLKey,
+		Params:      b.Params,
+		ReadOnly:    b.ReadOnly,
+		Tags:        b.Tags,
+		Client:      b.Client,
+	}
+}
+
+// IsSecretParam tells whether a connection parameter holds a secret, judging
+// by its name
+func IsSecretParam(key string) bool {
+	lower := strings.ToLower(key)
+	for _, word := range []string{"password", "passwd", "pwd", "secret", "token"} {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePasswordSource checks the syntax of a bundle password source. An
+// empty source is valid and means the password is asked for on import.
+func ValidatePasswordSource(source string) error {
+	switch {
+	case source == "", source == PasswordSourcePgpass, source == PasswordSourcePrompt:
+		return nil
+	case strings.HasPrefix(source, PasswordSourceEnv) && len(source) > len(PasswordSourceEnv):
+		return nil
+	default:
+		return fmt.Errorf("invalid password source %q, expected env:<VARIABLE>, pgpass or prompt", source)
+	}
+}
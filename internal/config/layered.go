@@ -0,0 +1,350 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// ProjectConfigNames are the project config files looked up from the current
+// directory upward
+var ProjectConfigNames = []string{".dbear.json", ".dbear.yaml", ".dbear.yml"}
+
+// NewFileManager returns a JSON or YAML manager depending on the extension of
+// configPath
+func NewFileManager(configPath string) Manager {
+	switch strings.ToLower(filepath.Ext(configPath)) {
+	case ".yaml", ".yml":
+		return NewYAMLManager(configPath)
+	default:
+		return NewJSONManager(configPath)
+	}
+}
+
+// FindProjectConfigs returns the project config files found in dir and its
+// parents, outermost first. A directory holding several of them is an error.
+func FindProjectConfigs(dir string) ([]string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	found := []string{}
+	for {
+		inDir := []string{}
+		for _, name := range ProjectConfigNames {
+			path := filepath.Join(absDir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				inDir = append(inDir, path)
+			}
+		}
+		if len(inDir) > 1 {
+			return nil, fmt.Errorf("several project configs in %s: %s", absDir, strings.Join(inDir, ", "))
+		}
+		found = append(inDir, found...)
+
+		parent := filepath.Dir(absDir)
+		if parent == absDir {
+			return found, nil
+		}
+		absDir = parent
+	}
+}
+
+type configLayer struct {
+	// origin is the value Connection.Origin takes for the layer's connections
+	origin  string
+	manager Manager
+}
+
+// LayeredManager merges project configs over the global config. Connections and
+// profiles of a later layer replace those of the same name in earlier ones, and
+// connections keep the path of their file in Origin. Writes go back to the layer each connection
+// came from; connections without an origin belong to the global config.
+type LayeredManager struct {
+	layers []configLayer
+}
+
+// NewLayeredManager layers projectPaths, outermost first, over the global
+// config at globalPath
+func NewLayeredManager(globalPath string, projectPaths []string) *LayeredManager {
+	layers := []configLayer{{manager: NewFileManager(globalPath)}}
+	for _, path := range projectPaths {
+		layers = append(layers, configLayer{origin: path, manager: NewFileManager(path)})
+	}
+
+	return &LayeredManager{
+		layers: layers,
+	}
+}
+
+func (m *LayeredManager) Load() (*Config, error) {
+	raw, err := m.loadLayers()
+	if err != nil {
+		return nil, err
+	}
+	return m.merge(raw), nil
+}
+
+// merge lays the configs of the layers over one another
+func (m *LayeredManager) merge(raw []*Config) *Config {
+	merged := &Config{Connections: []Connection{}}
+	index := map[string]int{}
+	for i, layer := range m.layers {
+		for _, conn := range raw[i].Connections {
+			conn.Origin = layer.origin
+			if at, found := index[conn.Name]; found {
+				merged.Connections[at] = conn
+				continue
+			}
+			index[conn.Name] = len(merged.Connections)
+			merged.Connections = append(merged.Connections, conn)
+		}
+
+		if raw[i].DefaultConnection != "" {
+			merged.DefaultConnection = raw[i].DefaultConnection
+		}
+		if raw[i].Client != "" {
+			merged.Client = raw[i].Client
+		}
+		for name, profile := range raw[i].Profiles {
+			if merged.Profiles == nil {
+				merged.Profiles = map[string]Profile{}
+			}
+			merged.Profiles[name] = profile
+		}
+
+		if transfer := raw[i].Transfer; transfer != nil {
+			if merged.Transfer == nil {
+				merged.Transfer = &TransferDefaults{}
+			}
+			if transfer.Source != "" {
+				merged.Transfer.Source = transfer.Source
+			}
+			if transfer.Destination != "" {
+				merged.Transfer.Destination = transfer.Destination
+			}
+		}
+	}
+
+	return merged
+}
+
+// Save writes each connection of config into the layer it came from. Layers
+// whose connections did not change are not rewritten, and connections hidden
+// by a later layer are kept as they are. Removing a connection that hides one
+// of an earlier layer is refused, as the hidden one would take its place.
+//
+// Changed settings, profiles and transfer defaults go to the last layer
+// setting them, or to the global config when none does; removed ones are
+// removed from every layer.
+func (m *LayeredManager) Save(config *Config) error {
+	original, err := m.loadLayers()
+	if err != nil {
+		return err
+	}
+	raw, err := m.loadLayers()
+	if err != nil {
+		return err
+	}
+	merged := m.merge(original)
+
+	kept := map[string]bool{}
+	for _, conn := range config.Connections {
+		kept[conn.Name] = true
+	}
+
+	for i, layer := range m.layers {
+		shadowed := map[string]bool{}
+		for _, later := range raw[i+1:] {
+			for _, conn := range later.Connections {
+				shadowed[conn.Name] = true
+			}
+		}
+
+		owned := map[string]Connection{}
+		for _, conn := range config.Connections {
+			if conn.Origin == layer.origin {
+				conn.Origin = ""
+				owned[conn.Name] = conn
+			}
+		}
+
+		connections := []Connection{}
+		written := map[string]bool{}
+		for _, conn := range raw[i].Connections {
+			if updated, found := owned[conn.Name]; found && !written[conn.Name] {
+				connections = append(connections, updated)
+				written[conn.Name] = true
+			} else if shadowed[conn.Name] {
+				connections = append(connections, conn)
+			} else if !kept[conn.Name] {
+				if hidden := m.hiddenOrigin(raw[:i], conn.Name); hidden != "" {
+					return fmt.Errorf("connection '%s' of %s hides the one of the same name in %s, which would take its place: edit the files to remove it", conn.Name, m.describe(i), hidden)
+				}
+			}
+		}
+		for _, conn := range config.Connections {
+			if updated, found := owned[conn.Name]; found && !written[conn.Name] {
+				connections = append(connections, updated)
+				written[conn.Name] = true
+			}
+		}
+		if len(connections) > 0 || len(raw[i].Connections) > 0 {
+			raw[i].Connections = connections
+		}
+	}
+
+	saveSetting(raw, original, config.DefaultConnection, merged.DefaultConnection,
+		func(c *Config) *string { return &c.DefaultConnection })
+	saveSetting(raw, original, config.Client, merged.Client,
+		func(c *Config) *string { return &c.Client })
+	saveTransferDefaults(raw, original, config.Transfer, merged.Transfer)
+	saveProfiles(raw, original, config.Profiles, merged.Profiles)
+
+	for i, layer := range m.layers {
+		if reflect.DeepEqual(raw[i], original[i]) {
+			continue
+		}
+		if err := layer.manager.Save(raw[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hiddenOrigin describes the last of layers holding a connection named name,
+// or returns "" when none does
+func (m *LayeredManager) hiddenOrigin(layers []*Config, name string) string {
+	for i := len(layers) - 1; i >= 0; i-- {
+		for _, conn := range layers[i].Connections {
+			if conn.Name == name {
+				return m.describe(i)
+			}
+		}
+	}
+	return ""
+}
+
+// describe names the file of the layer at index
+func (m *LayeredManager) describe(index int) string {
+	if m.layers[index].origin == "" {
+		return "the global config"
+	}
+	return m.layers[index].origin
+}
+
+// ownerLayer returns the last layer of original for which set holds, or the
+// global config
+func ownerLayer(original []*Config, set func(c *Config) bool) int {
+	for i := len(original) - 1; i >= 0; i-- {
+		if set(original[i]) {
+			return i
+		}
+	}
+	return 0
+}
+
+// saveSetting writes a changed setting into the layer setting it, or clears it
+// in every layer
+func saveSetting(raw, original []*Config, value, merged string, field func(c *Config) *string) {
+	if value == merged {
+		return
+	}
+	if value == "" {
+		for _, layer := range raw {
+			*field(layer) = ""
+		}
+		return
+	}
+	owner := ownerLayer(original, func(c *Config) bool { return *field(c) != "" })
+	*field(raw[owner]) = value
+}
+
+// saveTransferDefaults writes changed transfer defaults field by field, as they
+// are merged
+func saveTransferDefaults(raw, original []*Config, value, merged *TransferDefaults) {
+	if value == nil {
+		value = &TransferDefaults{}
+	}
+	if merged == nil {
+		merged = &TransferDefaults{}
+	}
+
+	fields := []func(t *TransferDefaults) *string{
+		func(t *TransferDefaults) *string { return &t.Source },
+		func(t *TransferDefaults) *string { return &t.Destination },
+	}
+	for _, field := range fields {
+		if *field(value) == *field(merged) {
+			continue
+		}
+		if *field(value) == "" {
+			for _, layer := range raw {
+				if layer.Transfer != nil {
+					*field(layer.Transfer) = ""
+				}
+			}
+			continue
+		}
+		owner := ownerLayer(original, func(c *Config) bool { return c.Transfer != nil && *field(c.Transfer) != "" })
+		if raw[owner].Transfer == nil {
+			raw[owner].Transfer = &TransferDefaults{}
+		}
+		*field(raw[owner].Transfer) = *field(value)
+	}
+
+	for _, layer := range raw {
+		if layer.Transfer != nil && *layer.Transfer == (TransferDefaults{}) {
+			layer.Transfer = nil
+		}
+	}
+}
+
+// saveProfiles writes changed profiles into the layer defining them, and
+// removes deleted ones from every layer
+func saveProfiles(raw, original []*Config, profiles, merged map[string]Profile) {
+	for name, profile := range profiles {
+		if existing, found := merged[name]; found && reflect.DeepEqual(existing, profile) {
+			continue
+		}
+		owner := ownerLayer(original, func(c *Config) bool {
+			_, found := c.Profiles[name]
+			return found
+		})
+		if raw[owner].Profiles == nil {
+			raw[owner].Profiles = map[string]Profile{}
+		}
+		raw[owner].Profiles[name] = profile
+	}
+
+	for name := range merged {
+		if _, found := profiles[name]; found {
+			continue
+		}
+		for _, layer := range raw {
+			delete(layer.Profiles, name)
+			if len(layer.Profiles) == 0 {
+				layer.Profiles = nil
+			}
+		}
+	}
+}
+
+func (m *LayeredManager) loadLayers() ([]*Config, error) {
+	raw := make([]*Config, len(m.layers))
+	for i, layer := range m.layers {
+		cfg, err := layer.manager.Load()
+		if err != nil {
+			if layer.origin != "" {
+				return nil, fmt.Errorf("failed to load %s: %w", layer.origin, err)
+			}
+			return nil, err
+		}
+		raw[i] = cfg
+	}
+	return raw, nil
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupLayers writes a global config and a project config over it
+func setupLayers(t *testing.T, global, project *Config) (*LayeredManager, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	globalPath := filepath.Join(dir, "config.json")
+	projectPath := filepath.Join(dir, "project", ".dbear.json")
+	if err := NewJSONManager(globalPath).Save(global); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewJSONManager(projectPath).Save(project); err != nil {
+		t.Fatal(err)
+	}
+	return NewLayeredManager(globalPath, []string{projectPath}), globalPath, projectPath
+}
+
+func loadFile(t *testing.T, path string) *Config {
+	t.Helper()
+	cfg, err := NewJSONManager(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestLayeredSaveWritesSettingsToTheirLayer(t *testing.T) {
+	manager, globalPath, projectPath := setupLayers(t,
+		&Config{
+			Connections: []Connection{{Name: "shared", Type: TypeSQLite, Database: "shared.db"}},
+			Client:      "psql",
+			Profiles:    map[string]Profile{"nightly": {Source: "shared"}},
+		},
+		&Config{
+			Connections:       []Connection{{Name: "app", Type: TypeSQLite, Database: "app.db"}},
+			DefaultConnection: "app",
+			Transfer:          &TransferDefaults{Source: "app"},
+			Profiles:          map[string]Profile{"seed": {Source: "app"}, "old": {Source: "app"}},
+		},
+	)
+
+	cfg, err := manager.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.DefaultConnection = "shared"
+	cfg.Client = "usql"
+	cfg.Transfer = &TransferDefaults{Source: "app", Destination: "shared"}
+	cfg.Profiles["seed"] = Profile{Source: "app", Destination: "shared"}
+	cfg.Profiles["backup"] = Profile{Command: ProfileDump, Source: "app"}
+	delete(cfg.Profiles, "old")
+	if err := manager.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	global, project := loadFile(t, globalPath), loadFile(t, projectPath)
+	if project.DefaultConnection != "shared" || global.DefaultConnection != "" {
+		t.Fatalf("default connection not written to the project: global %q, project %q", global.DefaultConnection, project.DefaultConnection)
+	}
+	if global.Client != "usql" || project.Client != "" {
+		t.Fatalf("client not written to the global config: global %q, project %q", global.Client, project.Client)
+	}
+	// Transfer defaults are merged field by field: no layer had a destination
+	if project.Transfer == nil || project.Transfer.Source != "app" || global.Transfer == nil || global.Transfer.Destination != "shared" {
+		t.Fatalf("transfer defaults not written field by field: global %+v, project %+v", global.Transfer, project.Transfer)
+	}
+	if project.Profiles["seed"].Destination != "shared" {
+		t.Fatalf("changed profile not written to the project: %+v", project.Profiles)
+	}
+	if _, found := project.Profiles["old"]; found {
+		t.Fatalf("deleted profile kept in the project: %+v", project.Profiles)
+	}
+	if _, found := global.Profiles["backup"]; !found || global.Profiles["nightly"].Source != "shared" {
+		t.Fatalf("new profile not written to the global config: %+v", global.Profiles)
+	}
+}
+
+func TestLayeredSaveRefusesToUncoverHiddenConnections(t *testing.T) {
+	manager, globalPath, projectPath := setupLayers(t,
+		&Config{Connections: []Connection{
+			{Name: "app", Type: TypeSQLite, Database: "global.db"},
+			{Name: "other", Type: TypeSQLite, Database: "other.db"},
+		}},
+		&Config{Connections: []Connection{
+			{Name: "app", Type: TypeSQLite, Database: "project.db"},
+			{Name: "local", Type: TypeSQLite, Database: "local.db"},
+		}},
+	)
+
+	remove := func(name string) error {
+		cfg, err := manager.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		kept := []Connection{}
+		for _, conn := range cfg.Connections {
+			if conn.Name != name {
+				kept = append(kept, conn)
+			}
+		}
+		cfg.Connections = kept
+		return manager.Save(cfg)
+	}
+
+	err := remove("app")
+	if err == nil || !strings.Contains(err.Error(), "the global config") {
+		t.Fatalf("expected removing a connection hiding a global one to fail, got %v", err)
+	}
+	if len(loadFile(t, projectPath).Connections) != 2 {
+		t.Fatal("project config changed by a refused save")
+	}
+
+	if err := remove("local"); err != nil {
+		t.Fatal(err)
+	}
+	project := loadFile(t, projectPath)
+	if len(project.Connections) != 1 || project.Connections[0].Name != "app" {
+		t.Fatalf("unexpected project connections: %+v", project.Connections)
+	}
+	if len(loadFile(t, globalPath).Connections) != 2 {
+		t.Fatal("global config changed by removing a project connection")
+	}
+}
@@ -6,18 +6,69 @@ const (
 	TypeSQLite     = "sqlite"
 )
 
+const (
+	SSLModeDisable    = "disable"
+	SSLModeAllow      = "allow"
+	SSLModePrefer     = "prefer"
+	SSLModeRequire    = "require"
+	SSLModeVerifyCA   = "verify-ca"
+	SSLModeVerifyFull = "verify-full"
+)
+
 type Connection struct {
-	Name     string `json:"name" yaml:"name"`
-	Type     string `json:"type" yaml:"type"`
-	Host     string `json:"host" yaml:"host"`
-	Port     int    `json:"port" yaml:"port"`
-	Database string `json:"database" yaml:"database"`
-	Username string `json:"username" yaml:"username"`
-	Password string `json:"password" yaml:"password"`
+	Name        string            `json:"name" yaml:"name"`
+	Type        string            `json:"type" yaml:"type"`
+	Host        string            `json:"host" yaml:"host"`
+	Port        int               `json:"port" yaml:"port"`
+	Socket      string            `json:"socket,omitempty" yaml:"socket,omitempty"`
+	Database    string            `json:"database" yaml:"database"`
+	Username    string            `json:"username" yaml:"username"`
+	Password    string            `json:"password" yaml:"password"`
+	SSLMode     string            `json:"ssl_mode,omitempty" yaml:"ssl_mode,omitempty"`
+	SSLRootCert string            `json:"ssl_root_cert,omitempty" yaml:"ssl_root_cert,omitempty"`
+	SSLCert     string            `json:"ssl_cert,omitempty" yaml:"ssl_cert,omitempty"`
+	SSLKey      string            `json:"ssl_key,omitempty" yaml:"ssl_key,omitempty"`
+	Params      map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
+	ReadOnly    bool              `json:"read_only,omitempty" yaml:"read_only,omitempty"`
+	Tags        []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Client is the interactive client `connect` runs, overriding the one of
+	// the config
+	Client string `json:"client,omitempty" yaml:"client,omitempty"`
+	// Origin is the project config file the connection was loaded from, empty
+	// for the global config. It is not stored.
+	Origin string `json:"-" yaml:"-"`
 }
 
+// TransferDefaults names the connections `transfer` uses without asking
+type TransferDefaults struct {
+	Source      string `json:"source,omitempty" yaml:"source,omitempty"`
+	Destination string `json:"destination,omitempty" yaml:"destination,omitempty"`
+}
+
+// Profile is a saved, non-interactive transfer or dump run
+type Profile struct {
+	// Command is ProfileTransfer, the default, or ProfileDump
+	Command       string   `json:"command,omitempty" yaml:"command,omitempty"`
+	Source        string   `json:"source,omitempty" yaml:"source,omitempty"`
+	Destination   string   `json:"destination,omitempty" yaml:"destination,omitempty"`
+	Schemas       []string `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	Tables        []string `json:"tables,omitempty" yaml:"tables,omitempty"`
+	ExcludeTables []string `json:"exclude_tables,omitempty" yaml:"exclude_tables,omitempty"`
+	// Output is the file a dump profile writes to
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+}
+
+const (
+	ProfileTransfer = "transfer"
+	ProfileDump     = "dump"
+)
+
 type Config struct {
-	Connections []Connection `json:"connections" yaml:"connections"`
+	Connections       []Connection       `json:"connections" yaml:"connections"`
+	DefaultConnection string             `json:"default_connection,omitempty" yaml:"default_connection,omitempty"`
+	Client            string             `json:"client,omitempty" yaml:"client,omitempty"`
+	Transfer          *TransferDefaults  `json:"transfer,omitempty" yaml:"transfer,omitempty"`
+	Profiles          map[string]Profile `json:"profiles,omitempty" yaml:"profiles,omitempty"`
 }
 
 type Manager interface {
@@ -29,3 +80,13 @@ func IsValidType(dbType string) bool {
 	return dbType == TypeMySQL || dbType == TypePostgreSQL || dbType == TypeSQLite
 }
 
+// IsValidSSLMode reports whether mode is one of the libpq-style sslmode values.
+// An empty mode is valid and means the connection does not configure TLS.
+func IsValidSSLMode(mode string) bool {
+	switch mode {
+	case "", SSLModeDisable, SSLModeAllow, SSLModePrefer, SSLModeRequire, SSLModeVerifyCA, SSLModeVerifyFull:
+		return true
+	default:
+		return false
+	}
+}
@@ -1,5 +1,13 @@
 package config
 
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
 type YAMLManager struct {
 	configPath string
 }
@@ -11,10 +19,42 @@ func NewYAMLManager(configPath string) *YAMLManager {
 }
 
 func (m *YAMLManager) Load() (*Config, error) {
-	return nil, nil
+	if _, err := os.Stat(m.configPath); os.IsNotExist(err) {
+		return &Config{Connections: []Connection{}}, nil
+	}
+
+	data, err := os.ReadFile(m.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	if config.Connections == nil {
+		config.Connections = []Connection{}
+	}
+
+	return &config, nil
 }
 
 func (m *YAMLManager) Save(config *Config) error {
-	return nil
-}
+	dir := filepath.Dir(m.configPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
 
+	var data bytes.Buffer
+	encoder := yaml.NewEncoder(&data)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	return os.WriteFile(m.configPath, data.Bytes(), 0644)
+}
@@ -3,6 +3,7 @@ package connection
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
 	"dbear/internal/config"
 )
@@ -12,37 +13,138 @@ func BuildConnectionString(conn config.Connection) (string, error) {
 	case TypePostgreSQL:
 		return buildPostgreSQLString(conn), nil
 	case TypeMySQL:
-		return buildMySQLString(conn), nil
+		return buildMySQLString(conn)
 	case TypeSQLite:
-		return buildSQLiteString(conn), nil
+		return buildSQLiteString(conn)
 	default:
 		return "", fmt.Errorf("unsupported database type: %s", conn.Type)
 	}
 }
 
 func buildPostgreSQLString(conn config.Connection) string {
-	user := url.UserPassword(conn.Username, conn.Password)
+	user := urlUser(conn)
 	u := &url.URL{
 		Scheme: "postgres",
 		User:   user,
 		Host:   fmt.Sprintf("%s:%d", conn.Host, conn.Port),
 		Path:   conn.Database,
 	}
+
+	// usql reads socket connections as <scheme>+unix URLs whose path is the
+	// socket directory and port followed by the database name.
+	if UsesSocket(conn) {
+		dir, port := PostgreSQLSocketDir(conn)
+		u.Scheme = "postgres+unix"
+		u.Host = ""
+		u.Path = fmt.Sprintf("%s:%d/%s", dir, port, conn.Database)
+	}
+
+	query := paramsQuery(conn.Params)
+	if conn.SSLMode != "" {
+		query.Set("sslmode", conn.SSLMode)
+	}
+	if conn.SSLRootCert != "" {
+		query.Set("sslrootcert", conn.SSLRootCert)
+	}
+	if conn.SSLCert != "" {
+		query.Set("sslcert", conn.SSLCert)
+	}
+	if conn.SSLKey != "" {
+		query.Set("sslkey", conn.SSLKey)
+	}
+	u.RawQuery = query.Encode()
+
 	return u.String()
 }
 
-func buildMySQLString(conn config.Connection) string {
-	user := url.UserPassword(conn.Username, conn.Password)
+func buildMySQLString(conn config.Connection) (string, error) {
+	// usql hands the query to go-sql-driver/mysql, which only takes
+	// certificates through a TLS config registered in its own process
+	if HasCertFiles(conn) {
+		return "", fmt.Errorf("mysql URLs cannot carry the certificate files of '%s', use the mysql or mycli client", conn.Name)
+	}
+
+	user := urlUser(conn)
 	u := &url.URL{
 		Scheme: "mysql",
 		User:   user,
 		Host:   fmt.Sprintf("%s:%d", conn.Host, conn.Port),
 		Path:   conn.Database,
 	}
-	return u.String()
+
+	if UsesSocket(conn) {
+		u.Scheme = "mysql+unix"
+		u.Host = ""
+		u.Path = conn.Socket + "/" + conn.Database
+	}
+
+	query := paramsQuery(conn.Params)
+	if tlsParam := mysqlURLTLSParam(conn.SSLMode); tlsParam != "" {
+		query.Set("tls", tlsParam)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+func buildSQLiteString(conn config.Connection) (string, error) {
+	path, err := SQLitePath(conn)
+	if err != nil {
+		return "", err
+	}
+
+	query := paramsQuery(conn.Params)
+	if conn.ReadOnly {
+		query.Set("mode", "ro")
+	}
+
+	u := &url.URL{
+		Scheme:   "sqlite",
+		Path:     path,
+		RawQuery: query.Encode(),
+	}
+	return u.String(), nil
+}
+
+// urlUser returns the user info of conn, leaving the password separator out
+// when there is no password so that drivers still look it up elsewhere
+func urlUser(conn config.Connection) *url.Userinfo {
+	if conn.Password == "" {
+		return url.User(conn.Username)
+	}
+	return url.UserPassword(conn.Username, conn.Password)
+}
+
+func paramsQuery(params map[string]string) url.Values {
+	query := url.Values{}
+	for key, value := range params {
+		query.Set(key, value)
+	}
+	return query
+}
+
+// ParseParams parses driver parameters written as a URL query string, such as
+// "application_name=dbear&connect_timeout=5". It returns nil for an empty string.
+func ParseParams(raw string) (map[string]string, error) {
+	raw = strings.TrimPrefix(strings.TrimSpace(raw), "?")
+	if raw == "" {
+		return nil, nil
+	}
+
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	params := make(map[string]string, len(values))
+	for key := range values {
+		params[key] = values.Get(key)
+	}
+	return params, nil
 }
 
-func buildSQLiteString(conn config.Connection) string {
-	return fmt.Sprintf("sqlite://%s", conn.Database)
+// FormatParams is the inverse of ParseParams.
+func FormatParams(params map[string]string) string {
+	return paramsQuery(params).Encode()
 }
 
@@ -0,0 +1,234 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"dbear/internal/config"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// OpenDB opens and pings a database/sql handle for conn using the Go drivers
+// linked into dbear.
+func OpenDB(conn config.Connection) (*sql.DB, error) {
+	return OpenDBContext(context.Background(), conn)
+}
+
+// OpenDBContext is OpenDB with a context bounding the connection attempt.
+func OpenDBContext(ctx context.Context, conn config.Connection) (*sql.DB, error) {
+	switch conn.Type {
+	case config.TypePostgreSQL:
+		return openPostgreSQL(ctx, conn)
+	case config.TypeMySQL:
+		return openMySQL(ctx, conn)
+	case config.TypeSQLite:
+		return openSQLite(ctx, conn)
+	default:
+		return nil, fmt.Errorf("unsupported database type for driver connection: %s", conn.Type)
+	}
+}
+
+func openPostgreSQL(ctx context.Context, conn config.Connection) (*sql.DB, error) {
+	var lastErr error
+	for _, mode := range postgresDriverSSLModes(conn.SSLMode) {
+		connector, err := pq.NewConnector(postgresDSN(conn, mode))
+		if err != nil {
+			return nil, err
+		}
+
+		db := sql.OpenDB(connector)
+		if err := db.PingContext(ctx); err != nil {
+			db.Close()
+			lastErr = err
+			continue
+		}
+
+		return db, nil
+	}
+
+	return nil, lastErr
+}
+
+func postgresDSN(conn config.Connection, sslMode string) string {
+	host, port := conn.Host, conn.Port
+	if UsesSocket(conn) {
+		host, port = PostgreSQLSocketDir(conn)
+	}
+
+	values := [][2]string{
+		{"host", host},
+		{"port", fmt.Sprintf("%d", port)},
+		{"user", conn.Username},
+		{"password", conn.Password},
+		{"dbname", conn.Database},
+		{"sslmode", sslMode},
+	}
+
+	if sslMode != config.SSLModeDisable {
+		values = append(values,
+			[2]string{"sslrootcert", conn.SSLRootCert},
+			[2]string{"sslcert", conn.SSLCert},
+			[2]string{"sslkey", conn.SSLKey},
+		)
+	}
+
+	paramKeys := make([]string, 0, len(conn.Params))
+	for key := range conn.Params {
+		paramKeys = append(paramKeys, key)
+	}
+	sort.Strings(paramKeys)
+	for _, key := range paramKeys {
+		values = append(values, [2]string{key, conn.Params[key]})
+	}
+
+	parts := make([]string, 0, len(values))
+	for _, kv := range values {
+		if kv[1] == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", kv[0], quoteDSNValue(kv[1])))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// quoteDSNValue quotes a value for a libpq keyword/value connection string.
+func quoteDSNValue(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + replacer.Replace(value) + "'"
+}
+
+func openMySQL(ctx context.Context, conn config.Connection) (*sql.DB, error) {
+	cfg, err := mysqlConfig(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	db := sql.OpenDB(connector)
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// openSQLite opens the database file of conn, which must exist, read-only
+// when the connection is. Params are passed as URI parameters, such as
+// _pragma=foreign_keys(1).
+func openSQLite(ctx context.Context, conn config.Connection) (*sql.DB, error) {
+	path, err := CheckSQLiteFile(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	query := paramsQuery(conn.Params)
+	if conn.ReadOnly {
+		query.Set("mode", "ro")
+	}
+	dsn := (&url.URL{Scheme: "file", Path: path, RawQuery: query.Encode()}).String()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// mysqlDriverParams are the DSN parameters go-sql-driver/mysql reads itself.
+// It sends any other parameter to the server as a system variable, as in
+// time_zone or sql_mode.
+var mysqlDriverParams = map[string]bool{
+	"allowAllFiles": true, "allowCleartextPasswords": true, "allowFallbackToPlaintext": true,
+	"allowNativePasswords": true, "allowOldPasswords": true, "charset": true, "checkConnLiveness": true,
+	"clientFoundRows": true, "collation": true, "columnsWithAlias": true, "compress": true,
+	"connectionAttributes": true, "interpolateParams": true, "loc": true, "maxAllowedPacket": true,
+	"multiStatements": true, "parseTime": true, "readTimeout": true, "rejectReadOnly": true,
+	"serverPubKey": true, "timeTruncate": true, "timeout": true, "tls": true, "writeTimeout": true,
+}
+
+var mysqlVariablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// CheckMySQLParams fails on the params the MySQL driver would fail to connect
+// with: a loc that is not a known time zone, and params it does not read whose
+// names cannot be system variables
+func CheckMySQLParams(params map[string]string) error {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch {
+		case key == "loc":
+			if _, err := time.LoadLocation(params[key]); err != nil {
+				return fmt.Errorf("invalid mysql parameter loc: unknown time zone '%s'", params[key])
+			}
+		case mysqlDriverParams[key]:
+		case !mysqlVariablePattern.MatchString(key):
+			return fmt.Errorf("invalid mysql parameter '%s', expected a driver option or a system variable name", key)
+		}
+	}
+	return nil
+}
+
+func mysqlConfig(conn config.Connection) (*mysql.Config, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = conn.Username
+	cfg.Passwd = conn.Password
+	cfg.Net = "tcp"
+	cfg.Addr = fmt.Sprintf("%s:%d", conn.Host, conn.Port)
+	if UsesSocket(conn) {
+		cfg.Net = "unix"
+		cfg.Addr = conn.Socket
+	}
+	cfg.DBName = conn.Database
+
+	if len(conn.Params) > 0 {
+		// Round-trip through a DSN so the driver sorts options such as charset,
+		// collation and loc from server system variables, as it would for a
+		// hand-written DSN.
+		dsn := cfg.FormatDSN()
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+
+		parsed, err := mysql.ParseDSN(dsn + separator + paramsQuery(conn.Params).Encode())
+		if err != nil {
+			return nil, fmt.Errorf("invalid mysql parameters: %w", err)
+		}
+		cfg = parsed
+	}
+
+	tlsConfig, fallback, err := mysqlTLSConfig(conn)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		cfg.TLS = tlsConfig
+		cfg.AllowFallbackToPlaintext = fallback
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,50 @@
+package connection
+
+import (
+	"testing"
+
+	"dbear/internal/config"
+)
+
+func TestCheckMySQLParams(t *testing.T) {
+	tests := []struct {
+		params map[string]string
+		valid  bool
+	}{
+		{map[string]string{"charset": "utf8mb4", "parseTime": "true"}, true},
+		{map[string]string{"loc": "Europe/Paris"}, true},
+		{map[string]string{"loc": "Local"}, true},
+		{map[string]string{"loc": "Europe/Atlantis"}, false},
+		{map[string]string{"time_zone": "'+00:00'"}, true},
+		{map[string]string{"sql_mode": "'STRICT_ALL_TABLES'"}, true},
+		{map[string]string{"time-zone": "UTC"}, false},
+		{map[string]string{"1st": "x"}, false},
+	}
+
+	for _, test := range tests {
+		err := CheckMySQLParams(test.params)
+		if test.valid && err != nil {
+			t.Errorf("%v: unexpected error: %s", test.params, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: expected an error", test.params)
+		}
+	}
+}
+
+func TestMySQLConfigPassesSystemVariables(t *testing.T) {
+	conn := config.Connection{
+		Type: config.TypeMySQL, Host: "localhost", Port: 3306, Username: "app", Database: "app",
+		Params: map[string]string{"time_zone": "'+00:00'", "loc": "UTC", "charset": "utf8mb4"},
+	}
+	cfg, err := mysqlConfig(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Params["time_zone"] != "'+00:00'" {
+		t.Fatalf("time_zone not passed as a system variable: %v", cfg.Params)
+	}
+	if cfg.Loc.String() != "UTC" {
+		t.Fatalf("loc not read by the driver: %s", cfg.Loc)
+	}
+}
@@ -16,6 +16,7 @@ func NewManager(configManager config.Manager) *Manager {
 	}
 }
 
+// Create adds a connection, replacing any existing connection of the same name
 func (m *Manager) Create(conn config.Connection) error {
 	cfg, err := m.configManager.Load()
 	if err != nil {
@@ -25,6 +26,8 @@ func (m *Manager) Create(conn config.Connection) error {
 	found := false
 	for i, existing := range cfg.Connections {
 		if existing.Name == conn.Name {
+			// The replacement lives where the connection it replaces did
+			conn.Origin = existing.Origin
 			cfg.Connections[i] = conn
 			found = true
 			break
@@ -84,3 +87,47 @@ func (m *Manager) Delete(name string) error {
 	return m.configManager.Save(cfg)
 }
 
+// TransferDefaults returns the connections `transfer` uses without asking, as
+// declared by the config
+func (m *Manager) TransferDefaults() (config.TransferDefaults, error) {
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return config.TransferDefaults{}, err
+	}
+
+	if cfg.Transfer == nil {
+		return config.TransferDefaults{}, nil
+	}
+	return *cfg.Transfer, nil
+}
+
+// DefaultConnection returns the name of the connection commands use when none
+// is given, or an empty string
+func (m *Manager) DefaultConnection() (string, error) {
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return "", err
+	}
+
+	return cfg.DefaultConnection, nil
+}
+
+// Client returns the interactive client of the config, or an empty string
+func (m *Manager) Client() (string, error) {
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return "", err
+	}
+
+	return cfg.Client, nil
+}
+
+// Profiles returns the saved profiles by name
+func (m *Manager) Profiles() (map[string]config.Profile, error) {
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.Profiles, nil
+}
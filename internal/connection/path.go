@@ -0,0 +1,102 @@
+package connection
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"dbear/internal/config"
+)
+
+const postgresSocketPrefix = ".s.PGSQL."
+
+// ExpandPath expands a leading ~ to the user's home directory and makes path
+// absolute, resolving relative paths against baseDir (or the working directory
+// when baseDir is empty).
+func ExpandPath(path, baseDir string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve home directory: %w", err)
+		}
+		path = filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+	}
+
+	if !filepath.IsAbs(path) && baseDir != "" {
+		path = filepath.Join(baseDir, path)
+	}
+
+	return filepath.Abs(path)
+}
+
+// SQLitePath returns the absolute path of the database file of a SQLite connection.
+func SQLitePath(conn config.Connection) (string, error) {
+	if conn.Database == "" {
+		return "", fmt.Errorf("sqlite connection '%s' has no database file", conn.Name)
+	}
+	return ExpandPath(conn.Database, "")
+}
+
+// CheckSQLiteFile resolves the database file of a SQLite connection and checks
+// that it exists, so clients do not silently create an empty database.
+func CheckSQLiteFile(conn config.Connection) (string, error) {
+	path, err := SQLitePath(conn)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("sqlite database file %s does not exist", path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to access sqlite database file: %w", err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("sqlite database path %s is a directory", path)
+	}
+
+	return path, nil
+}
+
+// PostgreSQLSocketDir returns the socket directory and port of a PostgreSQL
+// connection. The socket may be configured either as the directory libpq expects
+// or as the full path of the .s.PGSQL.<port> file.
+func PostgreSQLSocketDir(conn config.Connection) (string, int) {
+	dir := conn.Socket
+	port := conn.Port
+
+	base := filepath.Base(dir)
+	if strings.HasPrefix(base, postgresSocketPrefix) {
+		if socketPort, err := strconv.Atoi(strings.TrimPrefix(base, postgresSocketPrefix)); err == nil {
+			port = socketPort
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	if port == 0 {
+		port = 5432
+	}
+
+	return dir, port
+}
+
+// SocketDir returns the directory that holds the unix socket of conn.
+func SocketDir(conn config.Connection) string {
+	if conn.Type == config.TypePostgreSQL {
+		dir, _ := PostgreSQLSocketDir(conn)
+		return dir
+	}
+	return filepath.Dir(conn.Socket)
+}
+
+// UsesSocket reports whether conn connects through a unix socket.
+func UsesSocket(conn config.Connection) bool {
+	return conn.Socket != "" && conn.Type != config.TypeSQLite
+}
@@ -0,0 +1,64 @@
+package connection
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"dbear/internal/config"
+)
+
+// SecretFile is a temporary file, readable by the owner only, that hands a
+// password to a client without putting it on its command line
+type SecretFile struct {
+	Path string
+}
+
+// WriteSecretFile writes content into a new SecretFile. The caller removes it
+// once the client has exited.
+func WriteSecretFile(content string) (*SecretFile, error) {
+	// CreateTemp creates the file with mode 0600
+	file, err := os.CreateTemp("", "dbear-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secret file: %w", err)
+	}
+
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to write secret file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to write secret file: %w", err)
+	}
+
+	return &SecretFile{Path: file.Name()}, nil
+}
+
+// Remove deletes the file. It is safe to call on a nil SecretFile.
+func (f *SecretFile) Remove() {
+	if f != nil {
+		os.Remove(f.Path)
+	}
+}
+
+// WriteMySQLOptionFile writes the password of conn into an option file the
+// mysql clients read with --defaults-extra-file
+func WriteMySQLOptionFile(conn config.Connection) (*SecretFile, error) {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return WriteSecretFile(fmt.Sprintf("[client]\npassword=\"%s\"\n", replacer.Replace(conn.Password)))
+}
+
+// WriteDockerEnvFile writes env, a list of KEY=value entries, into a file for
+// docker run --env-file. Docker reads one entry per line without unquoting,
+// so values cannot hold line breaks.
+func WriteDockerEnvFile(env []string) (*SecretFile, error) {
+	for _, entry := range env {
+		if strings.ContainsAny(entry, "\r\n") {
+			key, _, _ := strings.Cut(entry, "=")
+			return nil, fmt.Errorf("%s contains a line break, which docker env files cannot hold", key)
+		}
+	}
+	return WriteSecretFile(strings.Join(env, "\n") + "\n")
+}
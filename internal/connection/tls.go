@@ -0,0 +1,169 @@
+package connection
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"dbear/internal/config"
+)
+
+// HasTLS reports whether conn asks for an encrypted connection.
+func HasTLS(conn config.Connection) bool {
+	return conn.SSLMode != "" && conn.SSLMode != config.SSLModeDisable
+}
+
+// HasCertFiles reports whether conn names a CA certificate, client
+// certificate or client key file.
+func HasCertFiles(conn config.Connection) bool {
+	return conn.SSLRootCert != "" || conn.SSLCert != "" || conn.SSLKey != ""
+}
+
+// MySQLClientSSLMode maps a libpq-style sslmode onto the value expected by the
+// mysql and mysqldump --ssl-mode flag. It returns an empty string when no mode is set.
+func MySQLClientSSLMode(mode string) string {
+	switch mode {
+	case config.SSLModeDisable:
+		return "DISABLED"
+	case config.SSLModeAllow, config.SSLModePrefer:
+		return "PREFERRED"
+	case config.SSLModeRequire:
+		return "REQUIRED"
+	case config.SSLModeVerifyCA:
+		return "VERIFY_CA"
+	case config.SSLModeVerifyFull:
+		return "VERIFY_IDENTITY"
+	default:
+		return ""
+	}
+}
+
+// mysqlURLTLSParam maps a libpq-style sslmode onto the go-sql-driver "tls" parameter,
+// which is what usql understands in mysql:// URLs.
+func mysqlURLTLSParam(mode string) string {
+	switch mode {
+	case config.SSLModeDisable:
+		return "false"
+	case config.SSLModeAllow, config.SSLModePrefer:
+		return "preferred"
+	case config.SSLModeRequire:
+		return "skip-verify"
+	case config.SSLModeVerifyCA, config.SSLModeVerifyFull:
+		return "true"
+	default:
+		return ""
+	}
+}
+
+// postgresDriverSSLModes returns the sslmode values to try, in order, with lib/pq.
+// lib/pq does not implement allow and prefer, so those fall back between the
+// plaintext and TLS variants the same way libpq does.
+func postgresDriverSSLModes(mode string) []string {
+	switch mode {
+	case "", config.SSLModeDisable:
+		return []string{config.SSLModeDisable}
+	case config.SSLModeAllow:
+		return []string{config.SSLModeDisable, config.SSLModeRequire}
+	case config.SSLModePrefer:
+		return []string{config.SSLModeRequire, config.SSLModeDisable}
+	default:
+		return []string{mode}
+	}
+}
+
+// mysqlTLSConfig builds the TLS configuration for go-sql-driver/mysql. It returns
+// nil when TLS is disabled. fallback is true when the driver may continue in
+// plaintext if the server does not support TLS.
+func mysqlTLSConfig(conn config.Connection) (tlsConfig *tls.Config, fallback bool, err error) {
+	if !HasTLS(conn) {
+		return nil, false, nil
+	}
+
+	tlsConfig = &tls.Config{}
+
+	switch conn.SSLMode {
+	case config.SSLModeAllow, config.SSLModePrefer:
+		tlsConfig.InsecureSkipVerify = true
+		fallback = true
+	case config.SSLModeRequire:
+		tlsConfig.InsecureSkipVerify = true
+	case config.SSLModeVerifyCA:
+		// Only the host name check is skipped: the chain is still verified,
+		// against the CA file when there is one and the system roots otherwise
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = verifyChainOnly(tlsConfig)
+	case config.SSLModeVerifyFull:
+		tlsConfig.ServerName = conn.Host
+	}
+
+	if conn.SSLRootCert != "" {
+		pem, err := os.ReadFile(conn.SSLRootCert)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, false, fmt.Errorf("no certificates found in %s", conn.SSLRootCert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if conn.SSLCert != "" || conn.SSLKey != "" {
+		cert, err := tls.LoadX509KeyPair(conn.SSLCert, conn.SSLKey)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, fallback, nil
+}
+
+// verifyChainOnly checks the server certificate against the RootCAs of
+// tlsConfig, or the system roots when it has none, without checking the host
+// name, which is what verify-ca means.
+func verifyChainOnly(tlsConfig *tls.Config) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("server did not present a certificate")
+		}
+
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs[i] = cert
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err := certs[0].Verify(x509.VerifyOptions{Roots: tlsConfig.RootCAs, Intermediates: intermediates})
+		return err
+	}
+}
+
+// SSLModeFromMySQL maps a mysql client --ssl-mode value back onto the libpq-style
+// sslmode dbear stores. Unknown values map to an empty string.
+func SSLModeFromMySQL(mode string) string {
+	switch strings.ToUpper(strings.TrimSpace(mode)) {
+	case "DISABLED":
+		return config.SSLModeDisable
+	case "PREFERRED":
+		return config.SSLModePrefer
+	case "REQUIRED":
+		return config.SSLModeRequire
+	case "VERIFY_CA":
+		return config.SSLModeVerifyCA
+	case "VERIFY_IDENTITY":
+		return config.SSLModeVerifyFull
+	default:
+		return ""
+	}
+}
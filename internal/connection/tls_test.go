@@ -0,0 +1,150 @@
+package connection
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dbear/internal/config"
+)
+
+// testServerCertificate returns a server certificate for other.example signed
+// by a new CA, and the path of a file holding that CA
+func testServerCertificate(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "dbear test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "other.example"},
+		DNSNames:     []string{"other.example"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	serverDER, err := x509.CreateCertificate(rand.Reader, serverTemplate, caCert, &serverKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey}, caFile
+}
+
+// handshake runs a TLS handshake between clientConfig and a server presenting
+// cert, returning the error the client saw
+func handshake(t *testing.T, clientConfig *tls.Config, cert tls.Certificate) error {
+	t.Helper()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		serverConn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer serverConn.Close()
+		_ = serverConn.(*tls.Conn).Handshake()
+	}()
+
+	clientConn, err := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+	if err != nil {
+		return err
+	}
+	return clientConn.Close()
+}
+
+func TestMySQLTLSConfigVerification(t *testing.T) {
+	cert, caFile := testServerCertificate(t)
+
+	tests := []struct {
+		name    string
+		mode    string
+		caFile  string
+		success bool
+		// check tells whether the failure is the one expected
+		check func(error) bool
+	}{
+		{name: "require", mode: config.SSLModeRequire, success: true},
+		{name: "verify-ca with CA ignores the host name", mode: config.SSLModeVerifyCA, caFile: caFile, success: true},
+		{
+			name: "verify-ca without CA still verifies the chain",
+			mode: config.SSLModeVerifyCA,
+			check: func(err error) bool {
+				var unknown x509.UnknownAuthorityError
+				return errors.As(err, &unknown)
+			},
+		},
+		{
+			name:   "verify-full checks the host name",
+			mode:   config.SSLModeVerifyFull,
+			caFile: caFile,
+			check: func(err error) bool {
+				var hostname x509.HostnameError
+				return errors.As(err, &hostname)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn := config.Connection{Type: config.TypeMySQL, Host: "127.0.0.1", SSLMode: test.mode, SSLRootCert: test.caFile}
+			tlsConfig, fallback, err := mysqlTLSConfig(conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fallback {
+				t.Fatalf("%s must not fall back to plaintext", test.mode)
+			}
+
+			err = handshake(t, tlsConfig, cert)
+			if test.success && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !test.success && (err == nil || !test.check(err)) {
+				t.Fatalf("got error %v, expected a verification failure", err)
+			}
+		})
+	}
+}
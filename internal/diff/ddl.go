@@ -0,0 +1,417 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"dbear/internal/catalog"
+	"dbear/internal/config"
+	"dbear/internal/query"
+)
+
+// phase orders the statements of a migration: objects are created before
+// those depending on them, and dropped after them
+type phase int
+
+const (
+	createSchemas phase = iota
+	dropKeys
+	dropViews
+	dropFunctions
+	dropTables
+	createTables
+	alterColumns
+	addKeys
+	addForeignKeys
+	createFunctions
+	createViews
+	dropSchemas
+	phaseCount
+)
+
+// generator collects the statements of a migration by phase
+type generator struct {
+	dbType     string
+	statements [phaseCount][]string
+}
+
+// SQL returns the statements turning the destination of changes into the
+// source, for databases of type dbType. What SQLite cannot alter in place,
+// such as the type of a column, is left as comments.
+func SQL(dbType string, changes []Change) string {
+	g := &generator{dbType: dbType}
+	for _, change := range changes {
+		switch change.Object {
+		case "schema":
+			g.schema(change)
+		case "table":
+			g.table(change)
+		case "view":
+			g.view(change)
+		case "function", "procedure":
+			g.function(change)
+		}
+	}
+
+	var b strings.Builder
+	for _, statements := range g.statements {
+		for _, statement := range statements {
+			b.WriteString(statement)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func (g *generator) add(p phase, format string, args ...any) {
+	g.statements[p] = append(g.statements[p], fmt.Sprintf(format, args...))
+}
+
+// sqliteCannot notes a change SQLite has no ALTER TABLE for
+func (g *generator) sqliteCannot(p phase, what string) {
+	g.add(p, "-- SQLite cannot %s in place: rebuild the table to apply it", what)
+}
+
+func (g *generator) quote(name string) string {
+	return query.QuoteName(g.dbType, name)
+}
+
+func (g *generator) quoteNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = g.quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// qualified quotes the name of a table, view or function, qualified by its
+// schema on PostgreSQL only
+func (g *generator) qualified(schema, name string) string {
+	if g.dbType == config.TypePostgreSQL {
+		return g.quote(schema) + "." + g.quote(name)
+	}
+	return g.quote(name)
+}
+
+func (g *generator) schema(change Change) {
+	if change.Action == Create {
+		g.add(createSchemas, "CREATE SCHEMA %s;", g.quote(change.Name))
+	} else {
+		g.add(dropSchemas, "DROP SCHEMA %s;", g.quote(change.Name))
+	}
+}
+
+func (g *generator) table(change Change) {
+	table := change.table
+	name := g.qualified(table.Schema, table.Name)
+	switch change.Action {
+	case Create:
+		g.createTable(table)
+	case Drop:
+		g.add(dropTables, "DROP TABLE %s;", name)
+	case Alter:
+		for _, tableChange := range change.Changes {
+			g.alterTable(table, name, tableChange)
+		}
+	}
+}
+
+func (g *generator) createTable(table *catalog.Table) {
+	name := g.qualified(table.Schema, table.Name)
+	indexes := tableIndexes(table)
+
+	// SQLite keeps the statement creating the table, foreign keys included
+	if g.dbType == config.TypeSQLite {
+		g.add(createTables, "%s;", strings.TrimSuffix(strings.TrimSpace(table.Definition), ";"))
+		for _, indexName := range sortedKeys(indexes, nil) {
+			g.createIndex(createTables, table, indexes[indexName])
+		}
+		return
+	}
+
+	lines := []string{}
+	for i := range table.Columns {
+		lines = append(lines, "  "+g.columnDefinition(&table.Columns[i]))
+	}
+	if len(table.PrimaryKey) > 0 {
+		lines = append(lines, "  PRIMARY KEY ("+g.quoteNames(table.PrimaryKey)+")")
+	}
+	g.add(createTables, "CREATE TABLE %s (\n%s\n);", name, strings.Join(lines, ",\n"))
+
+	for _, constraint := range table.Constraints {
+		g.add(createTables, "ALTER TABLE %s ADD CONSTRAINT %s %s;", name, g.quote(constraint.Name), constraint.Definition)
+	}
+	for _, indexName := range sortedKeys(indexes, nil) {
+		g.createIndex(createTables, table, indexes[indexName])
+	}
+	for i := range table.ForeignKeys {
+		g.addForeignKey(name, &table.ForeignKeys[i])
+	}
+}
+
+// alterTable applies a change of a column, key, index or constraint to the
+// table named name
+func (g *generator) alterTable(table *catalog.Table, name string, change Change) {
+	switch change.Object {
+	case "column":
+		switch change.Action {
+		case Create:
+			g.add(alterColumns, "ALTER TABLE %s ADD COLUMN %s;", name, g.columnDefinition(change.source.(*catalog.Column)))
+		case Drop:
+			g.add(alterColumns, "ALTER TABLE %s DROP COLUMN %s;", name, g.quote(change.Name))
+		case Alter:
+			g.alterColumn(name, change.source.(*catalog.Column), change.dest.(*catalog.Column))
+		}
+
+	case "primary key":
+		if g.dbType == config.TypeSQLite {
+			g.sqliteCannot(addKeys, "change the primary key of "+g.quote(table.Name))
+			return
+		}
+		if change.Action != Create {
+			if g.dbType == config.TypeMySQL {
+				g.add(dropKeys, "ALTER TABLE %s DROP PRIMARY KEY;", name)
+			} else {
+				g.add(dropKeys, "ALTER TABLE %s DROP CONSTRAINT %s;", name, g.quote(primaryKeyName(change.dest.(*catalog.Table))))
+			}
+		}
+		if change.Action != Drop {
+			g.add(addKeys, "ALTER TABLE %s ADD PRIMARY KEY (%s);", name, g.quoteNames(change.source.(*catalog.Table).PrimaryKey))
+		}
+
+	case "index":
+		if change.Action != Create {
+			g.dropIndex(table, name, change.dest.(*catalog.Index))
+		}
+		if change.Action != Drop {
+			g.createIndex(addKeys, table, change.source.(*catalog.Index))
+		}
+
+	case "constraint":
+		if g.dbType == config.TypeSQLite {
+			g.sqliteCannot(addKeys, "change the constraint "+g.quote(change.Name)+" of "+g.quote(table.Name))
+			return
+		}
+		if change.Action != Create {
+			// MySQL has DROP CONSTRAINT since 8.0.19 only
+			if g.dbType == config.TypeMySQL {
+				g.add(dropKeys, "ALTER TABLE %s DROP CHECK %s;", name, g.quote(change.Name))
+			} else {
+				g.add(dropKeys, "ALTER TABLE %s DROP CONSTRAINT %s;", name, g.quote(change.Name))
+			}
+		}
+		if change.Action != Drop {
+			constraint := change.source.(*catalog.Constraint)
+			g.add(addKeys, "ALTER TABLE %s ADD CONSTRAINT %s %s;", name, g.quote(constraint.Name), constraint.Definition)
+		}
+
+	case "foreign key":
+		if g.dbType == config.TypeSQLite {
+			g.sqliteCannot(addForeignKeys, "change the foreign key "+change.Name+" of "+g.quote(table.Name))
+			return
+		}
+		if change.Action != Create {
+			key := change.dest.(*catalog.ForeignKey)
+			if g.dbType == config.TypeMySQL {
+				g.add(dropKeys, "ALTER TABLE %s DROP FOREIGN KEY %s;", name, g.quote(key.Name))
+			} else {
+				g.add(dropKeys, "ALTER TABLE %s DROP CONSTRAINT %s;", name, g.quote(key.Name))
+			}
+		}
+		if change.Action != Drop {
+			g.addForeignKey(name, change.source.(*catalog.ForeignKey))
+		}
+	}
+}
+
+// postgreSQLSerials are the types of serial columns, whose default takes
+// values from a sequence created along with them
+var postgreSQLSerials = map[string]string{
+	"smallint": "smallserial",
+	"integer":  "serial",
+	"bigint":   "bigserial",
+}
+
+// columnDefinition returns column as written in CREATE TABLE and ADD COLUMN
+func (g *generator) columnDefinition(column *catalog.Column) string {
+	definition := g.quote(column.Name)
+	if g.dbType == config.TypePostgreSQL && column.Default != nil && strings.HasPrefix(*column.Default, "nextval(") {
+		if serial, ok := postgreSQLSerials[column.Type]; ok {
+			return definition + " " + serial
+		}
+	}
+
+	// SQLite columns may have no type
+	if column.Type != "" {
+		definition += " " + column.Type
+	}
+	if !column.Nullable {
+		definition += " NOT NULL"
+	}
+	if column.Identity && g.dbType == config.TypePostgreSQL {
+		definition += " GENERATED BY DEFAULT AS IDENTITY"
+	}
+	if column.Default != nil {
+		definition += " DEFAULT " + g.defaultValue(*column.Default)
+	}
+	if column.Identity && g.dbType == config.TypeMySQL {
+		definition += " AUTO_INCREMENT"
+	}
+	return definition
+}
+
+// mySQLLiteralDefault matches the defaults MySQL reports that are not string
+// literals
+var mySQLLiteralDefault = regexp.MustCompile(`(?i)^(-?[0-9]+(\.[0-9]+)?|NULL|CURRENT_TIMESTAMP(\([0-9]*\))?|\(.*\))$`)
+
+// defaultValue returns a default as written after DEFAULT. PostgreSQL and
+// SQLite keep defaults as expressions, MySQL keeps string defaults unquoted.
+func (g *generator) defaultValue(value string) string {
+	if g.dbType != config.TypeMySQL || mySQLLiteralDefault.MatchString(value) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func (g *generator) alterColumn(name string, source, dest *catalog.Column) {
+	switch g.dbType {
+	case config.TypeMySQL:
+		g.add(alterColumns, "ALTER TABLE %s MODIFY COLUMN %s;", name, g.columnDefinition(source))
+	case config.TypePostgreSQL:
+		column := "ALTER COLUMN " + g.quote(source.Name)
+		clauses := []string{}
+		if source.Identity != dest.Identity && !source.Identity {
+			clauses = append(clauses, column+" DROP IDENTITY")
+		}
+		if !strings.EqualFold(source.Type, dest.Type) {
+			clauses = append(clauses, fmt.Sprintf("%s TYPE %s USING %s::%s", column, source.Type, g.quote(source.Name), source.Type))
+		}
+		if source.Nullable != dest.Nullable {
+			if source.Nullable {
+				clauses = append(clauses, column+" DROP NOT NULL")
+			} else {
+				clauses = append(clauses, column+" SET NOT NULL")
+			}
+		}
+		if defaultText(source.Default) != defaultText(dest.Default) {
+			if source.Default == nil {
+				clauses = append(clauses, column+" DROP DEFAULT")
+			} else {
+				clauses = append(clauses, column+" SET DEFAULT "+*source.Default)
+			}
+		}
+		if source.Identity != dest.Identity && source.Identity {
+			clauses = append(clauses, column+" ADD GENERATED BY DEFAULT AS IDENTITY")
+		}
+		g.add(alterColumns, "ALTER TABLE %s %s;", name, strings.Join(clauses, ",\n  "))
+	default:
+		g.sqliteCannot(alterColumns, "alter the column "+g.quote(source.Name)+" of "+name)
+	}
+}
+
+// primaryKeyName returns the name of the constraint holding the primary key
+// of a PostgreSQL table, which is that of its index
+func primaryKeyName(table *catalog.Table) string {
+	for _, index := range table.Indexes {
+		if index.Primary {
+			return index.Name
+		}
+	}
+	return table.Name + "_pkey"
+}
+
+func (g *generator) createIndex(p phase, table *catalog.Table, index *catalog.Index) {
+	if g.dbType != config.TypeMySQL {
+		g.add(p, "%s;", strings.TrimSuffix(strings.TrimSpace(index.Definition), ";"))
+		return
+	}
+
+	for _, column := range index.Columns {
+		if column == "" {
+			g.add(p, "-- The index %s of %s has expressions, which are not read: create it by hand", g.quote(index.Name), g.quote(table.Name))
+			return
+		}
+	}
+	unique := ""
+	if index.Unique {
+		unique = "UNIQUE "
+	}
+	g.add(p, "CREATE %sINDEX %s ON %s (%s);", unique, g.quote(index.Name), g.quote(table.Name), g.quoteNames(index.Columns))
+}
+
+func (g *generator) dropIndex(table *catalog.Table, name string, index *catalog.Index) {
+	switch g.dbType {
+	case config.TypeMySQL:
+		g.add(dropKeys, "DROP INDEX %s ON %s;", g.quote(index.Name), name)
+	default:
+		g.add(dropKeys, "DROP INDEX %s;", g.qualified(table.Schema, index.Name))
+	}
+}
+
+func (g *generator) addForeignKey(name string, key *catalog.ForeignKey) {
+	statement := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		name, g.quote(key.Name), g.quoteNames(key.Columns),
+		g.qualified(key.ReferencedSchema, key.ReferencedTable), g.quoteNames(key.ReferencedColumns))
+	if key.OnUpdate != "" && key.OnUpdate != "NO ACTION" {
+		statement += " ON UPDATE " + key.OnUpdate
+	}
+	if key.OnDelete != "" && key.OnDelete != "NO ACTION" {
+		statement += " ON DELETE " + key.OnDelete
+	}
+	g.add(addForeignKeys, "%s;", statement)
+}
+
+func (g *generator) view(change Change) {
+	if change.Action != Create {
+		view := change.dest.(*catalog.View)
+		materialized := ""
+		if view.Materialized {
+			materialized = "MATERIALIZED "
+		}
+		g.add(dropViews, "DROP %sVIEW %s;", materialized, g.qualified(view.Schema, view.Name))
+	}
+	if change.Action == Drop {
+		return
+	}
+
+	view := change.source.(*catalog.View)
+	// SQLite keeps the statement creating the view
+	if g.dbType == config.TypeSQLite {
+		g.add(createViews, "%s;", viewQuery(g.dbType, view))
+		return
+	}
+	materialized := ""
+	if view.Materialized {
+		materialized = "MATERIALIZED "
+	}
+	g.add(createViews, "CREATE %sVIEW %s AS\n%s;", materialized, g.qualified(view.Schema, view.Name), viewQuery(g.dbType, view))
+}
+
+func (g *generator) function(change Change) {
+	source, dest := change.source.(*catalog.Function), change.dest.(*catalog.Function)
+	kind := strings.ToUpper(change.Object)
+
+	// PostgreSQL definitions replace the function in place
+	if change.Action == Drop || change.Action == Alter && g.dbType == config.TypeMySQL {
+		name := g.qualified(dest.Schema, dest.Name)
+		if g.dbType == config.TypePostgreSQL {
+			name += "(" + dest.Arguments + ")"
+		}
+		g.add(dropFunctions, "DROP %s %s;", kind, name)
+	}
+	if change.Action == Drop {
+		return
+	}
+
+	if strings.TrimSpace(source.Definition) == "" {
+		g.add(createFunctions, "-- The definition of %s %s cannot be read: create it by hand", change.Object, change.Name)
+		return
+	}
+	if g.dbType == config.TypeMySQL {
+		g.add(createFunctions, "DELIMITER ;;\n%s ;;\nDELIMITER ;", strings.TrimSpace(definerPattern.ReplaceAllString(source.Definition, "")))
+		return
+	}
+	g.add(createFunctions, "%s;", strings.TrimSuffix(strings.TrimSpace(source.Definition), ";"))
+}
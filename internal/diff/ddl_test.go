@@ -0,0 +1,132 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"dbear/internal/catalog"
+	"dbear/internal/config"
+)
+
+func TestSQLOrdersPostgreSQLStatements(t *testing.T) {
+	source, dest := testCatalogs()
+	changes, err := Schemas(source, dest, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `CREATE SCHEMA "audit";
+DROP INDEX "public"."users_name_idx";
+DROP TABLE "public"."stale";
+CREATE TABLE "public"."orders" (
+  "id" bigint NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "user_id" integer NOT NULL,
+  PRIMARY KEY ("id")
+);
+ALTER TABLE "public"."users" ALTER COLUMN "email" TYPE text USING "email"::text,
+  ALTER COLUMN "email" SET NOT NULL;
+ALTER TABLE "public"."users" ADD COLUMN "name" character varying(50) DEFAULT 'anonymous'::character varying;
+ALTER TABLE "public"."users" DROP COLUMN "legacy";
+CREATE INDEX users_name_idx ON public.users USING btree (name);
+ALTER TABLE "public"."users" ADD CONSTRAINT "users_email_key" UNIQUE (email);
+ALTER TABLE "public"."orders" ADD CONSTRAINT "orders_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON DELETE CASCADE;
+DROP SCHEMA "old";
+`
+	if statements := SQL(config.TypePostgreSQL, changes); statements != expected {
+		t.Fatalf("got:\n%s\nexpected:\n%s", statements, expected)
+	}
+}
+
+func TestColumnDefinition(t *testing.T) {
+	tests := []struct {
+		dbType   string
+		column   catalog.Column
+		expected string
+	}{
+		{config.TypePostgreSQL, catalog.Column{Name: "id", Type: "integer", Default: text("nextval('t_id_seq'::regclass)")}, `"id" serial`},
+		{config.TypePostgreSQL, catalog.Column{Name: "id", Type: "uuid", Default: text("gen_random_uuid()")}, `"id" uuid NOT NULL DEFAULT gen_random_uuid()`},
+		{config.TypePostgreSQL, catalog.Column{Name: "note", Type: "text", Nullable: true}, `"note" text`},
+		{config.TypeMySQL, catalog.Column{Name: "id", Type: "int unsigned", Identity: true}, "`id` int unsigned NOT NULL AUTO_INCREMENT"},
+		{config.TypeMySQL, catalog.Column{Name: "status", Type: "varchar(10)", Default: text("it's")}, "`status` varchar(10) NOT NULL DEFAULT 'it''s'"},
+		{config.TypeMySQL, catalog.Column{Name: "path", Type: "varchar(10)", Nullable: true, Default: text(`C:\`)}, "`path` varchar(10) DEFAULT 'C:\\\\'"},
+		{config.TypeMySQL, catalog.Column{Name: "count", Type: "int", Default: text("-1")}, "`count` int NOT NULL DEFAULT -1"},
+		{config.TypeMySQL, catalog.Column{Name: "created", Type: "datetime(3)", Default: text("CURRENT_TIMESTAMP(3)")}, "`created` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3)"},
+		{config.TypeMySQL, catalog.Column{Name: "tags", Type: "json", Nullable: true, Default: text("(json_array())")}, "`tags` json DEFAULT (json_array())"},
+		{config.TypeSQLite, catalog.Column{Name: "anything", Nullable: true}, `"anything"`},
+	}
+
+	for _, test := range tests {
+		g := &generator{dbType: test.dbType}
+		if definition := g.columnDefinition(&test.column); definition != test.expected {
+			t.Errorf("%s %+v: got %s, expected %s", test.dbType, test.column, definition, test.expected)
+		}
+	}
+}
+
+func TestSQLOfMySQLAndSQLiteAlterations(t *testing.T) {
+	sourceTable := catalog.Table{
+		Name:    "users",
+		Columns: []catalog.Column{{Name: "email", Type: "varchar(255)"}},
+		Indexes: []catalog.Index{{Name: "users_email", Columns: []string{"email"}, Unique: true, Definition: "CREATE UNIQUE INDEX users_email ON users (email)"}},
+	}
+	destTable := catalog.Table{
+		Name:    "users",
+		Columns: []catalog.Column{{Name: "email", Type: "varchar(100)", Nullable: true}},
+		Indexes: []catalog.Index{{Name: "users_email", Columns: []string{"email"}, Definition: "CREATE INDEX users_email ON users (email)"}},
+	}
+
+	tests := []struct {
+		dbType   string
+		expected string
+	}{
+		{
+			config.TypeMySQL,
+			"DROP INDEX `users_email` ON `users`;\n" +
+				"ALTER TABLE `users` MODIFY COLUMN `email` varchar(255) NOT NULL;\n" +
+				"CREATE UNIQUE INDEX `users_email` ON `users` (`email`);\n",
+		},
+		{
+			config.TypeSQLite,
+			`DROP INDEX "users_email";` + "\n" +
+				`-- SQLite cannot alter the column "email" of "users" in place: rebuild the table to apply it` + "\n" +
+				"CREATE UNIQUE INDEX users_email ON users (email);\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.dbType, func(t *testing.T) {
+			source := &catalog.Catalog{Type: test.dbType, Tables: []catalog.Table{sourceTable}}
+			dest := &catalog.Catalog{Type: test.dbType, Tables: []catalog.Table{destTable}}
+			changes, err := Schemas(source, dest, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statements := SQL(test.dbType, changes); statements != test.expected {
+				t.Fatalf("got:\n%s\nexpected:\n%s", statements, test.expected)
+			}
+		})
+	}
+}
+
+func TestSQLOfMySQLRoutines(t *testing.T) {
+	source := &catalog.Catalog{Type: config.TypeMySQL, Functions: []catalog.Function{{
+		Name: "total", Kind: "procedure",
+		Definition: "CREATE DEFINER=`root`@`%` PROCEDURE `total`()\nBEGIN\n  SELECT 2;\nEND",
+	}}}
+	dest := &catalog.Catalog{Type: config.TypeMySQL, Functions: []catalog.Function{{
+		Name: "total", Kind: "procedure",
+		Definition: "CREATE DEFINER=`root`@`%` PROCEDURE `total`()\nBEGIN\n  SELECT 1;\nEND",
+	}}}
+
+	changes, err := Schemas(source, dest, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "DROP PROCEDURE `total`;\nDELIMITER ;;\nCREATE PROCEDURE `total`()\nBEGIN\n  SELECT 2;\nEND ;;\nDELIMITER ;\n"
+	if statements := SQL(config.TypeMySQL, changes); statements != expected {
+		t.Fatalf("got:\n%s\nexpected:\n%s", statements, expected)
+	}
+	if !strings.Contains(describeChanges(changes, "")[0], "alter procedure total") {
+		t.Fatalf("unexpected changes: %v", describeChanges(changes, ""))
+	}
+}
@@ -0,0 +1,476 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"dbear/internal/catalog"
+	"dbear/internal/config"
+	"dbear/internal/query"
+)
+
+// Action says how an object of the destination changes to match the source
+type Action string
+
+const (
+	Create Action = "create"
+	Drop   Action = "drop"
+	Alter  Action = "alter"
+)
+
+// Change is a difference between the schemas of two databases, read as the
+// change turning the destination into the source
+type Change struct {
+	Action Action
+	// Object is schema, table, column, primary key, index, constraint,
+	// foreign key, view or function
+	Object string
+	Name   string
+	// Details tells what differs in an altered object, or describes a
+	// created column
+	Details []string
+	// Changes are those of the columns, keys, indexes and constraints of an
+	// altered table
+	Changes []Change
+
+	// source and dest are the object in each database, nil where it is
+	// missing, and table the table holding it
+	source any
+	dest   any
+	table  *catalog.Table
+}
+
+// Schemas compares the schemas of source and dest, which must be databases of
+// the same type. Only the given PostgreSQL schemas are compared when schemas
+// is not empty.
+func Schemas(source, dest *catalog.Catalog, schemas []string) ([]Change, error) {
+	if source.Type != dest.Type {
+		return nil, fmt.Errorf("cannot compare a %s database with a %s one", source.Type, dest.Type)
+	}
+
+	keep := func(schema string) bool {
+		if len(schemas) == 0 || source.Type != config.TypePostgreSQL {
+			return true
+		}
+		for _, wanted := range schemas {
+			if schema == wanted {
+				return true
+			}
+		}
+		return false
+	}
+
+	changes := []Change{}
+	if source.Type == config.TypePostgreSQL {
+		changes = append(changes, compareNames("schema", filter(source.Schemas, keep), filter(dest.Schemas, keep))...)
+	}
+
+	sourceTables := map[string]*catalog.Table{}
+	for i, table := range source.Tables {
+		if keep(table.Schema) {
+			sourceTables[source.QualifiedName(table.Schema, table.Name)] = &source.Tables[i]
+		}
+	}
+	destTables := map[string]*catalog.Table{}
+	for i, table := range dest.Tables {
+		if keep(table.Schema) {
+			destTables[dest.QualifiedName(table.Schema, table.Name)] = &dest.Tables[i]
+		}
+	}
+	for _, name := range sortedKeys(sourceTables, destTables) {
+		sourceTable, destTable := sourceTables[name], destTables[name]
+		switch {
+		case destTable == nil:
+			changes = append(changes, Change{Action: Create, Object: "table", Name: name, source: sourceTable, table: sourceTable})
+		case sourceTable == nil:
+			changes = append(changes, Change{Action: Drop, Object: "table", Name: name, dest: destTable, table: destTable})
+		default:
+			if tableChanges := compareTables(source, dest, sourceTable, destTable); len(tableChanges) > 0 {
+				changes = append(changes, Change{Action: Alter, Object: "table", Name: name, Changes: tableChanges,
+					source: sourceTable, dest: destTable, table: destTable})
+			}
+		}
+	}
+
+	sourceViews := map[string]*catalog.View{}
+	for i, view := range source.Views {
+		if keep(view.Schema) {
+			sourceViews[source.QualifiedName(view.Schema, view.Name)] = &source.Views[i]
+		}
+	}
+	destViews := map[string]*catalog.View{}
+	for i, view := range dest.Views {
+		if keep(view.Schema) {
+			destViews[dest.QualifiedName(view.Schema, view.Name)] = &dest.Views[i]
+		}
+	}
+	for _, name := range sortedKeys(sourceViews, destViews) {
+		sourceView, destView := sourceViews[name], destViews[name]
+		change := Change{Object: "view", Name: name, source: sourceView, dest: destView}
+		switch {
+		case destView == nil:
+			change.Action = Create
+		case sourceView == nil:
+			change.Action = Drop
+		case sourceView.Materialized != destView.Materialized:
+			change.Action = Alter
+			change.Details = []string{"materialized " + fmt.Sprint(destView.Materialized) + " -> " + fmt.Sprint(sourceView.Materialized)}
+		case normalize(viewQuery(source.Type, sourceView)) != normalize(viewQuery(dest.Type, destView)):
+			change.Action = Alter
+			change.Details = []string{"definition differs"}
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	sourceFunctions := map[string]*catalog.Function{}
+	for i, function := range source.Functions {
+		if keep(function.Schema) {
+			sourceFunctions[functionName(source, function)] = &source.Functions[i]
+		}
+	}
+	destFunctions := map[string]*catalog.Function{}
+	for i, function := range dest.Functions {
+		if keep(function.Schema) {
+			destFunctions[functionName(dest, function)] = &dest.Functions[i]
+		}
+	}
+	for _, name := range sortedKeys(sourceFunctions, destFunctions) {
+		sourceFunction, destFunction := sourceFunctions[name], destFunctions[name]
+		change := Change{Object: "function", Name: name, source: sourceFunction, dest: destFunction}
+		switch {
+		case destFunction == nil:
+			change.Action = Create
+		case sourceFunction == nil:
+			change.Action = Drop
+		case normalizeRoutine(sourceFunction.Definition) != normalizeRoutine(destFunction.Definition):
+			change.Action = Alter
+			change.Details = []string{"definition differs"}
+		default:
+			continue
+		}
+		if sourceFunction != nil && sourceFunction.Kind == "procedure" || destFunction != nil && destFunction.Kind == "procedure" {
+			change.Object = "procedure"
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// compareTables compares two tables of the same name
+func compareTables(source, dest *catalog.Catalog, sourceTable, destTable *catalog.Table) []Change {
+	changes := []Change{}
+
+	sourceColumns := map[string]*catalog.Column{}
+	for i, column := range sourceTable.Columns {
+		sourceColumns[column.Name] = &sourceTable.Columns[i]
+	}
+	destColumns := map[string]*catalog.Column{}
+	for i, column := range destTable.Columns {
+		destColumns[column.Name] = &destTable.Columns[i]
+	}
+	// Columns keep the order of the source table, then dropped ones follow
+	for _, column := range sourceTable.Columns {
+		sourceColumn, destColumn := sourceColumns[column.Name], destColumns[column.Name]
+		if destColumn == nil {
+			changes = append(changes, Change{Action: Create, Object: "column", Name: column.Name,
+				Details: []string{describeColumn(sourceColumn)}, source: sourceColumn, table: destTable})
+			continue
+		}
+		if details := compareColumns(sourceColumn, destColumn); len(details) > 0 {
+			changes = append(changes, Change{Action: Alter, Object: "column", Name: column.Name,
+				Details: details, source: sourceColumn, dest: destColumn, table: destTable})
+		}
+	}
+	for _, column := range destTable.Columns {
+		if sourceColumns[column.Name] == nil {
+			changes = append(changes, Change{Action: Drop, Object: "column", Name: column.Name, dest: destColumns[column.Name], table: destTable})
+		}
+	}
+
+	if !equalNames(sourceTable.PrimaryKey, destTable.PrimaryKey) {
+		change := Change{Action: Alter, Object: "primary key", Name: "(" + strings.Join(sourceTable.PrimaryKey, ", ") + ")",
+			source: sourceTable, dest: destTable, table: destTable}
+		switch {
+		case len(destTable.PrimaryKey) == 0:
+			change.Action = Create
+		case len(sourceTable.PrimaryKey) == 0:
+			change.Action = Drop
+			change.Name = "(" + strings.Join(destTable.PrimaryKey, ", ") + ")"
+		default:
+			change.Details = []string{"columns (" + strings.Join(destTable.PrimaryKey, ", ") + ") -> (" + strings.Join(sourceTable.PrimaryKey, ", ") + ")"}
+		}
+		changes = append(changes, change)
+	}
+
+	sourceIndexes, destIndexes := tableIndexes(sourceTable), tableIndexes(destTable)
+	for _, name := range sortedKeys(sourceIndexes, destIndexes) {
+		sourceIndex, destIndex := sourceIndexes[name], destIndexes[name]
+		change := Change{Object: "index", Name: name, source: sourceIndex, dest: destIndex, table: destTable}
+		switch {
+		case destIndex == nil:
+			change.Action = Create
+		case sourceIndex == nil:
+			change.Action = Drop
+		case sourceIndex.Unique != destIndex.Unique || !equalNames(sourceIndex.Columns, destIndex.Columns):
+			change.Action = Alter
+			change.Details = []string{describeIndex(destIndex) + " -> " + describeIndex(sourceIndex)}
+		case normalizeIndex(source, sourceTable, sourceIndex) != normalizeIndex(dest, destTable, destIndex):
+			change.Action = Alter
+			change.Details = []string{"definition differs"}
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	sourceConstraints, destConstraints := map[string]*catalog.Constraint{}, map[string]*catalog.Constraint{}
+	for i, constraint := range sourceTable.Constraints {
+		sourceConstraints[constraint.Name] = &sourceTable.Constraints[i]
+	}
+	for i, constraint := range destTable.Constraints {
+		destConstraints[constraint.Name] = &destTable.Constraints[i]
+	}
+	for _, name := range sortedKeys(sourceConstraints, destConstraints) {
+		sourceConstraint, destConstraint := sourceConstraints[name], destConstraints[name]
+		change := Change{Object: "constraint", Name: name, source: sourceConstraint, dest: destConstraint, table: destTable}
+		switch {
+		case destConstraint == nil:
+			change.Action = Create
+			change.Details = []string{sourceConstraint.Definition}
+		case sourceConstraint == nil:
+			change.Action = Drop
+		case normalize(sourceConstraint.Definition) != normalize(destConstraint.Definition):
+			change.Action = Alter
+			change.Details = []string{destConstraint.Definition + " -> " + sourceConstraint.Definition}
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	sourceKeys, destKeys := foreignKeys(source, sourceTable), foreignKeys(dest, destTable)
+	for _, name := range sortedKeys(sourceKeys, destKeys) {
+		sourceKey, destKey := sourceKeys[name], destKeys[name]
+		change := Change{Object: "foreign key", Name: name, source: sourceKey, dest: destKey, table: destTable}
+		switch {
+		case destKey == nil:
+			change.Action = Create
+			change.Details = []string{describeForeignKey(source, sourceKey)}
+		case sourceKey == nil:
+			change.Action = Drop
+		case describeForeignKey(source, sourceKey) != describeForeignKey(dest, destKey):
+			change.Action = Alter
+			change.Details = []string{describeForeignKey(dest, destKey) + " -> " + describeForeignKey(source, sourceKey)}
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// compareColumns describes how dest differs from source
+func compareColumns(source, dest *catalog.Column) []string {
+	details := []string{}
+	if !strings.EqualFold(source.Type, dest.Type) {
+		details = append(details, "type "+dest.Type+" -> "+source.Type)
+	}
+	if source.Nullable != dest.Nullable {
+		details = append(details, nullability(dest.Nullable)+" -> "+nullability(source.Nullable))
+	}
+	if defaultText(source.Default) != defaultText(dest.Default) {
+		details = append(details, "default "+defaultText(dest.Default)+" -> "+defaultText(source.Default))
+	}
+	if source.Identity != dest.Identity {
+		details = append(details, "identity "+fmt.Sprint(dest.Identity)+" -> "+fmt.Sprint(source.Identity))
+	}
+	return details
+}
+
+func describeColumn(column *catalog.Column) string {
+	description := column.Type + " " + nullability(column.Nullable)
+	if column.Default != nil {
+		description += " default " + *column.Default
+	}
+	return description
+}
+
+func nullability(nullable bool) string {
+	if nullable {
+		return "null"
+	}
+	return "not null"
+}
+
+func defaultText(value *string) string {
+	if value == nil {
+		return "none"
+	}
+	return *value
+}
+
+// tableIndexes returns the indexes of table by name, leaving out those backing
+// its primary key and unique constraints, which are compared as such
+func tableIndexes(table *catalog.Table) map[string]*catalog.Index {
+	constraints := map[string]bool{}
+	for _, constraint := range table.Constraints {
+		constraints[constraint.Name] = true
+	}
+
+	indexes := map[string]*catalog.Index{}
+	for i, index := range table.Indexes {
+		// SQLite names the indexes of UNIQUE clauses itself
+		if index.Primary || constraints[index.Name] || strings.HasPrefix(index.Name, "sqlite_autoindex_") {
+			continue
+		}
+		indexes[index.Name] = &table.Indexes[i]
+	}
+	return indexes
+}
+
+func describeIndex(index *catalog.Index) string {
+	description := "(" + strings.Join(index.Columns, ", ") + ")"
+	if index.Unique {
+		description = "unique " + description
+	}
+	return description
+}
+
+// normalizeIndex returns the definition of an index without the name of its
+// schema, which may differ between the databases
+func normalizeIndex(c *catalog.Catalog, table *catalog.Table, index *catalog.Index) string {
+	definition := normalize(index.Definition)
+	if c.Type == config.TypePostgreSQL {
+		definition = strings.Replace(definition, " ON "+table.Schema+".", " ON ", 1)
+	}
+	return definition
+}
+
+// viewQuery returns the query of a view. MySQL qualifies the tables of views
+// with the name of their database, which differs between servers, so it is
+// left out.
+func viewQuery(dbType string, view *catalog.View) string {
+	definition := view.Definition
+	if dbType == config.TypeMySQL {
+		definition = strings.ReplaceAll(definition, query.QuoteName(dbType, view.Schema)+".", "")
+	}
+	return strings.TrimSuffix(strings.TrimSpace(definition), ";")
+}
+
+// foreignKeys returns the foreign keys of table by name. SQLite foreign keys
+// have no name and are known by what they reference.
+func foreignKeys(c *catalog.Catalog, table *catalog.Table) map[string]*catalog.ForeignKey {
+	keys := map[string]*catalog.ForeignKey{}
+	for i, key := range table.ForeignKeys {
+		name := key.Name
+		if name == "" {
+			name = "(" + strings.Join(key.Columns, ", ") + ") -> " + c.QualifiedName(key.ReferencedSchema, key.ReferencedTable)
+		}
+		keys[name] = &table.ForeignKeys[i]
+	}
+	return keys
+}
+
+func describeForeignKey(c *catalog.Catalog, key *catalog.ForeignKey) string {
+	description := fmt.Sprintf("(%s) -> %s (%s)", strings.Join(key.Columns, ", "),
+		c.QualifiedName(key.ReferencedSchema, key.ReferencedTable), strings.Join(key.ReferencedColumns, ", "))
+	if key.OnUpdate != "" && key.OnUpdate != "NO ACTION" {
+		description += " on update " + key.OnUpdate
+	}
+	if key.OnDelete != "" && key.OnDelete != "NO ACTION" {
+		description += " on delete " + key.OnDelete
+	}
+	return description
+}
+
+// functionName names a function with its arguments, which tell apart the
+// overloads of PostgreSQL
+func functionName(c *catalog.Catalog, function catalog.Function) string {
+	name := c.QualifiedName(function.Schema, function.Name)
+	if c.Type == config.TypePostgreSQL {
+		name += "(" + function.Arguments + ")"
+	}
+	return name
+}
+
+// compareNames compares two lists of names of the given object
+func compareNames(object string, source, dest []string) []Change {
+	sourceNames, destNames := map[string]bool{}, map[string]bool{}
+	for _, name := range source {
+		sourceNames[name] = true
+	}
+	for _, name := range dest {
+		destNames[name] = true
+	}
+
+	changes := []Change{}
+	for _, name := range sortedKeys(sourceNames, destNames) {
+		switch {
+		case !destNames[name]:
+			changes = append(changes, Change{Action: Create, Object: object, Name: name})
+		case !sourceNames[name]:
+			changes = append(changes, Change{Action: Drop, Object: object, Name: name})
+		}
+	}
+	return changes
+}
+
+// sortedKeys returns the keys of both maps, sorted
+func sortedKeys[V any](a, b map[string]V) []string {
+	keys := []string{}
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func filter(names []string, keep func(string) bool) []string {
+	kept := []string{}
+	for _, name := range names {
+		if keep(name) {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+var whitespacePattern = regexp.MustCompile(`\s+`)
+
+// normalize drops the differences in whitespace and trailing semicolons the
+// catalogs keep from how statements were written
+func normalize(definition string) string {
+	definition = whitespacePattern.ReplaceAllString(strings.TrimSpace(definition), " ")
+	return strings.TrimSpace(strings.TrimSuffix(definition, ";"))
+}
+
+// definerPattern matches the DEFINER clause of MySQL routines, whose account
+// differs between servers
+var definerPattern = regexp.MustCompile("DEFINER=(`[^`]*`|[^@\\s]*)@(`[^`]*`|\\S*)\\s+")
+
+func normalizeRoutine(definition string) string {
+	return normalize(definerPattern.ReplaceAllString(definition, ""))
+}
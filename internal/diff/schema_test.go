@@ -0,0 +1,184 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"dbear/internal/catalog"
+	"dbear/internal/config"
+)
+
+func text(value string) *string {
+	return &value
+}
+
+// testCatalogs returns the PostgreSQL catalogs of a source database and of a
+// destination lagging behind it
+func testCatalogs() (*catalog.Catalog, *catalog.Catalog) {
+	serial := text("nextval('users_id_seq'::regclass)")
+	source := &catalog.Catalog{
+		Type:    config.TypePostgreSQL,
+		Schemas: []string{"audit", "public"},
+		Tables: []catalog.Table{
+			{
+				Schema: "public", Name: "orders",
+				Columns: []catalog.Column{
+					{Name: "id", Type: "bigint", Identity: true},
+					{Name: "user_id", Type: "integer"},
+				},
+				PrimaryKey: []string{"id"},
+				Indexes:    []catalog.Index{{Name: "orders_pkey", Columns: []string{"id"}, Unique: true, Primary: true}},
+				ForeignKeys: []catalog.ForeignKey{{
+					Name: "orders_user_id_fkey", Columns: []string{"user_id"},
+					ReferencedSchema: "public", ReferencedTable: "users", ReferencedColumns: []string{"id"}, OnDelete: "CASCADE",
+				}},
+			},
+			{
+				Schema: "public", Name: "users",
+				Columns: []catalog.Column{
+					{Name: "id", Type: "integer", Default: serial},
+					{Name: "email", Type: "text"},
+					{Name: "name", Type: "character varying(50)", Nullable: true, Default: text("'anonymous'::character varying")},
+				},
+				PrimaryKey: []string{"id"},
+				Indexes: []catalog.Index{
+					{Name: "users_pkey", Columns: []string{"id"}, Unique: true, Primary: true},
+					{Name: "users_email_key", Columns: []string{"email"}, Unique: true},
+					{Name: "users_name_idx", Columns: []string{"name"}, Definition: "CREATE INDEX users_name_idx ON public.users USING btree (name)"},
+				},
+				Constraints: []catalog.Constraint{{Name: "users_email_key", Type: "UNIQUE", Definition: "UNIQUE (email)"}},
+			},
+		},
+		Views: []catalog.View{{Schema: "public", Name: "active_users", Definition: " SELECT users.id\n   FROM users;"}},
+		Functions: []catalog.Function{{
+			Schema: "public", Name: "touch", Kind: "function",
+			Definition: "CREATE OR REPLACE FUNCTION public.touch()\n RETURNS trigger\n LANGUAGE plpgsql\nAS $$BEGIN NEW.updated = now(); RETURN NEW; END$$",
+		}},
+	}
+
+	dest := &catalog.Catalog{
+		Type:    config.TypePostgreSQL,
+		Schemas: []string{"old", "public"},
+		Tables: []catalog.Table{
+			{
+				Schema: "public", Name: "stale",
+				Columns:    []catalog.Column{{Name: "id", Type: "integer"}},
+				PrimaryKey: []string{"id"},
+			},
+			{
+				Schema: "public", Name: "users",
+				Columns: []catalog.Column{
+					{Name: "id", Type: "integer", Default: serial},
+					{Name: "email", Type: "character varying(100)", Nullable: true},
+					{Name: "legacy", Type: "text", Nullable: true},
+				},
+				PrimaryKey: []string{"id"},
+				Indexes: []catalog.Index{
+					{Name: "users_pkey", Columns: []string{"id"}, Unique: true, Primary: true},
+					{Name: "users_name_idx", Columns: []string{"email"}, Definition: "CREATE INDEX users_name_idx ON public.users USING btree (email)"},
+				},
+			},
+		},
+		Views: []catalog.View{{Schema: "public", Name: "active_users", Definition: "SELECT users.id FROM users"}},
+		Functions: []catalog.Function{{
+			Schema: "public", Name: "touch", Kind: "function",
+			Definition: "CREATE OR REPLACE FUNCTION public.touch()\n RETURNS trigger\n LANGUAGE plpgsql\nAS $$BEGIN NEW.updated = now(); RETURN NEW; END$$\n",
+		}},
+	}
+	return source, dest
+}
+
+// describeChanges flattens changes into one line per change
+func describeChanges(changes []Change, prefix string) []string {
+	lines := []string{}
+	for _, change := range changes {
+		line := fmt.Sprintf("%s%s %s %s", prefix, change.Action, change.Object, change.Name)
+		if len(change.Details) > 0 {
+			line += ": " + strings.Join(change.Details, "; ")
+		}
+		lines = append(lines, line)
+		lines = append(lines, describeChanges(change.Changes, prefix+"  ")...)
+	}
+	return lines
+}
+
+func TestSchemas(t *testing.T) {
+	source, dest := testCatalogs()
+
+	changes, err := Schemas(source, dest, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"create schema audit",
+		"drop schema old",
+		"create table public.orders",
+		"drop table public.stale",
+		"alter table public.users",
+		"  alter column email: type character varying(100) -> text; null -> not null",
+		"  create column name: character varying(50) null default 'anonymous'::character varying",
+		"  drop column legacy",
+		"  alter index users_name_idx: (email) -> (name)",
+		"  create constraint users_email_key: UNIQUE (email)",
+	}
+	if lines := describeChanges(changes, ""); !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("got changes:\n%s\nexpected:\n%s", strings.Join(lines, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestSchemasFiltersPostgreSQLSchemas(t *testing.T) {
+	source, dest := testCatalogs()
+
+	changes, err := Schemas(source, dest, []string{"audit"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := describeChanges(changes, ""); !reflect.DeepEqual(lines, []string{"create schema audit"}) {
+		t.Fatalf("got changes %v, expected only the audit schema", lines)
+	}
+
+	mysql := &catalog.Catalog{Type: config.TypeMySQL}
+	if _, err := Schemas(source, mysql, nil); err == nil {
+		t.Fatal("expected an error comparing databases of different types")
+	}
+}
+
+func TestSchemasOfIdenticalCatalogs(t *testing.T) {
+	source, _ := testCatalogs()
+	same, _ := testCatalogs()
+
+	changes, err := Schemas(source, same, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes, got %v", describeChanges(changes, ""))
+	}
+}
+
+func TestNormalizeRoutine(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		equal bool
+	}{
+		{"CREATE DEFINER=`root`@`%` FUNCTION f() RETURNS int RETURN 1", "CREATE DEFINER=`app`@`localhost` FUNCTION f() RETURNS int RETURN 1", true},
+		{"CREATE DEFINER=root@localhost PROCEDURE p()\nBEGIN\n  SELECT 1;\nEND", "CREATE PROCEDURE p() BEGIN SELECT 1; END;", true},
+		{"CREATE FUNCTION f() RETURNS int RETURN 1", "CREATE FUNCTION f() RETURNS int RETURN 2", false},
+	}
+
+	for _, test := range tests {
+		if equal := normalizeRoutine(test.a) == normalizeRoutine(test.b); equal != test.equal {
+			t.Errorf("%q and %q: got equal %t, expected %t", test.a, test.b, equal, test.equal)
+		}
+	}
+}
+
+func TestViewQueryLeavesOutTheMySQLDatabase(t *testing.T) {
+	view := &catalog.View{Schema: "shop", Name: "big", Definition: "select `shop`.`orders`.`id` AS `id` from `shop`.`orders`"}
+	if query := viewQuery(config.TypeMySQL, view); query != "select `orders`.`id` AS `id` from `orders`" {
+		t.Fatalf("got %s", query)
+	}
+}
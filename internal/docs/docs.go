@@ -0,0 +1,390 @@
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"dbear/internal/catalog"
+	"dbear/internal/config"
+	"dbear/internal/ui"
+)
+
+// Documentation formats
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// Formats lists the formats documentation is written in
+var Formats = []string{FormatMarkdown, FormatHTML}
+
+// IsValidFormat tells whether format is one of Formats
+func IsValidFormat(format string) bool {
+	return format == FormatMarkdown || format == FormatHTML
+}
+
+// marker is in every generated page, telling them apart from files written by
+// hand when pages of dropped tables are removed
+const marker = "Generated by dbear docs"
+
+// Options tells Generate what to write
+type Options struct {
+	Format string
+	// Title heads the index page
+	Title string
+	// Stats adds the row estimates and sizes of tables, which change with
+	// the data rather than the schema
+	Stats bool
+}
+
+// Result lists the files Generate wrote and removed
+type Result struct {
+	Written []string
+	Removed []string
+}
+
+// reference is a foreign key as seen from one of the tables it links
+type reference struct {
+	table string
+	key   *catalog.ForeignKey
+}
+
+// generator writes the pages of a catalog
+type generator struct {
+	catalog  *catalog.Catalog
+	options  Options
+	index    string
+	files    map[string]string
+	incoming map[string][]reference
+}
+
+// Generate writes one page per table of c into dir, with an index page
+// linking them, and removes the pages it wrote before for tables that are
+// gone
+func Generate(c *catalog.Catalog, dir string, options Options) (Result, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Result{}, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	g := &generator{catalog: c, options: options, files: map[string]string{}, incoming: map[string][]reference{}}
+	extension := ".md"
+	// README.md is shown when browsing the directory on code hosts
+	g.index = "README.md"
+	if options.Format == FormatHTML {
+		extension = ".html"
+		g.index = "index.html"
+	}
+	// Names that differ only by unsafe characters or case, as on
+	// case-insensitive file systems, get numbered pages, and no table takes
+	// the index page
+	taken := map[string]bool{strings.ToLower(g.index): true}
+	for _, table := range c.Tables {
+		name := c.QualifiedName(table.Schema, table.Name)
+		base := pageName(name)
+		file := base + extension
+		for i := 2; taken[strings.ToLower(file)]; i++ {
+			file = fmt.Sprintf("%s-%d%s", base, i, extension)
+		}
+		taken[strings.ToLower(file)] = true
+		g.files[name] = file
+	}
+	for i := range c.Tables {
+		table := &c.Tables[i]
+		for j := range table.ForeignKeys {
+			key := &table.ForeignKeys[j]
+			referenced := c.QualifiedName(key.ReferencedSchema, key.ReferencedTable)
+			g.incoming[referenced] = append(g.incoming[referenced], reference{table: c.QualifiedName(table.Schema, table.Name), key: key})
+		}
+	}
+
+	result := Result{}
+	write := func(file string, d document) error {
+		if err := os.WriteFile(filepath.Join(dir, file), d.bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+		result.Written = append(result.Written, file)
+		return nil
+	}
+	for i := range c.Tables {
+		table := &c.Tables[i]
+		if err := write(g.files[c.QualifiedName(table.Schema, table.Name)], g.tablePage(table)); err != nil {
+			return result, err
+		}
+	}
+	if err := write(g.index, g.indexPage()); err != nil {
+		return result, err
+	}
+
+	removed, err := removeStale(dir, extension, result.Written)
+	result.Removed = removed
+	return result, err
+}
+
+// unsafeFileCharacters matches what is left out of the names of pages
+var unsafeFileCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+func pageName(table string) string {
+	return unsafeFileCharacters.ReplaceAllString(table, "_")
+}
+
+func (g *generator) newDocument(title string) document {
+	if g.options.Format == FormatHTML {
+		return newHTMLDocument(title)
+	}
+	return newMarkdownDocument()
+}
+
+func (g *generator) indexPage() document {
+	c := g.catalog
+	d := g.newDocument(g.options.Title)
+	d.heading(1, d.text(g.options.Title))
+	schemas := "the " + strings.Join(c.Schemas, "") + " schema"
+	if len(c.Schemas) != 1 {
+		schemas = "the schemas " + strings.Join(c.Schemas, ", ")
+	}
+	d.paragraph(d.text(fmt.Sprintf("%s in %s.", pluralize(len(c.Tables), "table"), schemas)))
+
+	if len(c.Tables) > 0 {
+		d.heading(2, "Tables")
+		header := []string{"Table", "Columns"}
+		if g.options.Stats {
+			header = append(header, "Rows", "Size")
+		}
+		header = append(header, "Comment")
+		rows := [][]string{}
+		for _, table := range c.Tables {
+			name := c.QualifiedName(table.Schema, table.Name)
+			row := []string{d.link(name, g.files[name]), fmt.Sprint(len(table.Columns))}
+			if g.options.Stats {
+				row = append(row, d.text(g.rowEstimate(table.RowEstimate)), d.text(size(table.Size)))
+			}
+			rows = append(rows, append(row, d.text(table.Comment)))
+		}
+		d.table(header, rows)
+	}
+
+	if len(c.Views) > 0 {
+		d.heading(2, "Views")
+		rows := [][]string{}
+		for _, view := range c.Views {
+			kind := "view"
+			if view.Materialized {
+				kind = "materialized view"
+			}
+			columns := make([]string, len(view.Columns))
+			for i, column := range view.Columns {
+				columns[i] = column.Name
+			}
+			rows = append(rows, []string{d.code(c.QualifiedName(view.Schema, view.Name)), kind, d.text(strings.Join(columns, ", "))})
+		}
+		d.table([]string{"View", "Kind", "Columns"}, rows)
+	}
+	return d
+}
+
+func (g *generator) tablePage(table *catalog.Table) document {
+	c := g.catalog
+	name := c.QualifiedName(table.Schema, table.Name)
+	d := g.newDocument(name)
+	d.paragraph(d.link(g.options.Title, g.index))
+	d.heading(1, d.text(name))
+	if table.Comment != "" {
+		d.paragraph(d.text(table.Comment))
+	}
+	if g.options.Stats {
+		d.paragraph(d.text(g.rowEstimate(table.RowEstimate) + ", " + size(table.Size) + "."))
+	}
+
+	d.heading(2, "Columns")
+	rows := [][]string{}
+	for _, column := range table.Columns {
+		nullable := "no"
+		if column.Nullable {
+			nullable = "yes"
+		}
+		defaultValue := ""
+		if column.Default != nil {
+			defaultValue = d.code(*column.Default)
+		}
+		keys := []string{}
+		if contains(table.PrimaryKey, column.Name) {
+			keys = append(keys, "PK")
+		}
+		for _, key := range table.ForeignKeys {
+			if contains(key.Columns, column.Name) {
+				keys = append(keys, "FK")
+				break
+			}
+		}
+		if column.Identity {
+			keys = append(keys, "identity")
+		}
+		rows = append(rows, []string{d.code(column.Name), d.text(column.Type), nullable, defaultValue,
+			strings.Join(keys, ", "), d.text(column.Comment)})
+	}
+	d.table([]string{"Column", "Type", "Nullable", "Default", "Key", "Comment"}, rows)
+
+	constraints := []string{}
+	if len(table.PrimaryKey) > 0 {
+		constraints = append(constraints, "Primary key "+d.code("("+strings.Join(table.PrimaryKey, ", ")+")"))
+	}
+	for _, constraint := range table.Constraints {
+		constraints = append(constraints, d.code(constraint.Name)+": "+d.code(constraint.Definition))
+	}
+	if len(constraints) > 0 {
+		d.heading(2, "Constraints")
+		d.list(constraints)
+	}
+
+	if len(table.Indexes) > 0 {
+		d.heading(2, "Indexes")
+		rows := [][]string{}
+		for _, index := range table.Indexes {
+			unique := "no"
+			if index.Unique {
+				unique = "yes"
+			}
+			rows = append(rows, []string{d.code(index.Name), d.text(strings.Join(index.Columns, ", ")), unique})
+		}
+		d.table([]string{"Index", "Columns", "Unique"}, rows)
+	}
+
+	if len(table.ForeignKeys) > 0 {
+		d.heading(2, "References")
+		items := []string{}
+		for i := range table.ForeignKeys {
+			key := &table.ForeignKeys[i]
+			referenced := c.QualifiedName(key.ReferencedSchema, key.ReferencedTable)
+			item := d.code("("+strings.Join(key.Columns, ", ")+")") + " → " + g.tableLink(d, referenced) + " " +
+				d.code("("+strings.Join(key.ReferencedColumns, ", ")+")")
+			items = append(items, item+d.text(keyActions(key)))
+		}
+		d.list(items)
+	}
+
+	if incoming := g.incoming[name]; len(incoming) > 0 {
+		d.heading(2, "Referenced by")
+		items := []string{}
+		for _, ref := range incoming {
+			item := g.tableLink(d, ref.table) + " " + d.code("("+strings.Join(ref.key.Columns, ", ")+")") + " → " +
+				d.code("("+strings.Join(ref.key.ReferencedColumns, ", ")+")")
+			items = append(items, item+d.text(keyActions(ref.key)))
+		}
+		d.list(items)
+	}
+	return d
+}
+
+// tableLink links to the page of a table, which tables of schemas left out
+// have none of
+func (g *generator) tableLink(d document, table string) string {
+	if file, ok := g.files[table]; ok {
+		return d.link(table, file)
+	}
+	return d.code(table)
+}
+
+func keyActions(key *catalog.ForeignKey) string {
+	actions := ""
+	if key.OnUpdate != "" && key.OnUpdate != "NO ACTION" {
+		actions += " on update " + key.OnUpdate
+	}
+	if key.OnDelete != "" && key.OnDelete != "NO ACTION" {
+		actions += " on delete " + key.OnDelete
+	}
+	return actions
+}
+
+// rowEstimate renders the row count of a table, which is only exact on
+// SQLite
+func (g *generator) rowEstimate(estimate int64) string {
+	about := "About "
+	if g.catalog.Type == config.TypeSQLite {
+		about = ""
+	}
+	switch {
+	case estimate < 0:
+		return "Rows unknown"
+	case estimate == 1:
+		return about + "1 row"
+	default:
+		return fmt.Sprintf("%s%d rows", about, estimate)
+	}
+}
+
+func size(value int64) string {
+	if value < 0 {
+		return "size unknown"
+	}
+	return ui.FormatSize(value)
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// removeStale removes the pages with extension in dir that dbear generated
+// but did not write this time, such as those of dropped tables
+func removeStale(dir, extension string, written []string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	kept := map[string]bool{}
+	for _, file := range written {
+		kept[file] = true
+	}
+
+	removed := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || kept[entry.Name()] || filepath.Ext(entry.Name()) != extension {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		generated, err := isGenerated(path)
+		if err != nil {
+			return removed, err
+		}
+		if !generated {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", entry.Name(), err)
+		}
+		removed = append(removed, entry.Name())
+	}
+	return removed, nil
+}
+
+// isGenerated tells whether the file at path starts like a generated page
+func isGenerated(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	head := make([]byte, 256)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, err
+	}
+	return bytes.Contains(head[:n], []byte(marker)), nil
+}
@@ -0,0 +1,270 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"dbear/internal/catalog"
+	"dbear/internal/config"
+)
+
+func text(value string) *string {
+	return &value
+}
+
+// testCatalog returns a PostgreSQL catalog where public.orders references
+// public.users
+func testCatalog() *catalog.Catalog {
+	return &catalog.Catalog{
+		Type:    config.TypePostgreSQL,
+		Schemas: []string{"public"},
+		Tables: []catalog.Table{
+			{
+				Schema: "public", Name: "orders", RowEstimate: 10, Size: 8192,
+				Columns:    []catalog.Column{{Name: "id", Type: "bigint"}, {Name: "user_id", Type: "integer"}},
+				PrimaryKey: []string{"id"},
+				Indexes:    []catalog.Index{{Name: "orders_user_idx", Columns: []string{"user_id"}}},
+				ForeignKeys: []catalog.ForeignKey{{
+					Name: "orders_user_fkey", Columns: []string{"user_id"},
+					ReferencedSchema: "public", ReferencedTable: "users", ReferencedColumns: []string{"id"},
+					OnUpdate: "NO ACTION", OnDelete: "CASCADE",
+				}},
+			},
+			{
+				Schema: "public", Name: "users", RowEstimate: 1, Size: 16384, Comment: "People *signed up*",
+				Columns: []catalog.Column{
+					{Name: "id", Type: "integer", Identity: true},
+					{Name: "e-mail", Type: "text", Nullable: true, Default: text("'x'::text"), Comment: "Where | to write"},
+				},
+				PrimaryKey:  []string{"id"},
+				Constraints: []catalog.Constraint{{Name: "users_email_check", Type: "CHECK", Definition: "CHECK (\"e-mail\" <> '')"}},
+			},
+		},
+		Views: []catalog.View{{Schema: "public", Name: "active", Columns: []catalog.Column{{Name: "id"}, {Name: "e-mail"}}}},
+	}
+}
+
+func TestGenerateMarkdown(t *testing.T) {
+	dir := t.TempDir()
+	result, err := Generate(testCatalog(), dir, Options{Format: FormatMarkdown, Title: "Shop", Stats: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"public.orders.md", "public.users.md", "README.md"}; !reflect.DeepEqual(result.Written, expected) {
+		t.Fatalf("got written %v, expected %v", result.Written, expected)
+	}
+
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{"README.md", `<!-- Generated by dbear docs: edit the database, then run it again -->
+
+# Shop
+
+2 tables in the public schema.
+
+## Tables
+
+| Table | Columns | Rows | Size | Comment |
+| --- | --- | --- | --- | --- |
+| [public.orders](public.orders.md) | 2 | About 10 rows | 8.0 KiB |  |
+| [public.users](public.users.md) | 2 | About 1 row | 16.0 KiB | People \*signed up\* |
+
+## Views
+
+| View | Kind | Columns |
+| --- | --- | --- |
+| ` + "`public.active`" + ` | view | id, e-mail |
+`},
+		{"public.users.md", `<!-- Generated by dbear docs: edit the database, then run it again -->
+
+[Shop](README.md)
+
+# public.users
+
+People \*signed up\*
+
+About 1 row, 16.0 KiB.
+
+## Columns
+
+| Column | Type | Nullable | Default | Key | Comment |
+| --- | --- | --- | --- | --- | --- |
+| ` + "`id`" + ` | integer | no |  | PK, identity |  |
+| ` + "`e-mail`" + ` | text | yes | ` + "`'x'::text`" + ` |  | Where \| to write |
+
+## Constraints
+
+- Primary key ` + "`(id)`" + `
+- ` + "`users_email_check`: `CHECK (\"e-mail\" <> '')`" + `
+
+## Referenced by
+
+- [public.orders](public.orders.md) ` + "`(user_id)` → `(id)`" + ` on delete CASCADE
+`},
+		{"public.orders.md", `<!-- Generated by dbear docs: edit the database, then run it again -->
+
+[Shop](README.md)
+
+# public.orders
+
+About 10 rows, 8.0 KiB.
+
+## Columns
+
+| Column | Type | Nullable | Default | Key | Comment |
+| --- | --- | --- | --- | --- | --- |
+| ` + "`id`" + ` | bigint | no |  | PK |  |
+| ` + "`user_id`" + ` | integer | no |  | FK |  |
+
+## Constraints
+
+- Primary key ` + "`(id)`" + `
+
+## Indexes
+
+| Index | Columns | Unique |
+| --- | --- | --- |
+| ` + "`orders_user_idx`" + ` | user\_id | no |
+
+## References
+
+- ` + "`(user_id)` → [public.users](public.users.md) `(id)`" + ` on delete CASCADE
+`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join(dir, test.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != test.expected {
+				t.Fatalf("got:\n%s\nexpected:\n%s", content, test.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateHTML(t *testing.T) {
+	dir := t.TempDir()
+	c := testCatalog()
+	c.Tables = c.Tables[1:]
+	if _, err := Generate(c, dir, Options{Format: FormatHTML, Title: "Shop & co"}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "public.users.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "<!DOCTYPE html>\n<!-- Generated by dbear docs: edit the database, then run it again -->\n" +
+		"<html>\n<head>\n<meta charset=\"utf-8\">\n<title>public.users</title>\n<style>\n" + htmlStyle + "\n</style>\n</head>\n<body>\n" +
+		`<p><a href="index.html">Shop &amp; co</a></p>` + "\n" +
+		"<h1>public.users</h1>\n" +
+		"<p>People *signed up*</p>\n" +
+		"<h2>Columns</h2>\n" +
+		"<table>\n<tr><th>Column</th><th>Type</th><th>Nullable</th><th>Default</th><th>Key</th><th>Comment</th></tr>\n" +
+		"<tr><td><code>id</code></td><td>integer</td><td>no</td><td></td><td>PK, identity</td><td></td></tr>\n" +
+		"<tr><td><code>e-mail</code></td><td>text</td><td>yes</td><td><code>&#39;x&#39;::text</code></td><td></td><td>Where | to write</td></tr>\n" +
+		"</table>\n" +
+		"<h2>Constraints</h2>\n<ul>\n<li>Primary key <code>(id)</code></li>\n" +
+		"<li><code>users_email_check</code>: <code>CHECK (&#34;e-mail&#34; &lt;&gt; &#39;&#39;)</code></li>\n</ul>\n" +
+		"</body>\n</html>\n"
+	if string(content) != expected {
+		t.Fatalf("got:\n%s\nexpected:\n%s", content, expected)
+	}
+}
+
+func TestGenerateNamesPagesApart(t *testing.T) {
+	c := &catalog.Catalog{
+		Type:    config.TypeSQLite,
+		Schemas: []string{"main"},
+		Tables:  []catalog.Table{{Name: "order lines"}, {Name: "order_lines"}, {Name: "Order_Lines"}, {Name: "readme"}},
+	}
+
+	result, err := Generate(c, t.TempDir(), Options{Format: FormatMarkdown, Title: "Shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"order_lines.md", "order_lines-2.md", "Order_Lines-3.md", "readme-2.md", "README.md"}
+	if !reflect.DeepEqual(result.Written, expected) {
+		t.Fatalf("got written %v, expected %v", result.Written, expected)
+	}
+}
+
+func TestGenerateRemovesStalePages(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"public.gone.md": "<!-- " + marker + ": edit the database, then run it again -->\n\n# public.gone\n",
+		"notes.md":       "# Notes written by hand\n",
+		"gone.html":      "<!-- " + marker + " -->\n",
+	}
+	for file, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := Generate(testCatalog(), dir, Options{Format: FormatMarkdown, Title: "Shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Removed, []string{"public.gone.md"}) {
+		t.Fatalf("got removed %v, expected only public.gone.md", result.Removed)
+	}
+	for _, file := range []string{"notes.md", "gone.html"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("%s: %s", file, err)
+		}
+	}
+}
+
+func TestMarkdownInline(t *testing.T) {
+	d := newMarkdownDocument()
+	tests := []struct {
+		got      string
+		expected string
+	}{
+		{d.text("a_b *c* [d] <e> #f | g\nh"), `a\_b \*c\* \[d\] \<e\> \#f \| g h`},
+		{d.text(`C:\dir`), `C:\\dir`},
+		{d.code("a | b"), "`a \\| b`"},
+		{d.code("say `hi`"), "`` say `hi` ``"},
+		{d.code("x\r\ny"), "`x y`"},
+		{d.link("public.users", "public.users.md"), "[public.users](public.users.md)"},
+		{d.link("a b", "a b.md"), "[a b](a%20b.md)"},
+	}
+
+	for _, test := range tests {
+		if test.got != test.expected {
+			t.Errorf("got %q, expected %q", test.got, test.expected)
+		}
+	}
+}
+
+func TestRowEstimate(t *testing.T) {
+	tests := []struct {
+		dbType   string
+		estimate int64
+		expected string
+	}{
+		{config.TypePostgreSQL, -1, "Rows unknown"},
+		{config.TypePostgreSQL, 0, "About 0 rows"},
+		{config.TypeMySQL, 1, "About 1 row"},
+		{config.TypeSQLite, 1, "1 row"},
+		{config.TypeSQLite, 42, "42 rows"},
+	}
+
+	for _, test := range tests {
+		g := &generator{catalog: &catalog.Catalog{Type: test.dbType}}
+		if estimate := g.rowEstimate(test.estimate); estimate != test.expected {
+			t.Errorf("%s %d: got %q, expected %q", test.dbType, test.estimate, estimate, test.expected)
+		}
+	}
+	if s := size(-1); s != "size unknown" {
+		t.Errorf("got %q, expected %q", s, "size unknown")
+	}
+}
@@ -0,0 +1,156 @@
+package docs
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+// document builds a page in one of the formats. Headings, paragraphs, cells
+// and list items are inline markup made with text, code and link.
+type document interface {
+	heading(level int, inline string)
+	paragraph(inline string)
+	table(header []string, rows [][]string)
+	list(items []string)
+
+	// text escapes plain text, code marks it as code and link links to
+	// another page
+	text(s string) string
+	code(s string) string
+	link(s, target string) string
+
+	bytes() []byte
+}
+
+type markdownDocument struct {
+	b strings.Builder
+}
+
+func newMarkdownDocument() *markdownDocument {
+	d := &markdownDocument{}
+	d.b.WriteString("<!-- " + marker + ": edit the database, then run it again -->\n")
+	return d
+}
+
+func (d *markdownDocument) heading(level int, inline string) {
+	fmt.Fprintf(&d.b, "\n%s %s\n", strings.Repeat("#", level), inline)
+}
+
+func (d *markdownDocument) paragraph(inline string) {
+	fmt.Fprintf(&d.b, "\n%s\n", inline)
+}
+
+func (d *markdownDocument) table(header []string, rows [][]string) {
+	d.b.WriteString("\n| " + strings.Join(header, " | ") + " |\n")
+	d.b.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for _, row := range rows {
+		d.b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+}
+
+func (d *markdownDocument) list(items []string) {
+	d.b.WriteString("\n")
+	for _, item := range items {
+		d.b.WriteString("- " + item + "\n")
+	}
+}
+
+// markdownEscaper escapes what Markdown would read as markup, and pipes, which
+// end table cells
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`, "\r\n", " ", "\n", " ",
+)
+
+func (d *markdownDocument) text(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func (d *markdownDocument) code(s string) string {
+	s = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ").Replace(s)
+	// Code holding backticks is fenced with more of them
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+func (d *markdownDocument) link(s, target string) string {
+	return "[" + d.text(s) + "](" + (&url.URL{Path: target}).String() + ")"
+}
+
+func (d *markdownDocument) bytes() []byte {
+	return []byte(d.b.String())
+}
+
+type htmlDocument struct {
+	b strings.Builder
+}
+
+const htmlStyle = `body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 60em; padding: 0 1em; color: #24292f; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #d0d7de; padding: 0.3em 0.7em; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+code { background: #f6f8fa; padding: 0.1em 0.3em; border-radius: 4px; }`
+
+func newHTMLDocument(title string) *htmlDocument {
+	d := &htmlDocument{}
+	d.b.WriteString("<!DOCTYPE html>\n<!-- " + marker + ": edit the database, then run it again -->\n")
+	fmt.Fprintf(&d.b, "<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n",
+		html.EscapeString(title), htmlStyle)
+	return d
+}
+
+func (d *htmlDocument) heading(level int, inline string) {
+	fmt.Fprintf(&d.b, "<h%d>%s</h%d>\n", level, inline, level)
+}
+
+func (d *htmlDocument) paragraph(inline string) {
+	fmt.Fprintf(&d.b, "<p>%s</p>\n", inline)
+}
+
+func (d *htmlDocument) table(header []string, rows [][]string) {
+	d.b.WriteString("<table>\n<tr>")
+	for _, cell := range header {
+		d.b.WriteString("<th>" + cell + "</th>")
+	}
+	d.b.WriteString("</tr>\n")
+	for _, row := range rows {
+		d.b.WriteString("<tr>")
+		for _, cell := range row {
+			d.b.WriteString("<td>" + cell + "</td>")
+		}
+		d.b.WriteString("</tr>\n")
+	}
+	d.b.WriteString("</table>\n")
+}
+
+func (d *htmlDocument) list(items []string) {
+	d.b.WriteString("<ul>\n")
+	for _, item := range items {
+		d.b.WriteString("<li>" + item + "</li>\n")
+	}
+	d.b.WriteString("</ul>\n")
+}
+
+func (d *htmlDocument) text(s string) string {
+	return html.EscapeString(s)
+}
+
+func (d *htmlDocument) code(s string) string {
+	return "<code>" + html.EscapeString(s) + "</code>"
+}
+
+func (d *htmlDocument) link(s, target string) string {
+	return `<a href="` + html.EscapeString((&url.URL{Path: target}).String()) + `">` + html.EscapeString(s) + "</a>"
+}
+
+func (d *htmlDocument) bytes() []byte {
+	return []byte(d.b.String() + "</body>\n</html>\n")
+}
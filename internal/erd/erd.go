@@ -0,0 +1,232 @@
+package erd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"dbear/internal/catalog"
+)
+
+// Diagram formats
+const (
+	FormatMermaid  = "mermaid"
+	FormatDot      = "dot"
+	FormatPlantUML = "plantuml"
+	FormatSVG      = "svg"
+)
+
+// Formats lists the formats diagrams are written in
+var Formats = []string{FormatMermaid, FormatDot, FormatPlantUML, FormatSVG}
+
+// IsValidFormat tells whether format is one of Formats
+func IsValidFormat(format string) bool {
+	for _, known := range Formats {
+		if format == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Diagram is a set of tables of a catalog, linked by their foreign keys
+type Diagram struct {
+	catalog *catalog.Catalog
+	tables  []*catalog.Table
+	drawn   map[string]bool
+}
+
+// New returns a diagram of every table of c or, when focus is given, of the
+// table it names and those within depth foreign keys of it, whichever way the
+// keys point
+func New(c *catalog.Catalog, focus string, depth int) (*Diagram, error) {
+	d := &Diagram{catalog: c, drawn: map[string]bool{}}
+	if focus == "" {
+		for i := range c.Tables {
+			d.add(&c.Tables[i])
+		}
+		return d, nil
+	}
+
+	start, err := d.find(focus)
+	if err != nil {
+		return nil, err
+	}
+
+	// Foreign keys are followed from the referencing and the referenced side
+	neighbours := map[string][]string{}
+	for _, table := range c.Tables {
+		name := d.name(&table)
+		for _, key := range table.ForeignKeys {
+			referenced := c.QualifiedName(key.ReferencedSchema, key.ReferencedTable)
+			neighbours[name] = append(neighbours[name], referenced)
+			neighbours[referenced] = append(neighbours[referenced], name)
+		}
+	}
+
+	distances := map[string]int{d.name(start): 0}
+	queue := []string{d.name(start)}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if distances[name] == depth {
+			continue
+		}
+		for _, neighbour := range neighbours[name] {
+			if _, seen := distances[neighbour]; !seen {
+				distances[neighbour] = distances[name] + 1
+				queue = append(queue, neighbour)
+			}
+		}
+	}
+	for i := range c.Tables {
+		if _, ok := distances[d.name(&c.Tables[i])]; ok {
+			d.add(&c.Tables[i])
+		}
+	}
+	return d, nil
+}
+
+// find returns the table named name. On PostgreSQL, a name without a schema
+// is looked for in every schema.
+func (d *Diagram) find(name string) (*catalog.Table, error) {
+	matches := []*catalog.Table{}
+	for i := range d.catalog.Tables {
+		table := &d.catalog.Tables[i]
+		if d.name(table) == name {
+			return table, nil
+		}
+		if table.Name == name {
+			matches = append(matches, table)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("table '%s' not found", name)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, len(matches))
+		for i, table := range matches {
+			names[i] = d.name(table)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("table '%s' is in several schemas, give one of %s", name, strings.Join(names, ", "))
+	}
+}
+
+func (d *Diagram) add(table *catalog.Table) {
+	d.tables = append(d.tables, table)
+	d.drawn[d.name(table)] = true
+}
+
+func (d *Diagram) name(table *catalog.Table) string {
+	return d.catalog.QualifiedName(table.Schema, table.Name)
+}
+
+// Tables returns the number of tables in the diagram
+func (d *Diagram) Tables() int {
+	return len(d.tables)
+}
+
+// relation is a foreign key between two tables of the diagram
+type relation struct {
+	from, to string
+	key      *catalog.ForeignKey
+	// optional is set when the referencing columns may be NULL
+	optional bool
+}
+
+// relations returns the foreign keys of the diagram whose referenced table is
+// drawn too
+func (d *Diagram) relations() []relation {
+	relations := []relation{}
+	for _, table := range d.tables {
+		nullable := map[string]bool{}
+		for _, column := range table.Columns {
+			nullable[column.Name] = column.Nullable
+		}
+		for i := range table.ForeignKeys {
+			key := &table.ForeignKeys[i]
+			to := d.catalog.QualifiedName(key.ReferencedSchema, key.ReferencedTable)
+			if !d.drawn[to] {
+				continue
+			}
+			r := relation{from: d.name(table), to: to, key: key}
+			for _, column := range key.Columns {
+				r.optional = r.optional || nullable[column]
+			}
+			relations = append(relations, r)
+		}
+	}
+	return relations
+}
+
+// columnKeys returns PK, FK, both or neither for a column of table
+func columnKeys(table *catalog.Table, column string) []string {
+	keys := []string{}
+	for _, name := range table.PrimaryKey {
+		if name == column {
+			keys = append(keys, "PK")
+			break
+		}
+	}
+	for _, key := range table.ForeignKeys {
+		for _, name := range key.Columns {
+			if name == column {
+				return append(keys, "FK")
+			}
+		}
+	}
+	return keys
+}
+
+// relationLabel names a foreign key by its constraint, or by its columns when
+// it has no name as on SQLite
+func relationLabel(r relation) string {
+	if r.key.Name != "" {
+		return r.key.Name
+	}
+	return strings.Join(r.key.Columns, ", ")
+}
+
+// Write writes the diagram to w in format. SVG is rendered from the dot
+// format by Graphviz, which must be installed.
+func (d *Diagram) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatMermaid:
+		return d.writeMermaid(w)
+	case FormatDot:
+		return d.writeDot(w)
+	case FormatPlantUML:
+		return d.writePlantUML(w)
+	case FormatSVG:
+		return d.writeSVG(w)
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+func (d *Diagram) writeSVG(w io.Writer) error {
+	if _, err := exec.LookPath("dot"); err != nil {
+		return fmt.Errorf("the svg format needs the dot command of Graphviz, install it or write the dot format instead")
+	}
+
+	var source bytes.Buffer
+	if err := d.writeDot(&source); err != nil {
+		return err
+	}
+	cmd := exec.Command("dot", "-Tsvg")
+	var stderr bytes.Buffer
+	cmd.Stdin = &source
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to render svg: %w, stderr: %s", err, stderr.String())
+	}
+	return nil
+}
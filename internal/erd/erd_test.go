@@ -0,0 +1,170 @@
+package erd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"dbear/internal/catalog"
+	"dbear/internal/config"
+)
+
+// testCatalog returns a PostgreSQL catalog where sales.lines references
+// sales.orders, which references public.users through a nullable column
+func testCatalog() *catalog.Catalog {
+	return &catalog.Catalog{
+		Type: config.TypePostgreSQL,
+		Tables: []catalog.Table{
+			{Schema: "public", Name: "audit", Columns: []catalog.Column{{Name: "event", Type: "text"}}},
+			{
+				Schema: "public", Name: "users",
+				Columns:    []catalog.Column{{Name: "id", Type: "integer"}, {Name: "e-mail", Type: "character varying(255)", Nullable: true}},
+				PrimaryKey: []string{"id"},
+			},
+			{
+				Schema: "sales", Name: "lines",
+				Columns:    []catalog.Column{{Name: "order_id", Type: "integer"}, {Name: "number", Type: "integer"}},
+				PrimaryKey: []string{"order_id", "number"},
+				ForeignKeys: []catalog.ForeignKey{{
+					Name: "lines_order_fkey", Columns: []string{"order_id"},
+					ReferencedSchema: "sales", ReferencedTable: "orders", ReferencedColumns: []string{"id"},
+				}},
+			},
+			{
+				Schema: "sales", Name: "orders",
+				Columns:    []catalog.Column{{Name: "id", Type: "integer"}, {Name: "user_id", Type: "integer", Nullable: true}},
+				PrimaryKey: []string{"id"},
+				ForeignKeys: []catalog.ForeignKey{{
+					Name: "orders_user_fkey", Columns: []string{"user_id"},
+					ReferencedSchema: "public", ReferencedTable: "users", ReferencedColumns: []string{"id"},
+				}},
+			},
+			{Schema: "sales", Name: "users", Columns: []catalog.Column{{Name: "id", Type: "integer"}}},
+		},
+	}
+}
+
+func tableNames(d *Diagram) []string {
+	names := []string{}
+	for _, table := range d.tables {
+		names = append(names, d.name(table))
+	}
+	return names
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		focus    string
+		depth    int
+		expected []string
+		err      string
+	}{
+		{"", 1, []string{"public.audit", "public.users", "sales.lines", "sales.orders", "sales.users"}, ""},
+		{"sales.lines", 0, []string{"sales.lines"}, ""},
+		{"sales.lines", 1, []string{"sales.lines", "sales.orders"}, ""},
+		{"lines", 2, []string{"public.users", "sales.lines", "sales.orders"}, ""},
+		{"public.users", 1, []string{"public.users", "sales.orders"}, ""},
+		{"users", 1, nil, "give one of public.users, sales.users"},
+		{"nope", 1, nil, "not found"},
+	}
+
+	for _, test := range tests {
+		d, err := New(testCatalog(), test.focus, test.depth)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: got error %v, expected it to mention %q", test.focus, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.focus, err)
+			continue
+		}
+		if names := tableNames(d); !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("%s at depth %d: got tables %v, expected %v", test.focus, test.depth, names, test.expected)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{FormatMermaid, `erDiagram
+    public_users["public.users"] {
+        integer id PK
+        character_varying(255) e-mail
+    }
+    sales_orders["sales.orders"] {
+        integer id PK
+        integer user_id FK
+    }
+    sales_orders }o--o| public_users : "orders_user_fkey"
+`},
+		{FormatDot, `digraph erd {
+    graph [rankdir=LR];
+    node [shape=plaintext, fontname="Helvetica"];
+    edge [dir=both, arrowtail=crow, arrowhead=tee];
+    "public.users" [label=<<table border="0" cellborder="1" cellspacing="0" cellpadding="4"><tr><td bgcolor="lightgrey" colspan="2"><b>public.users</b></td></tr><tr><td port="c0" align="left"><u>id (PK)</u></td><td align="left">integer</td></tr><tr><td port="c1" align="left">e-mail</td><td align="left">character varying(255)</td></tr></table>>];
+    "sales.orders" [label=<<table border="0" cellborder="1" cellspacing="0" cellpadding="4"><tr><td bgcolor="lightgrey" colspan="2"><b>sales.orders</b></td></tr><tr><td port="c0" align="left"><u>id (PK)</u></td><td align="left">integer</td></tr><tr><td port="c1" align="left">user_id (FK)</td><td align="left">integer</td></tr></table>>];
+    "sales.orders":c1 -> "public.users":c0 [tooltip="orders_user_fkey", style=dashed];
+}
+`},
+		{FormatPlantUML, `@startuml
+hide circle
+skinparam linetype ortho
+
+entity "public.users" as public_users {
+  * id : integer <<PK>>
+  --
+  e-mail : character varying(255)
+}
+
+entity "sales.orders" as sales_orders {
+  * id : integer <<PK>>
+  --
+  user_id : integer <<FK>>
+}
+
+sales_orders }o--o| public_users : orders_user_fkey
+@enduml
+`},
+	}
+
+	d, err := New(testCatalog(), "public.users", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := d.Write(&buffer, test.format); err != nil {
+				t.Fatal(err)
+			}
+			if buffer.String() != test.expected {
+				t.Fatalf("got:\n%s\nexpected:\n%s", buffer.String(), test.expected)
+			}
+		})
+	}
+
+	if err := d.Write(&bytes.Buffer{}, "png"); err == nil {
+		t.Fatal("expected an error for an unsupported format")
+	}
+}
+
+func TestRelationsLeaveOutTablesNotDrawn(t *testing.T) {
+	d, err := New(testCatalog(), "sales.lines", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	relations := d.relations()
+	if len(relations) != 1 || relations[0].from != "sales.lines" || relations[0].to != "sales.orders" || relations[0].optional {
+		t.Fatalf("unexpected relations: %+v", relations)
+	}
+	if label := relationLabel(relation{key: &catalog.ForeignKey{Columns: []string{"a", "b"}}}); label != "a, b" {
+		t.Fatalf("got label %q for a foreign key without a name", label)
+	}
+}
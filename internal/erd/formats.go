@@ -0,0 +1,166 @@
+package erd
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// mermaidUnsafe matches what Mermaid does not allow in entity and attribute
+// names, and mermaidTypeUnsafe what it does not allow in types
+var (
+	mermaidUnsafe     = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+	mermaidTypeUnsafe = regexp.MustCompile(`[^A-Za-z0-9_()\[\]-]`)
+)
+
+func (d *Diagram) writeMermaid(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "erDiagram")
+
+	// Entities whose names Mermaid cannot hold get an alias
+	ids := map[string]string{}
+	for _, table := range d.tables {
+		name := d.name(table)
+		id := mermaidUnsafe.ReplaceAllString(name, "_")
+		ids[name] = id
+		if id != name {
+			id += "[" + strconv.Quote(name) + "]"
+		}
+
+		fmt.Fprintf(out, "    %s {\n", id)
+		for _, column := range table.Columns {
+			columnType := column.Type
+			if columnType == "" {
+				columnType = "any"
+			}
+			line := mermaidTypeUnsafe.ReplaceAllString(columnType, "_") + " " + mermaidUnsafe.ReplaceAllString(column.Name, "_")
+			if keys := columnKeys(table, column.Name); len(keys) > 0 {
+				line += " " + strings.Join(keys, ", ")
+			}
+			fmt.Fprintf(out, "        %s\n", line)
+		}
+		fmt.Fprintln(out, "    }")
+	}
+
+	for _, r := range d.relations() {
+		cardinality := "}o--||"
+		if r.optional {
+			cardinality = "}o--o|"
+		}
+		fmt.Fprintf(out, "    %s %s %s : %s\n", ids[r.from], cardinality, ids[r.to], strconv.Quote(relationLabel(r)))
+	}
+	return out.Flush()
+}
+
+func (d *Diagram) writeDot(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "digraph erd {")
+	fmt.Fprintln(out, `    graph [rankdir=LR];`)
+	fmt.Fprintln(out, `    node [shape=plaintext, fontname="Helvetica"];`)
+	fmt.Fprintln(out, `    edge [dir=both, arrowtail=crow, arrowhead=tee];`)
+
+	// Columns are ports, numbered as names may hold any character
+	ports := map[string]map[string]string{}
+	for _, table := range d.tables {
+		name := d.name(table)
+		ports[name] = map[string]string{}
+
+		var label strings.Builder
+		label.WriteString(`<table border="0" cellborder="1" cellspacing="0" cellpadding="4">`)
+		fmt.Fprintf(&label, `<tr><td bgcolor="lightgrey" colspan="2"><b>%s</b></td></tr>`, html.EscapeString(name))
+		for i, column := range table.Columns {
+			port := "c" + strconv.Itoa(i)
+			ports[name][column.Name] = port
+			columnName := html.EscapeString(column.Name)
+			if keys := columnKeys(table, column.Name); len(keys) > 0 {
+				columnName += " (" + strings.Join(keys, ", ") + ")"
+				if keys[0] == "PK" {
+					columnName = "<u>" + columnName + "</u>"
+				}
+			}
+			fmt.Fprintf(&label, `<tr><td port="%s" align="left">%s</td><td align="left">%s</td></tr>`,
+				port, columnName, html.EscapeString(column.Type))
+		}
+		label.WriteString(`</table>`)
+		fmt.Fprintf(out, "    %s [label=<%s>];\n", strconv.Quote(name), label.String())
+	}
+
+	for _, r := range d.relations() {
+		from, to := strconv.Quote(r.from), strconv.Quote(r.to)
+		// Single column keys link their columns, others their tables
+		if len(r.key.Columns) == 1 && len(r.key.ReferencedColumns) == 1 {
+			if port, ok := ports[r.from][r.key.Columns[0]]; ok {
+				from += ":" + port
+			}
+			if port, ok := ports[r.to][r.key.ReferencedColumns[0]]; ok {
+				to += ":" + port
+			}
+		}
+		style := ""
+		if r.optional {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(out, "    %s -> %s [tooltip=%s%s];\n", from, to, strconv.Quote(relationLabel(r)), style)
+	}
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+func (d *Diagram) writePlantUML(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "@startuml")
+	fmt.Fprintln(out, "hide circle")
+	fmt.Fprintln(out, "skinparam linetype ortho")
+
+	ids := map[string]string{}
+	for _, table := range d.tables {
+		name := d.name(table)
+		ids[name] = mermaidUnsafe.ReplaceAllString(name, "_")
+		fmt.Fprintf(out, "\nentity %s as %s {\n", strconv.Quote(name), ids[name])
+
+		// Primary key columns go above the separator, mandatory ones are
+		// marked with *
+		keyLines, otherLines := []string{}, []string{}
+		for _, column := range table.Columns {
+			line := "  "
+			if !column.Nullable {
+				line += "* "
+			}
+			line += column.Name + " : " + column.Type
+			keys := columnKeys(table, column.Name)
+			if len(keys) > 0 {
+				line += " <<" + strings.Join(keys, ", ") + ">>"
+			}
+			if len(keys) > 0 && keys[0] == "PK" {
+				keyLines = append(keyLines, line)
+			} else {
+				otherLines = append(otherLines, line)
+			}
+		}
+		if len(keyLines) > 0 {
+			keyLines = append(keyLines, "  --")
+		}
+		for _, line := range append(keyLines, otherLines...) {
+			fmt.Fprintln(out, line)
+		}
+		fmt.Fprintln(out, "}")
+	}
+
+	relations := d.relations()
+	if len(relations) > 0 {
+		fmt.Fprintln(out)
+	}
+	for _, r := range relations {
+		cardinality := "}o--||"
+		if r.optional {
+			cardinality = "}o--o|"
+		}
+		fmt.Fprintf(out, "%s %s %s : %s\n", ids[r.from], cardinality, ids[r.to], relationLabel(r))
+	}
+	fmt.Fprintln(out, "@enduml")
+	return out.Flush()
+}
@@ -0,0 +1,79 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"dbear/internal/config"
+)
+
+// BundleExporter implements the Exporter interface for shareable connection
+// bundles. Passwords are never written; each connection can instead carry a
+// password source telling teammates where to find it.
+type BundleExporter struct {
+	passwordSources map[string]string
+	stripped        map[string][]string
+}
+
+// NewBundleExporter creates a new BundleExporter. passwordSources maps
+// connection names onto the password source written for them.
+func NewBundleExporter(passwordSources map[string]string) *BundleExporter {
+	return &BundleExporter{passwordSources: passwordSources}
+}
+
+// Stripped returns, by connection name, the parameters the last Export call
+// left out because they hold secrets
+func (b *BundleExporter) Stripped() map[string][]string {
+	return b.stripped
+}
+
+// Export writes the connections into the bundle, replacing entries of the same
+// name and keeping the others
+func (b *BundleExporter) Export(filePath string, connections []config.Connection) error {
+	b.stripped = map[string][]string{}
+
+	content, err := readExisting(filePath)
+	if err != nil {
+		return err
+	}
+
+	bundle := config.Bundle{}
+	if content != "" {
+		if err := json.Unmarshal([]byte(content), &bundle); err != nil {
+			return fmt.Errorf("failed to parse existing bundle %s: %w", filePath, err)
+		}
+	}
+	bundle.Version = config.BundleVersion
+
+	for _, conn := range connections {
+		source := b.passwordSources[conn.Name]
+		if err := config.ValidatePasswordSource(source); err != nil {
+			return fmt.Errorf("connection '%s': %w", conn.Name, err)
+		}
+
+		entry, stripped := config.NewBundleConnection(conn, source)
+		if len(stripped) > 0 {
+			b.stripped[conn.Name] = stripped
+		}
+
+		replaced := false
+		for i, existing := range bundle.Connections {
+			if existing.Name == conn.Name {
+				bundle.Connections[i] = entry
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			bundle.Connections = append(bundle.Connections, entry)
+		}
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Bundles hold no secrets and are meant to be committed
+	return writeFileAtomic(filePath, append(data, '\n'), 0644)
+}
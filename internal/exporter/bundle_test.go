@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dbear/internal/config"
+	"dbear/internal/importer"
+)
+
+func TestBundleLeavesOutPasswordsAndSecretParams(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "bundle.json")
+	conn := config.Connection{
+		Name: "pg", Type: config.TypePostgreSQL, Host: "localhost", Port: 5432,
+		Username: "app", Password: "sentinel-Pa55w0rd", Database: "app",
+		Params: map[string]string{
+			"sslpassword":      "sentinel-KeyPa55",
+			"api_token":        "sentinel-T0ken",
+			"application_name": "dbear",
+		},
+	}
+
+	bundleExporter := NewBundleExporter(map[string]string{"pg": "env:PG_PASSWORD"})
+	if err := bundleExporter.Export(file, []config.Connection{conn}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sentinel") {
+		t.Fatalf("secret written into the bundle:\n%s", data)
+	}
+	if stripped := strings.Join(bundleExporter.Stripped()["pg"], ","); stripped != "api_token,sslpassword" {
+		t.Fatalf("got stripped parameters %q, expected api_token,sslpassword", stripped)
+	}
+
+	t.Setenv("PG_PASSWORD", "from-env")
+	connections, err := importer.NewBundleImporter().Import(file, importer.ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(connections) != 1 {
+		t.Fatalf("got %d connections, expected 1", len(connections))
+	}
+	imported := connections[0]
+	if imported.Password != "from-env" || imported.Host != "localhost" || imported.Params["application_name"] != "dbear" || len(imported.Params) != 1 {
+		t.Fatalf("unexpected imported connection: %+v", imported)
+	}
+}
@@ -0,0 +1,321 @@
+package exporter
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+)
+
+// EnvKeys names the variables an EnvExporter writes. Fields with an empty name
+// are not written.
+type EnvKeys struct {
+	URL      string
+	Type     string
+	Host     string
+	Port     string
+	Socket   string
+	Database string
+	Username string
+	Password string
+}
+
+// URLEnvKeys writes the connection as a single DATABASE_URL
+var URLEnvKeys = EnvKeys{URL: "DATABASE_URL"}
+
+// LaravelEnvKeys writes the DB_* variables of Laravel's config/database.php,
+// which are also the defaults of `connections import env`
+var LaravelEnvKeys = EnvKeys{
+	Type:     "DB_CONNECTION",
+	Host:     "DB_HOST",
+	Port:     "DB_PORT",
+	Socket:   "DB_SOCKET",
+	Database: "DB_DATABASE",
+	Username: "DB_USERNAME",
+	Password: "DB_PASSWORD",
+}
+
+// EnvExporter implements the Exporter interface for .env files. It writes a
+// single connection into the variables named by its keys, updating them in
+// place so comments, ordering and unrelated variables survive.
+type EnvExporter struct {
+	keys EnvKeys
+}
+
+// NewEnvExporter creates a new EnvExporter writing the given keys
+func NewEnvExporter(keys EnvKeys) *EnvExporter {
+	return &EnvExporter{keys: keys}
+}
+
+// Export writes the connection into the env file. Existing files keep their
+// permissions, new ones are readable by the owner only.
+func (e *EnvExporter) Export(filePath string, connections []config.Connection) error {
+	if len(connections) != 1 {
+		return fmt.Errorf("an env file holds exactly one connection, got %d", len(connections))
+	}
+	conn := connections[0]
+
+	values, err := e.values(conn)
+	if err != nil {
+		return err
+	}
+
+	content, err := readExisting(filePath)
+	if err != nil {
+		return err
+	}
+
+	perm := os.FileMode(0600)
+	if info, err := os.Stat(filePath); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	return writeFileAtomic(filePath, []byte(updateEnv(content, values)), perm)
+}
+
+// values returns the variables to write for conn, in order
+func (e *EnvExporter) values(conn config.Connection) ([][2]string, error) {
+	values := [][2]string{}
+	add := func(key, value string) {
+		if key != "" {
+			values = append(values, [2]string{key, value})
+		}
+	}
+
+	if e.keys.URL != "" {
+		connURL, err := EnvURL(conn)
+		if err != nil {
+			return nil, err
+		}
+		add(e.keys.URL, connURL)
+	}
+
+	add(e.keys.Type, envDriverName(conn.Type))
+	if conn.Type == config.TypeSQLite {
+		path, err := connection.SQLitePath(conn)
+		if err != nil {
+			return nil, err
+		}
+		add(e.keys.Database, path)
+		return values, nil
+	}
+
+	if connection.UsesSocket(conn) {
+		add(e.keys.Host, "")
+		add(e.keys.Port, "")
+		add(e.keys.Socket, conn.Socket)
+	} else {
+		add(e.keys.Host, conn.Host)
+		add(e.keys.Port, strconv.Itoa(conn.Port))
+		add(e.keys.Socket, "")
+	}
+	add(e.keys.Database, conn.Database)
+	add(e.keys.Username, conn.Username)
+	add(e.keys.Password, conn.Password)
+
+	return values, nil
+}
+
+// EnvURL builds the connection URL applications expect in DATABASE_URL:
+// libpq URIs for PostgreSQL, mysql:// URLs with a socket parameter for MySQL
+// and sqlite:// URLs with an absolute path for SQLite
+func EnvURL(conn config.Connection) (string, error) {
+	if conn.Type == config.TypeSQLite {
+		return connection.BuildConnectionString(conn)
+	}
+
+	u := &url.URL{
+		Host: fmt.Sprintf("%s:%d", conn.Host, conn.Port),
+		Path: "/" + conn.Database,
+	}
+	if conn.Username != "" {
+		u.User = url.User(conn.Username)
+		if conn.Password != "" {
+			u.User = url.UserPassword(conn.Username, conn.Password)
+		}
+	}
+
+	query := url.Values{}
+	for key, value := range conn.Params {
+		query.Set(key, value)
+	}
+
+	switch conn.Type {
+	case config.TypePostgreSQL:
+		u.Scheme = "postgres"
+		if connection.UsesSocket(conn) {
+			dir, port := connection.PostgreSQLSocketDir(conn)
+			u.Host = fmt.Sprintf(":%d", port)
+			query.Set("host", dir)
+		}
+		if conn.SSLMode != "" {
+			query.Set("sslmode", conn.SSLMode)
+		}
+		if conn.SSLRootCert != "" {
+			query.Set("sslrootcert", conn.SSLRootCert)
+		}
+		if conn.SSLCert != "" {
+			query.Set("sslcert", conn.SSLCert)
+		}
+		if conn.SSLKey != "" {
+			query.Set("sslkey", conn.SSLKey)
+		}
+	case config.TypeMySQL:
+		u.Scheme = "mysql"
+		if connection.UsesSocket(conn) {
+			u.Host = "localhost"
+			query.Set("socket", conn.Socket)
+		}
+	default:
+		return "", fmt.Errorf("unsupported database type: %s", conn.Type)
+	}
+
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// envDriverName returns the DB_CONNECTION value Laravel uses for a database type
+func envDriverName(dbType string) string {
+	if dbType == config.TypePostgreSQL {
+		return "pgsql"
+	}
+	return dbType
+}
+
+var envLinePattern = regexp.MustCompile(`^\s*(export\s+)?([A-Za-z0-9_.-]+)\s*[=:]\s*(.*)$`)
+
+// updateEnv applies values to the content of an env file. Existing variables
+// are rewritten in place, keeping an export prefix; later duplicates are
+// dropped. Missing variables are appended, except empty ones.
+func updateEnv(content string, values [][2]string) string {
+	lines := []string{}
+	if content != "" {
+		lines = strings.Split(strings.TrimRight(content, "\n"), "\n")
+	}
+
+	wanted := map[string]string{}
+	for _, kv := range values {
+		wanted[kv[0]] = kv[1]
+	}
+
+	written := map[string]bool{}
+	result := []string{}
+	for i := 0; i < len(lines); i++ {
+		match := envLinePattern.FindStringSubmatch(lines[i])
+		if match == nil {
+			result = append(result, lines[i])
+			continue
+		}
+
+		// Quoted values may continue on the following lines
+		end := i + envValueLines(match[3], lines[i+1:])
+		key := match[2]
+		value, ok := wanted[key]
+		switch {
+		case !ok:
+			result = append(result, lines[i:end+1]...)
+		case !written[key]:
+			line := match[1] + key + "=" + quoteEnvValue(value)
+			if end == i {
+				line += envInlineComment(match[3])
+			}
+			result = append(result, line)
+			written[key] = true
+		}
+		i = end
+	}
+
+	added := []string{}
+	for _, kv := range values {
+		if !written[kv[0]] && kv[1] != "" {
+			added = append(added, kv[0]+"="+quoteEnvValue(kv[1]))
+			written[kv[0]] = true
+		}
+	}
+	if len(added) > 0 && len(result) > 0 && strings.TrimSpace(result[len(result)-1]) != "" {
+		result = append(result, "")
+	}
+	result = append(result, added...)
+
+	return strings.Join(result, "\n") + "\n"
+}
+
+// envInlineComment returns the comment following a single-line value,
+// including the whitespace before it, or an empty string
+func envInlineComment(value string) string {
+	start := 0
+	if value != "" && strings.ContainsRune(`"'`+"`", rune(value[0])) {
+		closing := envClosingQuote(value[1:], value[0])
+		if closing < 0 {
+			return ""
+		}
+		start = closing + 2
+	}
+
+	for i := start; i < len(value); i++ {
+		if value[i] == '#' && i > 0 && (value[i-1] == ' ' || value[i-1] == '\t') {
+			end := i
+			for end > start && (value[end-1] == ' ' || value[end-1] == '\t') {
+				end--
+			}
+			return value[end:]
+		}
+	}
+	return ""
+}
+
+// envValueLines returns how many of the following lines a value starting as
+// value on its first line spans
+func envValueLines(value string, following []string) int {
+	if value == "" || !strings.ContainsRune(`"'`+"`", rune(value[0])) {
+		return 0
+	}
+
+	quote := value[0]
+	if envQuoteCloses(value[1:], quote) {
+		return 0
+	}
+	for i, line := range following {
+		if envQuoteCloses(line, quote) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// envQuoteCloses reports whether text contains a closing quote
+func envQuoteCloses(text string, quote byte) bool {
+	return envClosingQuote(text, quote) >= 0
+}
+
+// envClosingQuote returns the index of the closing quote in text, skipping
+// backslash escapes inside double quotes, or -1
+func envClosingQuote(text string, quote byte) int {
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && quote == '"' {
+			i++
+			continue
+		}
+		if text[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// quoteEnvValue double-quotes values containing characters dotenv parsers
+// treat specially: whitespace, quotes, comment signs, dollar signs and
+// backslashes
+func quoteEnvValue(value string) string {
+	if !strings.ContainsAny(value, " \t\n\r\"'`#$\\") {
+		return value
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`)
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -0,0 +1,66 @@
+package exporter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"dbear/internal/config"
+)
+
+// Exporter defines the interface for all export types
+type Exporter interface {
+	// Export writes connections into filePath, updating entries that already
+	// exist there and keeping everything else in the file
+	Export(filePath string, connections []config.Connection) error
+}
+
+// readExisting returns the current contents of filePath, or an empty string when
+// the file does not exist yet
+func readExisting(filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	return string(data), nil
+}
+
+// writeSecretFile atomically replaces filePath with data, readable by the owner
+// only. Client libraries such as libpq ignore password files with wider
+// permissions.
+func writeSecretFile(filePath string, data []byte) error {
+	return writeFileAtomic(filePath, data, 0600)
+}
+
+// writeFileAtomic replaces filePath with data through a temporary file in the
+// same directory, so readers never see a partially written file
+func writeFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filePath)+".*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, filePath)
+}
@@ -0,0 +1,140 @@
+package exporter
+
+import (
+	"strings"
+)
+
+// iniUpdate describes the contents one section of an INI-style file should
+// have after an export
+type iniUpdate struct {
+	Name string
+	// Values are written in order; empty values remove the key
+	Values [][2]string
+	// Managed lists the keys dbear owns in the section. Managed keys missing
+	// from Values are removed, other keys are left untouched.
+	Managed []string
+}
+
+// updateINI applies updates to the content of an INI-style file. Existing keys
+// are rewritten in place, so comments, ordering and unrelated options survive.
+// Sections that do not exist yet are appended.
+func updateINI(content string, updates []iniUpdate) string {
+	lines := []string{}
+	if content != "" {
+		lines = strings.Split(strings.TrimRight(content, "\n"), "\n")
+	}
+
+	for _, update := range updates {
+		lines = updateINISection(lines, update)
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func updateINISection(lines []string, update iniUpdate) []string {
+	start := -1
+	for i, line := range lines {
+		if iniSectionName(line) == update.Name {
+			start = i
+			break
+		}
+	}
+
+	if start < 0 {
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+update.Name+"]")
+		for _, kv := range update.Values {
+			if kv[1] != "" {
+				lines = append(lines, kv[0]+"="+kv[1])
+			}
+		}
+		return lines
+	}
+
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if iniSectionName(lines[i]) != "" {
+			end = i
+			break
+		}
+	}
+
+	values := map[string]string{}
+	for _, kv := range update.Values {
+		values[normalizeINIKey(kv[0])] = kv[1]
+	}
+	managed := map[string]bool{}
+	for _, key := range update.Managed {
+		managed[normalizeINIKey(key)] = true
+	}
+
+	written := map[string]bool{}
+	body := []string{}
+	for _, line := range lines[start+1 : end] {
+		key := iniLineKey(line)
+		if key == "" {
+			body = append(body, line)
+			continue
+		}
+
+		value, wanted := values[key]
+		switch {
+		case wanted && value != "" && !written[key]:
+			// Keep the spacing around "=" the line was written with
+			name, rest, _ := strings.Cut(line, "=")
+			spacing := rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+			body = append(body, name+"="+spacing+value)
+			written[key] = true
+		case wanted || managed[key]:
+			// dropped: cleared, duplicated or no longer set by dbear
+		default:
+			body = append(body, line)
+		}
+	}
+
+	// New keys go after the last non-blank line of the section
+	insertAt := len(body)
+	for insertAt > 0 && strings.TrimSpace(body[insertAt-1]) == "" {
+		insertAt--
+	}
+	added := []string{}
+	for _, kv := range update.Values {
+		key := normalizeINIKey(kv[0])
+		if kv[1] != "" && !written[key] {
+			added = append(added, kv[0]+"="+kv[1])
+			written[key] = true
+		}
+	}
+	body = append(body[:insertAt], append(added, body[insertAt:]...)...)
+
+	result := append([]string{}, lines[:start+1]...)
+	result = append(result, body...)
+	return append(result, lines[end:]...)
+}
+
+func iniSectionName(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+		return strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+	}
+	return ""
+}
+
+// iniLineKey returns the normalized key of an option line, or an empty string
+// for blank lines and comments
+func iniLineKey(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "!") {
+		return ""
+	}
+	key, _, _ := strings.Cut(trimmed, "=")
+	return normalizeINIKey(key)
+}
+
+// normalizeINIKey folds the dash/underscore spellings MySQL accepts for the
+// same option
+func normalizeINIKey(key string) string {
+	return strings.ReplaceAll(strings.TrimSpace(key), "_", "-")
+}
@@ -0,0 +1,141 @@
+package exporter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"dbear/internal/config"
+	"dbear/internal/importer"
+)
+
+func TestUpdateINI(t *testing.T) {
+	update := iniUpdate{
+		Name:    "client-prod",
+		Values:  [][2]string{{"host", "db.example.com"}, {"port", "3307"}, {"socket", ""}},
+		Managed: []string{"host", "port", "socket", "ssl-mode"},
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			"empty file", "",
+			"[client-prod]\nhost=db.example.com\nport=3307\n",
+		},
+		{
+			"new section", "[client]\nuser=root\n",
+			"[client]\nuser=root\n\n[client-prod]\nhost=db.example.com\nport=3307\n",
+		},
+		{
+			"keys rewritten in place", "[client-prod]\n# primary\nport = 3306\nuser=admin\nhost=old\n",
+			"[client-prod]\n# primary\nport = 3307\nuser=admin\nhost=db.example.com\n",
+		},
+		{
+			"cleared and unmanaged keys", "[client-prod]\nsocket=/tmp/mysql.sock\nssl_mode=REQUIRED\nquick\n",
+			"[client-prod]\nquick\nhost=db.example.com\nport=3307\n",
+		},
+		{
+			"duplicates dropped", "[client-prod]\nhost=a\nhost=b\n\n[mysql-prod]\ndatabase=app\n",
+			"[client-prod]\nhost=db.example.com\nport=3307\n\n[mysql-prod]\ndatabase=app\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := updateINI(test.content, []iniUpdate{update}); result != test.expected {
+				t.Fatalf("got:\n%s\nexpected:\n%s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestQuoteMyCnfValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"", ""},
+		{"s3cret", "s3cret"},
+		{"pa#ss", `"pa#ss"`},
+		{"pa ss", `"pa ss"`},
+		{`a"b\c`, `"a\"b\\c"`},
+	}
+
+	for _, test := range tests {
+		if quoted := quoteMyCnfValue(test.value); quoted != test.expected {
+			t.Errorf("%q: got %s, expected %s", test.value, quoted, test.expected)
+		}
+	}
+}
+
+func TestMyCnfExportRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".my.cnf")
+	connections := []config.Connection{
+		{
+			Name: "prod", Type: config.TypeMySQL, Host: "db.example.com", Port: 3307, Username: "admin", Password: `p#ss "q"`,
+			Database: "shop", SSLMode: config.SSLModeVerifyCA, SSLRootCert: "/etc/ssl/ca.pem",
+			Params: map[string]string{"charset": "utf8mb4"},
+		},
+		{Name: "mycnf", Type: config.TypeMySQL, Socket: "/var/run/mysqld/mysqld.sock", Port: 3306, Username: "root", Database: "app"},
+	}
+	if err := NewMyCnfExporter().Export(path, connections); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := importer.NewMyCnfImporter().Import(path, importer.ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != 2 {
+		t.Fatalf("got %d connections, expected 2: %+v", len(imported), imported)
+	}
+
+	prod, local := imported[0], imported[1]
+	if prod.Name != "prod" || prod.Host != "db.example.com" || prod.Port != 3307 || prod.Username != "admin" ||
+		prod.Password != `p#ss "q"` || prod.Database != "shop" || prod.SSLMode != config.SSLModeVerifyCA ||
+		prod.SSLRootCert != "/etc/ssl/ca.pem" || prod.Params["charset"] != "utf8mb4" {
+		t.Errorf("unexpected prod connection: %+v", prod)
+	}
+	if local.Name != "mycnf" || local.Socket != "/var/run/mysqld/mysqld.sock" || local.Username != "root" || local.Database != "app" {
+		t.Errorf("unexpected local connection: %+v", local)
+	}
+
+	postgres := config.Connection{Name: "pg", Type: config.TypePostgreSQL}
+	if err := NewMyCnfExporter().Export(path, []config.Connection{postgres}); err == nil {
+		t.Fatal("expected an error for a PostgreSQL connection")
+	}
+}
+
+func TestPgServiceExportRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pg_service.conf")
+	t.Setenv("PGPASSFILE", filepath.Join(t.TempDir(), "missing"))
+	connections := []config.Connection{
+		{
+			Name: "prod", Type: config.TypePostgreSQL, Host: "db.example.com", Port: 5433, Database: "app", Username: "admin",
+			Password: "s3cret", SSLMode: config.SSLModeRequire, Params: map[string]string{"application_name": "dbear"},
+		},
+		{Name: "local", Type: config.TypePostgreSQL, Socket: "/var/run/postgresql/.s.PGSQL.5434", Database: "app", Username: "app"},
+	}
+	if err := NewPgServiceExporter().Export(path, connections); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := importer.NewPgServiceImporter().Import(path, importer.ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != 2 {
+		t.Fatalf("got %d connections, expected 2: %+v", len(imported), imported)
+	}
+
+	prod, local := imported[0], imported[1]
+	if prod.Host != "db.example.com" || prod.Port != 5433 || prod.Password != "s3cret" || prod.SSLMode != config.SSLModeRequire ||
+		prod.Params["application_name"] != "dbear" {
+		t.Errorf("unexpected prod connection: %+v", prod)
+	}
+	if local.Socket != "/var/run/postgresql" || local.Port != 5434 || local.Host != "" {
+		t.Errorf("unexpected local connection: %+v", local)
+	}
+}
@@ -0,0 +1,86 @@
+package exporter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+)
+
+// MyCnfExporter implements the Exporter interface for MySQL option files. Each
+// connection becomes a [client-<name>] group, usable with
+// `mysql --defaults-group-suffix=-<name>`. The database goes into the matching
+// [mysql-<name>] group because mysqldump rejects a database option in client
+// groups.
+type MyCnfExporter struct{}
+
+// NewMyCnfExporter creates a new MyCnfExporter instance
+func NewMyCnfExporter() *MyCnfExporter {
+	return &MyCnfExporter{}
+}
+
+var myCnfManagedKeys = []string{
+	"host", "port", "user", "password", "socket",
+	"ssl-mode", "ssl-ca", "ssl-cert", "ssl-key", "default-character-set",
+}
+
+// Export writes or updates the option groups of each MySQL connection
+func (m *MyCnfExporter) Export(filePath string, connections []config.Connection) error {
+	content, err := readExisting(filePath)
+	if err != nil {
+		return err
+	}
+
+	updates := make([]iniUpdate, 0, len(connections)*2)
+	for _, conn := range connections {
+		if conn.Type != config.TypeMySQL {
+			return fmt.Errorf("connection '%s' is not a MySQL connection", conn.Name)
+		}
+
+		clientGroup, mysqlGroup := MyCnfGroups(conn.Name)
+
+		values := [][2]string{}
+		if connection.UsesSocket(conn) {
+			values = append(values, [2]string{"socket", conn.Socket}, [2]string{"host", ""}, [2]string{"port", ""})
+		} else {
+			values = append(values, [2]string{"host", conn.Host}, [2]string{"port", strconv.Itoa(conn.Port)}, [2]string{"socket", ""})
+		}
+		values = append(values,
+			[2]string{"user", conn.Username},
+			[2]string{"password", quoteMyCnfValue(conn.Password)},
+			[2]string{"ssl-mode", connection.MySQLClientSSLMode(conn.SSLMode)},
+			[2]string{"ssl-ca", conn.SSLRootCert},
+			[2]string{"ssl-cert", conn.SSLCert},
+			[2]string{"ssl-key", conn.SSLKey},
+			[2]string{"default-character-set", conn.Params["charset"]},
+		)
+
+		updates = append(updates,
+			iniUpdate{Name: clientGroup, Values: values, Managed: myCnfManagedKeys},
+			iniUpdate{Name: mysqlGroup, Values: [][2]string{{"database", conn.Database}}, Managed: []string{"database"}},
+		)
+	}
+
+	return writeSecretFile(filePath, []byte(updateINI(content, updates)))
+}
+
+// MyCnfGroups returns the client and mysql option groups of a connection. The
+// "mycnf" name, given to the plain [client] group on import, maps back onto it.
+func MyCnfGroups(name string) (string, string) {
+	if name == "mycnf" {
+		return "client", "mysql"
+	}
+	return "client-" + name, "mysql-" + name
+}
+
+// quoteMyCnfValue quotes values that option files would otherwise cut at a
+// comment sign or whitespace
+func quoteMyCnfValue(value string) string {
+	if value == "" || !strings.ContainsAny(value, "#; \t\"'\\") {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -0,0 +1,122 @@
+package exporter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+	"dbear/internal/importer"
+)
+
+// PgpassExporter implements the Exporter interface for libpq password files
+type PgpassExporter struct{}
+
+// NewPgpassExporter creates a new PgpassExporter instance
+func NewPgpassExporter() *PgpassExporter {
+	return &PgpassExporter{}
+}
+
+// Export writes one line per PostgreSQL connection. A line with the same host,
+// port, database and user is replaced rather than duplicated, and new lines
+// go before the wildcard lines libpq would otherwise match first.
+func (p *PgpassExporter) Export(filePath string, connections []config.Connection) error {
+	content, err := readExisting(filePath)
+	if err != nil {
+		return err
+	}
+
+	lines := []string{}
+	if content != "" {
+		lines = strings.Split(strings.TrimRight(content, "\n"), "\n")
+	}
+
+	for _, conn := range connections {
+		if conn.Type != config.TypePostgreSQL {
+			return fmt.Errorf("connection '%s' is not a PostgreSQL connection", conn.Name)
+		}
+
+		fields := pgpassFields(conn)
+		line := strings.Join(escapePgpassFields(fields), ":")
+
+		// libpq uses the first line matching, so the line of conn replaces the
+		// first one matching it when it has the same fields, and goes before
+		// it otherwise, replacing the line of the same fields further down
+		entries, indexes := pgpassEntries(lines)
+		first := importer.MatchPgpassEntry(entries, conn)
+		switch {
+		case first < 0:
+			lines = append(lines, line)
+		case equalFields(importer.SplitPgpassLine(lines[indexes[first]])[:4], fields[:4]):
+			lines[indexes[first]] = line
+		default:
+			kept := append([]string{}, lines[:indexes[first]]...)
+			kept = append(kept, line)
+			for _, existing := range lines[indexes[first]:] {
+				existingFields := importer.SplitPgpassLine(existing)
+				if len(existingFields) == 5 && equalFields(existingFields[:4], fields[:4]) {
+					continue
+				}
+				kept = append(kept, existing)
+			}
+			lines = kept
+		}
+	}
+
+	return writeSecretFile(filePath, []byte(strings.Join(lines, "\n")+"\n"))
+}
+
+// pgpassFields returns the host, port, database, user and password fields libpq
+// matches a connection against
+func pgpassFields(conn config.Connection) []string {
+	host, port := conn.Host, conn.Port
+	if connection.UsesSocket(conn) {
+		// libpq matches socket connections against "localhost"
+		host = "localhost"
+		_, port = connection.PostgreSQLSocketDir(conn)
+	}
+	if port == 0 {
+		port = 5432
+	}
+
+	return []string{host, strconv.Itoa(port), conn.Database, conn.Username, conn.Password}
+}
+
+// pgpassEntries parses the entry lines of a password file, returning the index
+// of the line of each entry
+func pgpassEntries(lines []string) ([]importer.PgpassEntry, []int) {
+	entries := []importer.PgpassEntry{}
+	indexes := []int{}
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		fields := importer.SplitPgpassLine(line)
+		if len(fields) != 5 {
+			continue
+		}
+		entries = append(entries, importer.PgpassEntry{Host: fields[0], Port: fields[1], Database: fields[2], Username: fields[3], Password: fields[4]})
+		indexes = append(indexes, i)
+	}
+	return entries, indexes
+}
+
+func escapePgpassFields(fields []string) []string {
+	replacer := strings.NewReplacer(`\`, `\\`, `:`, `\:`)
+	escaped := make([]string, len(fields))
+	for i, field := range fields {
+		escaped[i] = replacer.Replace(field)
+	}
+	return escaped
+}
+
+func equalFields(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dbear/internal/config"
+)
+
+func TestPgpassExportGoesBeforeMatchingWildcards(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		expected string
+	}{
+		{
+			"empty file", "",
+			"db.example.com:5432:app:admin:secret\n",
+		},
+		{
+			"same fields", "# team\ndb.example.com:5432:app:admin:old\n",
+			"# team\ndb.example.com:5432:app:admin:secret\n",
+		},
+		{
+			"earlier wildcard", "other:5432:x:y:z\n*:*:*:admin:wildcard\n",
+			"other:5432:x:y:z\ndb.example.com:5432:app:admin:secret\n*:*:*:admin:wildcard\n",
+		},
+		{
+			"wildcard before the old line", "*:*:*:admin:wildcard\ndb.example.com:5432:app:admin:old\n",
+			"db.example.com:5432:app:admin:secret\n*:*:*:admin:wildcard\n",
+		},
+		{
+			"unrelated wildcard", "*:*:*:readonly:wildcard\n",
+			"*:*:*:readonly:wildcard\ndb.example.com:5432:app:admin:secret\n",
+		},
+	}
+
+	conn := config.Connection{
+		Name: "app", Type: config.TypePostgreSQL, Host: "db.example.com", Port: 5432,
+		Database: "app", Username: "admin", Password: "secret",
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".pgpass")
+			if test.existing != "" {
+				if err := os.WriteFile(path, []byte(test.existing), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := NewPgpassExporter().Export(path, []config.Connection{conn}); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != test.expected {
+				t.Fatalf("got:\n%s\nexpected:\n%s", data, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+)
+
+// PgServiceExporter implements the Exporter interface for libpq connection
+// service files. Each connection becomes a [service] named after it, so
+// `psql service=<name>` reaches the same database as dbear.
+type PgServiceExporter struct{}
+
+// NewPgServiceExporter creates a new PgServiceExporter instance
+func NewPgServiceExporter() *PgServiceExporter {
+	return &PgServiceExporter{}
+}
+
+var pgServiceManagedKeys = []string{
+	"host", "port", "dbname", "user", "password",
+	"sslmode", "sslrootcert", "sslcert", "sslkey",
+}
+
+// Export writes or updates one service section per PostgreSQL connection
+func (p *PgServiceExporter) Export(filePath string, connections []config.Connection) error {
+	content, err := readExisting(filePath)
+	if err != nil {
+		return err
+	}
+
+	updates := make([]iniUpdate, 0, len(connections))
+	for _, conn := range connections {
+		if conn.Type != config.TypePostgreSQL {
+			return fmt.Errorf("connection '%s' is not a PostgreSQL connection", conn.Name)
+		}
+
+		host, port := conn.Host, conn.Port
+		if connection.UsesSocket(conn) {
+			host, port = connection.PostgreSQLSocketDir(conn)
+		}
+
+		values := [][2]string{
+			{"host", host},
+			{"port", strconv.Itoa(port)},
+			{"dbname", conn.Database},
+			{"user", conn.Username},
+			{"password", conn.Password},
+			{"sslmode", conn.SSLMode},
+			{"sslrootcert", conn.SSLRootCert},
+			{"sslcert", conn.SSLCert},
+			{"sslkey", conn.SSLKey},
+		}
+
+		paramKeys := make([]string, 0, len(conn.Params))
+		for key := range conn.Params {
+			paramKeys = append(paramKeys, key)
+		}
+		sort.Strings(paramKeys)
+		for _, key := range paramKeys {
+			values = append(values, [2]string{key, conn.Params[key]})
+		}
+
+		updates = append(updates, iniUpdate{
+			Name:    conn.Name,
+			Values:  values,
+			Managed: pgServiceManagedKeys,
+		})
+	}
+
+	return writeSecretFile(filePath, []byte(updateINI(content, updates)))
+}
@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"dbear/internal/config"
+)
+
+// BundleImporter implements the Importer interface for connection bundles
+// written by `connections export --bundle`. Passwords are resolved from each
+// connection's password source when possible; the others are left empty for
+// the caller to ask for.
+type BundleImporter struct {
+	skipped []SkippedEntry
+}
+
+// NewBundleImporter creates a new BundleImporter instance
+func NewBundleImporter() *BundleImporter {
+	return &BundleImporter{}
+}
+
+// Skipped returns the connections the last Import call could not convert
+func (b *BundleImporter) Skipped() []SkippedEntry {
+	return b.skipped
+}
+
+// Import imports connections from a bundle file
+func (b *BundleImporter) Import(filePath string, options ImportOptions) ([]config.Connection, error) {
+	b.skipped = nil
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var bundle config.Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+	if bundle.Version > config.BundleVersion {
+		return nil, fmt.Errorf("bundle format version %d is newer than this dbear supports (%d)", bundle.Version, config.BundleVersion)
+	}
+
+	// A missing password file only means pgpass sources resolve to nothing
+	passwords, _ := ParsePgpassFile(PgpassPath())
+
+	connections := []config.Connection{}
+	for _, entry := range bundle.Connections {
+		conn := entry.Connection()
+		if options.DatabaseType != "" && conn.Type != options.DatabaseType {
+			continue
+		}
+		if !config.IsValidType(conn.Type) {
+			b.skipped = append(b.skipped, SkippedEntry{Name: conn.Name, Reason: "unsupported database type " + conn.Type})
+			continue
+		}
+		if err := config.ValidatePasswordSource(entry.PasswordSource); err != nil {
+			b.skipped = append(b.skipped, SkippedEntry{Name: conn.Name, Reason: err.Error()})
+			continue
+		}
+
+		switch {
+		case conn.Password != "":
+		case strings.HasPrefix(entry.PasswordSource, config.PasswordSourceEnv):
+			conn.Password = os.Getenv(strings.TrimPrefix(entry.PasswordSource, config.PasswordSourceEnv))
+		case entry.PasswordSource == config.PasswordSourcePgpass:
+			conn.Password = lookupPgpassPassword(passwords, conn)
+		}
+
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
+
+// NeedsPassword reports whether an imported connection still lacks a password
+// it presumably needs: a server connection with a user name but no password
+func NeedsPassword(conn config.Connection) bool {
+	return conn.Type != config.TypeSQLite && conn.Username != "" && conn.Password == ""
+}
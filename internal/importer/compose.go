@@ -0,0 +1,387 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dbear/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeFileNames lists the file names docker compose looks for, in order
+var ComposeFileNames = []string{
+	"compose.yaml",
+	"compose.yml",
+	"docker-compose.yaml",
+	"docker-compose.yml",
+}
+
+// ComposeImporter implements the Importer interface for docker compose files.
+// It proposes one connection per database service, reached through the port
+// the service publishes on the host.
+type ComposeImporter struct {
+	skipped []SkippedEntry
+}
+
+// NewComposeImporter creates a new ComposeImporter instance
+func NewComposeImporter() *ComposeImporter {
+	return &ComposeImporter{}
+}
+
+type composeFile struct {
+	Name     string                    `yaml:"name"`
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image       string             `yaml:"image"`
+	Environment composeEnvironment `yaml:"environment"`
+	EnvFile     composeEnvFiles    `yaml:"env_file"`
+	Ports       []composePort      `yaml:"ports"`
+}
+
+// composeEnvironment accepts both the map and the KEY=VALUE list syntax
+type composeEnvironment map[string]*string
+
+func (e *composeEnvironment) UnmarshalYAML(node *yaml.Node) error {
+	env := composeEnvironment{}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			valueNode := node.Content[i+1]
+			if valueNode.Tag == "!!null" {
+				env[key] = nil
+				continue
+			}
+			value := valueNode.Value
+			env[key] = &value
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			key, value, found := strings.Cut(item.Value, "=")
+			if !found {
+				env[key] = nil
+				continue
+			}
+			env[key] = &value
+		}
+	default:
+		return fmt.Errorf("environment must be a mapping or a list")
+	}
+
+	*e = env
+	return nil
+}
+
+type composeEnvFile struct {
+	Path     string
+	Required bool
+}
+
+// composeEnvFiles accepts a single path, a list of paths or the long syntax
+// with path and required keys
+type composeEnvFiles []composeEnvFile
+
+func (f *composeEnvFiles) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*f = composeEnvFiles{{Path: node.Value, Required: true}}
+		return nil
+	case yaml.SequenceNode:
+		files := composeEnvFiles{}
+		for _, item := range node.Content {
+			if item.Kind == yaml.ScalarNode {
+				files = append(files, composeEnvFile{Path: item.Value, Required: true})
+				continue
+			}
+
+			var long struct {
+				Path     string `yaml:"path"`
+				Required *bool  `yaml:"required"`
+			}
+			if err := item.Decode(&long); err != nil {
+				return err
+			}
+			required := long.Required == nil || *long.Required
+			files = append(files, composeEnvFile{Path: long.Path, Required: required})
+		}
+		*f = files
+		return nil
+	default:
+		return fmt.Errorf("env_file must be a path or a list")
+	}
+}
+
+// composePort accepts the short "[ip:]published:target[/protocol]" syntax and
+// the long syntax with target and published keys
+type composePort struct {
+	Target    string
+	Published string
+}
+
+func (p *composePort) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var long struct {
+			Target    string `yaml:"target"`
+			Published string `yaml:"published"`
+		}
+		if err := node.Decode(&long); err != nil {
+			return err
+		}
+		p.Target = long.Target
+		p.Published = long.Published
+		return nil
+	}
+
+	spec, _, _ := strings.Cut(node.Value, "/")
+	parts := strings.Split(spec, ":")
+	switch len(parts) {
+	case 1:
+		p.Target = parts[0]
+	default:
+		p.Target = parts[len(parts)-1]
+		p.Published = parts[len(parts)-2]
+	}
+	return nil
+}
+
+// Skipped returns the database services the last Import call could not convert
+func (c *ComposeImporter) Skipped() []SkippedEntry {
+	return c.skipped
+}
+
+// Import imports connections from a docker compose file
+func (c *ComposeImporter) Import(filePath string, options ImportOptions) ([]config.Connection, error) {
+	c.skipped = nil
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compose file: %w", err)
+	}
+
+	var compose composeFile
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("failed to parse compose file: %w", err)
+	}
+
+	projectDir := filepath.Dir(filePath)
+	lookup, err := ProjectLookup(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := options.ConnectionName
+	if prefix == "" {
+		prefix = compose.Name
+	}
+	if prefix == "" {
+		prefix = projectName(projectDir)
+	}
+
+	serviceNames := make([]string, 0, len(compose.Services))
+	for name := range compose.Services {
+		serviceNames = append(serviceNames, name)
+	}
+	sort.Strings(serviceNames)
+
+	connections := []config.Connection{}
+	for _, serviceName := range serviceNames {
+		service := compose.Services[serviceName]
+
+		image, err := Interpolate(service.Image, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("service '%s': %w", serviceName, err)
+		}
+
+		dbType := composeImageType(image)
+		if dbType == "" {
+			continue
+		}
+		if options.DatabaseType != "" && options.DatabaseType != dbType {
+			continue
+		}
+
+		env, err := c.serviceEnvironment(service, projectDir, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("service '%s': %w", serviceName, err)
+		}
+
+		port, err := publishedPort(service.Ports, dbType, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("service '%s': %w", serviceName, err)
+		}
+		if port == 0 {
+			c.skipped = append(c.skipped, SkippedEntry{
+				Name:   serviceName,
+				Reason: "database port is not published to the host",
+			})
+			continue
+		}
+
+		conn := config.Connection{
+			Name: serviceName,
+			Type: dbType,
+			Host: "localhost",
+			Port: port,
+		}
+		if prefix != "" {
+			conn.Name = prefix + "-" + serviceName
+		}
+
+		switch dbType {
+		case config.TypePostgreSQL:
+			applyPostgresEnvironment(&conn, env)
+		case config.TypeMySQL:
+			applyMySQLEnvironment(&conn, env)
+		}
+
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
+
+// serviceEnvironment merges the env_file entries and environment of a service,
+// with environment taking precedence as in docker compose.
+func (c *ComposeImporter) serviceEnvironment(service composeService, projectDir string, lookup LookupFunc) (map[string]string, error) {
+	env := map[string]string{}
+
+	for _, envFile := range service.EnvFile {
+		path, err := Interpolate(envFile.Path, lookup)
+		if err != nil {
+			return nil, err
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectDir, path)
+		}
+
+		if _, err := os.Stat(path); os.IsNotExist(err) && !envFile.Required {
+			continue
+		}
+
+		values, err := ParseEnvFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range values {
+			env[key] = value
+		}
+	}
+
+	for key, value := range service.Environment {
+		if value == nil {
+			// KEY without a value is taken from the environment compose runs in
+			if resolved, ok := lookup(key); ok {
+				env[key] = resolved
+			}
+			continue
+		}
+
+		resolved, err := Interpolate(*value, lookup)
+		if err != nil {
+			return nil, err
+		}
+		env[key] = resolved
+	}
+
+	return env, nil
+}
+
+// composeImageType maps an image reference to a database type, or returns an
+// empty string for images that are not databases dbear supports.
+func composeImageType(image string) string {
+	name := image
+	if at := strings.Index(name, "@"); at >= 0 {
+		name = name[:at]
+	}
+	if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+		name = name[:colon]
+	}
+	name = strings.ToLower(name)
+	base := name[strings.LastIndex(name, "/")+1:]
+
+	switch {
+	case base == "postgres" || base == "postgresql" || base == "postgis" ||
+		strings.HasPrefix(base, "timescaledb") || strings.HasPrefix(base, "pgvector"):
+		return config.TypePostgreSQL
+	case base == "mysql" || base == "mysql-server" || base == "mariadb" ||
+		base == "percona" || base == "percona-server":
+		return config.TypeMySQL
+	default:
+		return ""
+	}
+}
+
+// publishedPort returns the host port the database port of a service is
+// published on, or 0 when it is not published.
+func publishedPort(ports []composePort, dbType string, lookup LookupFunc) (int, error) {
+	target := "5432"
+	if dbType == config.TypeMySQL {
+		target = "3306"
+	}
+
+	for _, port := range ports {
+		portTarget, err := Interpolate(port.Target, lookup)
+		if err != nil {
+			return 0, err
+		}
+		if portTarget != target {
+			continue
+		}
+
+		published, err := Interpolate(port.Published, lookup)
+		if err != nil {
+			return 0, err
+		}
+		// A range or an empty value lets docker pick the host port
+		if published == "" || strings.Contains(published, "-") {
+			return 0, nil
+		}
+
+		hostPort, err := strconv.Atoi(published)
+		if err != nil {
+			return 0, fmt.Errorf("invalid published port '%s'", published)
+		}
+		return hostPort, nil
+	}
+
+	return 0, nil
+}
+
+func applyPostgresEnvironment(conn *config.Connection, env map[string]string) {
+	conn.Username = firstEnvValue(env, "POSTGRES_USER", "POSTGRESQL_USERNAME")
+	if conn.Username == "" {
+		conn.Username = "postgres"
+	}
+	conn.Password = firstEnvValue(env, "POSTGRES_PASSWORD", "POSTGRESQL_PASSWORD")
+	conn.Database = firstEnvValue(env, "POSTGRES_DB", "POSTGRESQL_DATABASE")
+	if conn.Database == "" {
+		conn.Database = conn.Username
+	}
+}
+
+func applyMySQLEnvironment(conn *config.Connection, env map[string]string) {
+	conn.Database = firstEnvValue(env, "MYSQL_DATABASE", "MARIADB_DATABASE")
+	conn.Username = firstEnvValue(env, "MYSQL_USER", "MARIADB_USER")
+	conn.Password = firstEnvValue(env, "MYSQL_PASSWORD", "MARIADB_PASSWORD")
+	if conn.Username == "" {
+		conn.Username = "root"
+		conn.Password = firstEnvValue(env, "MYSQL_ROOT_PASSWORD", "MARIADB_ROOT_PASSWORD")
+	}
+}
+
+func firstEnvValue(env map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value := env[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dbear/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestComposeImageType(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{"postgres", config.TypePostgreSQL},
+		{"postgres:16-alpine", config.TypePostgreSQL},
+		{"docker.io/library/postgres:16", config.TypePostgreSQL},
+		{"postgis/postgis:16-3.4", config.TypePostgreSQL},
+		{"timescale/timescaledb-ha:pg16", config.TypePostgreSQL},
+		{"pgvector/pgvector:pg16", config.TypePostgreSQL},
+		{"bitnami/postgresql@sha256:0123", config.TypePostgreSQL},
+		{"registry.example.com:5000/postgres", config.TypePostgreSQL},
+		{"MySQL:8", config.TypeMySQL},
+		{"mariadb:11", config.TypeMySQL},
+		{"percona/percona-server:8.0", config.TypeMySQL},
+		{"redis:7", ""},
+		{"registry.example.com:5000/app", ""},
+		{"postgres-exporter", ""},
+	}
+
+	for _, test := range tests {
+		if dbType := composeImageType(test.image); dbType != test.expected {
+			t.Errorf("%s: got type %q, expected %q", test.image, dbType, test.expected)
+		}
+	}
+}
+
+func TestComposePort(t *testing.T) {
+	tests := []struct {
+		yaml      string
+		target    string
+		published string
+	}{
+		{`"5432"`, "5432", ""},
+		{`"5433:5432"`, "5432", "5433"},
+		{`"127.0.0.1:5433:5432"`, "5432", "5433"},
+		{`"5433:5432/tcp"`, "5432", "5433"},
+		{`"5000-5010:5432"`, "5432", "5000-5010"},
+		{`"${PG_PORT}:5432"`, "5432", "${PG_PORT}"},
+		{"{target: 3306, published: 3307}", "3306", "3307"},
+		{"{target: 3306}", "3306", ""},
+	}
+
+	for _, test := range tests {
+		var port composePort
+		if err := yaml.Unmarshal([]byte(test.yaml), &port); err != nil {
+			t.Errorf("%s: %s", test.yaml, err)
+			continue
+		}
+		if port.Target != test.target || port.Published != test.published {
+			t.Errorf("%s: got target %q and published %q, expected %q and %q", test.yaml, port.Target, port.Published, test.target, test.published)
+		}
+	}
+}
+
+const composeProject = `name: shop
+services:
+  app:
+    image: shop/app
+    ports: ["8080:8080"]
+  db:
+    image: postgres:${PG_VERSION:-16}
+    env_file:
+      - db.env
+      - path: missing.env
+        required: false
+    environment:
+      POSTGRES_DB: shop
+      POSTGRES_PASSWORD:
+    ports:
+      - "${PG_PORT}:5432"
+  mysql:
+    image: mysql:8
+    environment:
+      - MYSQL_ROOT_PASSWORD=r00t
+    ports:
+      - target: 3306
+        published: 3307
+  maria:
+    image: mariadb:11
+    environment:
+      MARIADB_DATABASE: legacy
+      MARIADB_USER: legacy
+      MARIADB_PASSWORD: l3gacy
+    ports: ["3308:3306"]
+  internal:
+    image: postgres:16
+    expose: ["5432"]
+  random:
+    image: postgres:16
+    ports: ["5000-5010:5432"]
+`
+
+func TestComposeImport(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"compose.yaml": composeProject,
+		".env":         "PG_PORT=5433\nPOSTGRES_PASSWORD=s3cret\n",
+		"db.env":       "POSTGRES_USER=shop\nPOSTGRES_DB=ignored\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := NewComposeImporter()
+	connections, err := c.Import(filepath.Join(dir, "compose.yaml"), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []config.Connection{
+		{Name: "shop-db", Type: config.TypePostgreSQL, Host: "localhost", Port: 5433, Username: "shop", Password: "s3cret", Database: "shop"},
+		{Name: "shop-maria", Type: config.TypeMySQL, Host: "localhost", Port: 3308, Username: "legacy", Password: "l3gacy", Database: "legacy"},
+		{Name: "shop-mysql", Type: config.TypeMySQL, Host: "localhost", Port: 3307, Username: "root", Password: "r00t"},
+	}
+	if len(connections) != len(expected) {
+		t.Fatalf("got %d connections, expected %d: %+v", len(connections), len(expected), connections)
+	}
+	for i, conn := range connections {
+		want := expected[i]
+		if conn.Name != want.Name || conn.Type != want.Type || conn.Host != want.Host || conn.Port != want.Port ||
+			conn.Username != want.Username || conn.Password != want.Password || conn.Database != want.Database {
+			t.Errorf("got connection %+v, expected %+v", conn, want)
+		}
+	}
+
+	skipped := map[string]bool{}
+	for _, entry := range c.Skipped() {
+		skipped[entry.Name] = true
+	}
+	if len(skipped) != 2 || !skipped["internal"] || !skipped["random"] {
+		t.Fatalf("expected internal and random to be skipped, got %+v", c.Skipped())
+	}
+
+	mysqlOnly, err := c.Import(filepath.Join(dir, "compose.yaml"), ImportOptions{ConnectionName: "dev", DatabaseType: config.TypeMySQL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mysqlOnly) != 2 || mysqlOnly[0].Name != "dev-maria" || mysqlOnly[1].Name != "dev-mysql" {
+		t.Fatalf("unexpected connections for a mysql import: %+v", mysqlOnly)
+	}
+}
+
+func TestComposeImportRequiresEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+	compose := `services:
+  db:
+    image: postgres
+    env_file: db.env
+    ports: ["5432:5432"]
+`
+	if err := os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte(compose), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewComposeImporter().Import(filepath.Join(dir, "compose.yaml"), ImportOptions{}); err == nil {
+		t.Fatal("expected an error for a missing required env_file")
+	}
+}
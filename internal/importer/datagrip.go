@@ -0,0 +1,161 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dbear/internal/config"
+)
+
+// DataGripImporter implements the Importer interface for the dataSources.xml
+// of a JetBrains project (DataGrip, IntelliJ, GoLand...). User names are read
+// from the dataSources.local.xml next to it; passwords live in the system
+// keychain and are never available. Data source groups become connection tags.
+type DataGripImporter struct {
+	skipped []SkippedEntry
+}
+
+type dataGripProject struct {
+	Components []struct {
+		DataSources []dataGripDataSource `xml:"data-source"`
+	} `xml:"component"`
+}
+
+type dataGripDataSource struct {
+	Name      string `xml:"name,attr"`
+	UUID      string `xml:"uuid,attr"`
+	Group     string `xml:"group,attr"`
+	DriverRef string `xml:"driver-ref"`
+	JDBCURL   string `xml:"jdbc-url"`
+	UserName  string `xml:"user-name"`
+	SSH       struct {
+		Enabled bool `xml:"enabled"`
+	} `xml:"ssh-properties"`
+	SSL struct {
+		Enabled    bool   `xml:"enabled"`
+		Mode       string `xml:"mode"`
+		CACert     string `xml:"ca-cert"`
+		ClientCert string `xml:"client-cert"`
+		ClientKey  string `xml:"client-key"`
+	} `xml:"ssl-config"`
+}
+
+// NewDataGripImporter creates a new DataGripImporter instance
+func NewDataGripImporter() *DataGripImporter {
+	return &DataGripImporter{}
+}
+
+// Skipped returns the data sources the last Import call could not convert
+func (d *DataGripImporter) Skipped() []SkippedEntry {
+	return d.skipped
+}
+
+// Import imports connections from a JetBrains dataSources.xml file
+func (d *DataGripImporter) Import(filePath string, options ImportOptions) ([]config.Connection, error) {
+	d.skipped = nil
+
+	sources, err := readDataGripSources(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The local file is optional and only adds user names and TLS settings
+	localPath := filepath.Join(filepath.Dir(filePath), "dataSources.local.xml")
+	localSources, err := readDataGripSources(localPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	local := make(map[string]dataGripDataSource)
+	for _, source := range localSources {
+		local[source.UUID] = source
+	}
+
+	// $PROJECT_DIR$ is the directory holding .idea
+	projectDir, err := filepath.Abs(filepath.Dir(filepath.Dir(filePath)))
+	if err != nil {
+		return nil, err
+	}
+
+	connections := []config.Connection{}
+	for _, source := range sources {
+		conn, err := dataGripConnection(source, local[source.UUID], projectDir)
+		if err == nil {
+			err = checkPassword(conn, options, "stored in the system keychain")
+		}
+		if err != nil {
+			d.skipped = append(d.skipped, SkippedEntry{Name: source.Name, Reason: err.Error()})
+			continue
+		}
+		if options.DatabaseType != "" && conn.Type != options.DatabaseType {
+			continue
+		}
+
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
+
+// readDataGripSources returns the data sources declared in a JetBrains XML file
+func readDataGripSources(filePath string) ([]dataGripDataSource, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var project dataGripProject
+	if err := xml.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(filePath), err)
+	}
+
+	sources := []dataGripDataSource{}
+	for _, component := range project.Components {
+		sources = append(sources, component.DataSources...)
+	}
+	return sources, nil
+}
+
+// dataGripConnection converts one data source, merged with its local settings
+func dataGripConnection(source, local dataGripDataSource, projectDir string) (config.Connection, error) {
+	if driverType(source.DriverRef) == "" {
+		return config.Connection{}, fmt.Errorf("unsupported driver %s", source.DriverRef)
+	}
+	if source.SSH.Enabled || local.SSH.Enabled {
+		return config.Connection{}, fmt.Errorf("connects through an SSH tunnel, which is not supported")
+	}
+
+	jdbcURL := strings.NewReplacer("$PROJECT_DIR$", projectDir, "$ProjectFileDir$", projectDir).Replace(source.JDBCURL)
+	conn, err := parseJDBCURL(jdbcURL, projectDir)
+	if err != nil {
+		return config.Connection{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	conn.Name = source.Name
+	if source.Group != "" {
+		conn.Tags = []string{source.Group}
+	}
+	if conn.Type == config.TypeSQLite {
+		return conn, nil
+	}
+
+	conn.Username = firstNonEmpty(local.UserName, source.UserName, conn.Username)
+
+	ssl := source.SSL
+	if local.SSL.Enabled {
+		ssl = local.SSL
+	}
+	if ssl.Enabled {
+		conn.SSLMode = strings.ReplaceAll(strings.ToLower(ssl.Mode), "_", "-")
+		if conn.SSLMode == "" || !config.IsValidSSLMode(conn.SSLMode) {
+			conn.SSLMode = config.SSLModeRequire
+		}
+		conn.SSLRootCert = ssl.CACert
+		conn.SSLCert = ssl.ClientCert
+		conn.SSLKey = ssl.ClientKey
+	}
+
+	return conn, nil
+}
@@ -0,0 +1,118 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dbear/internal/config"
+)
+
+const dataGripSourcesXML = `<?xml version="1.0" encoding="UTF-8"?>
+<project version="4">
+  <component name="DataSourceManagerImpl" format="xml" multifile-model="true">
+    <data-source source="LOCAL" name="shop@db" uuid="1111" group="Production">
+      <driver-ref>postgresql</driver-ref>
+      <jdbc-url>jdbc:postgresql://db.example.com:5433/shop</jdbc-url>
+    </data-source>
+    <data-source source="LOCAL" name="local.db" uuid="2222">
+      <driver-ref>sqlite.xerial</driver-ref>
+      <jdbc-url>jdbc:sqlite:$PROJECT_DIR$/data/local.db</jdbc-url>
+    </data-source>
+    <data-source source="LOCAL" name="legacy" uuid="3333">
+      <driver-ref>mysql.8</driver-ref>
+      <jdbc-url>jdbc:mysql://localhost:3307/legacy</jdbc-url>
+    </data-source>
+    <data-source source="LOCAL" name="tunnelled" uuid="4444">
+      <driver-ref>postgresql</driver-ref>
+      <jdbc-url>jdbc:postgresql://10.0.0.5/app</jdbc-url>
+    </data-source>
+    <data-source source="LOCAL" name="warehouse" uuid="5555">
+      <driver-ref>redshift</driver-ref>
+      <jdbc-url>jdbc:redshift://warehouse:5439/dev</jdbc-url>
+    </data-source>
+  </component>
+</project>
+`
+
+const dataGripLocalXML = `<?xml version="1.0" encoding="UTF-8"?>
+<project version="4">
+  <component name="dataSourceStorageLocal">
+    <data-source name="shop@db" uuid="1111">
+      <user-name>admin</user-name>
+      <ssl-config>
+        <enabled>true</enabled>
+        <mode>VERIFY_FULL</mode>
+        <ca-cert>/etc/ssl/ca.pem</ca-cert>
+      </ssl-config>
+    </data-source>
+    <data-source name="tunnelled" uuid="4444">
+      <ssh-properties>
+        <enabled>true</enabled>
+      </ssh-properties>
+    </data-source>
+  </component>
+</project>
+`
+
+func TestDataGripImport(t *testing.T) {
+	projectDir := t.TempDir()
+	ideaDir := filepath.Join(projectDir, ".idea")
+	if err := os.Mkdir(ideaDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"dataSources.xml":       dataGripSourcesXML,
+		"dataSources.local.xml": dataGripLocalXML,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(ideaDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := NewDataGripImporter()
+	connections, err := d.Import(filepath.Join(ideaDir, "dataSources.xml"), ImportOptions{AllowMissingPasswords: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []config.Connection{
+		{
+			Name: "shop@db", Type: config.TypePostgreSQL, Host: "db.example.com", Port: 5433, Database: "shop", Username: "admin",
+			SSLMode: config.SSLModeVerifyFull, SSLRootCert: "/etc/ssl/ca.pem", Tags: []string{"Production"},
+		},
+		{Name: "local.db", Type: config.TypeSQLite, Database: filepath.Join(projectDir, "data", "local.db")},
+		{Name: "legacy", Type: config.TypeMySQL, Host: "localhost", Port: 3307, Database: "legacy"},
+	}
+	if len(connections) != len(expected) {
+		t.Fatalf("got %d connections, expected %d: %+v", len(connections), len(expected), connections)
+	}
+	for i, conn := range connections {
+		want := expected[i]
+		if conn.Name != want.Name || conn.Type != want.Type || conn.Host != want.Host || conn.Port != want.Port ||
+			conn.Database != want.Database || conn.Username != want.Username || conn.SSLMode != want.SSLMode ||
+			conn.SSLRootCert != want.SSLRootCert || strings.Join(conn.Tags, ",") != strings.Join(want.Tags, ",") {
+			t.Errorf("got connection %+v, expected %+v", conn, want)
+		}
+	}
+
+	skipped := map[string]string{}
+	for _, entry := range d.Skipped() {
+		skipped[entry.Name] = entry.Reason
+	}
+	if len(skipped) != 2 || !strings.Contains(skipped["tunnelled"], "SSH") || !strings.Contains(skipped["warehouse"], "unsupported driver") {
+		t.Fatalf("unexpected skipped data sources: %v", skipped)
+	}
+
+	// Without --allow-missing-passwords, a user whose password is in the
+	// keychain cannot be imported
+	strict, err := d.Import(filepath.Join(ideaDir, "dataSources.xml"), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(strict) != 2 || strict[0].Name != "local.db" || strict[1].Name != "legacy" {
+		t.Fatalf("unexpected connections without missing passwords: %+v", strict)
+	}
+}
@@ -0,0 +1,251 @@
+package importer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dbear/internal/config"
+)
+
+// dbeaverCredentialsKey is the fixed AES key DBeaver encrypts
+// credentials-config.json with when no master password is set
+const dbeaverCredentialsKey = "babb4a9f774ab853c96c2d653dfe544a"
+
+// DBeaverImporter implements the Importer interface for DBeaver's
+// data-sources.json. Credentials are read from the credentials-config.json
+// stored next to it. Folders become connection tags.
+type DBeaverImporter struct {
+	skipped []SkippedEntry
+}
+
+type dbeaverDataSources struct {
+	Connections map[string]dbeaverConnection `json:"connections"`
+}
+
+type dbeaverConnection struct {
+	Provider      string               `json:"provider"`
+	Driver        string               `json:"driver"`
+	Name          string               `json:"name"`
+	Folder        string               `json:"folder"`
+	Configuration dbeaverConfiguration `json:"configuration"`
+}
+
+type dbeaverConfiguration struct {
+	Host     string                    `json:"host"`
+	Port     any                       `json:"port"`
+	Database string                    `json:"database"`
+	URL      string                    `json:"url"`
+	User     string                    `json:"user"`
+	Password string                    `json:"password"`
+	Handlers map[string]dbeaverHandler `json:"handlers"`
+}
+
+type dbeaverHandler struct {
+	Type       string            `json:"type"`
+	Enabled    bool              `json:"enabled"`
+	Properties map[string]string `json:"properties"`
+}
+
+type dbeaverCredentials struct {
+	Connection struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	} `json:"#connection"`
+}
+
+// NewDBeaverImporter creates a new DBeaverImporter instance
+func NewDBeaverImporter() *DBeaverImporter {
+	return &DBeaverImporter{}
+}
+
+// DBeaverPaths returns the data-sources.json locations of the default DBeaver
+// workspace on Linux, macOS and Windows
+func DBeaverPaths() []string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{
+		filepath.Join(homeDir, ".local/share/DBeaverData/workspace6/General/.dbeaver/data-sources.json"),
+		filepath.Join(homeDir, "Library/DBeaverData/workspace6/General/.dbeaver/data-sources.json"),
+		filepath.Join(homeDir, "AppData/Roaming/DBeaverData/workspace6/General/.dbeaver/data-sources.json"),
+	}
+}
+
+// Skipped returns the connections the last Import call could not convert
+func (d *DBeaverImporter) Skipped() []SkippedEntry {
+	return d.skipped
+}
+
+// Import imports connections from a DBeaver data-sources.json file
+func (d *DBeaverImporter) Import(filePath string, options ImportOptions) ([]config.Connection, error) {
+	d.skipped = nil
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var sources dbeaverDataSources
+	if err := json.Unmarshal(data, &sources); err != nil {
+		return nil, fmt.Errorf("failed to parse DBeaver data sources: %w", err)
+	}
+
+	credentials, err := readDBeaverCredentials(filepath.Join(filepath.Dir(filePath), "credentials-config.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(sources.Connections))
+	for id := range sources.Connections {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	baseDir := filepath.Dir(filePath)
+	connections := []config.Connection{}
+	for _, id := range ids {
+		source := sources.Connections[id]
+		conn, err := dbeaverConnectionFor(source, credentials[id], baseDir)
+		if err == nil {
+			err = checkPassword(conn, options, "not saved in DBeaver or kept in secure storage")
+		}
+		if err != nil {
+			d.skipped = append(d.skipped, SkippedEntry{Name: source.Name, Reason: err.Error()})
+			continue
+		}
+		if options.DatabaseType != "" && conn.Type != options.DatabaseType {
+			continue
+		}
+
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
+
+// dbeaverConnectionFor converts one DBeaver data source
+func dbeaverConnectionFor(source dbeaverConnection, credentials dbeaverCredentials, baseDir string) (config.Connection, error) {
+	dbType := driverType(source.Provider)
+	if dbType == "" {
+		dbType = driverType(source.Driver)
+	}
+	if dbType == "" {
+		return config.Connection{}, fmt.Errorf("unsupported driver %s", source.Driver)
+	}
+
+	settings := source.Configuration
+	for id, handler := range settings.Handlers {
+		if handler.Enabled && handler.Type == "TUNNEL" {
+			return config.Connection{}, fmt.Errorf("connects through %s, which is not supported", id)
+		}
+	}
+
+	var conn config.Connection
+	if dbType == config.TypeSQLite || (settings.Host == "" && settings.URL != "") {
+		parsed, err := parseJDBCURL(settings.URL, baseDir)
+		if err != nil {
+			return config.Connection{}, fmt.Errorf("invalid URL: %w", err)
+		}
+		conn = parsed
+	} else {
+		conn = config.Connection{
+			Type:     dbType,
+			Host:     settings.Host,
+			Database: settings.Database,
+		}
+		port := strings.TrimSpace(fmt.Sprint(settings.Port))
+		if settings.Port != nil && port != "" {
+			parsed, err := strconv.Atoi(port)
+			if err != nil {
+				return config.Connection{}, fmt.Errorf("invalid port %s", port)
+			}
+			conn.Port = parsed
+		} else if dbType == config.TypePostgreSQL {
+			conn.Port = 5432
+		} else {
+			conn.Port = 3306
+		}
+	}
+
+	conn.Name = source.Name
+	if source.Folder != "" {
+		conn.Tags = []string{source.Folder}
+	}
+
+	if conn.Type != config.TypeSQLite {
+		conn.Username = firstNonEmpty(credentials.Connection.User, settings.User, conn.Username)
+		conn.Password = firstNonEmpty(credentials.Connection.Password, settings.Password, conn.Password)
+		applyDBeaverSSL(&conn, settings.Handlers)
+	}
+
+	return conn, nil
+}
+
+// applyDBeaverSSL copies the settings of an enabled PostgreSQL or MySQL SSL
+// handler
+func applyDBeaverSSL(conn *config.Connection, handlers map[string]dbeaverHandler) {
+	for id, handler := range handlers {
+		if !handler.Enabled || !strings.HasSuffix(id, "_ssl") {
+			continue
+		}
+
+		properties := handler.Properties
+		conn.SSLRootCert = firstNonEmpty(properties["ssl.root.cert"], properties["ssl.ca.cert"], properties["sslRootCert"])
+		conn.SSLCert = firstNonEmpty(properties["ssl.client.cert"], properties["sslCert"])
+		conn.SSLKey = firstNonEmpty(properties["ssl.client.key"], properties["sslKey"])
+
+		mode := firstNonEmpty(properties["ssl.mode"], properties["sslMode"])
+		switch {
+		case mode != "" && config.IsValidSSLMode(mode):
+			conn.SSLMode = mode
+		case properties["ssl.verify.server"] == "true":
+			conn.SSLMode = config.SSLModeVerifyCA
+		default:
+			conn.SSLMode = config.SSLModeRequire
+		}
+	}
+}
+
+// readDBeaverCredentials decrypts a credentials-config.json file into
+// credentials by data source id. A missing file yields no credentials.
+func readDBeaverCredentials(filePath string) (map[string]dbeaverCredentials, error) {
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DBeaver credentials: %w", err)
+	}
+
+	key, _ := hex.DecodeString(dbeaverCredentialsKey)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2*aes.BlockSize || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("failed to decrypt DBeaver credentials: unexpected file size")
+	}
+
+	plain := make([]byte, len(data)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, data[:aes.BlockSize]).CryptBlocks(plain, data[aes.BlockSize:])
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, fmt.Errorf("failed to decrypt DBeaver credentials: a master password may be set")
+	}
+	plain = plain[:len(plain)-padding]
+
+	credentials := make(map[string]dbeaverCredentials)
+	if err := json.Unmarshal(plain, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to decrypt DBeaver credentials: %w", err)
+	}
+	return credentials, nil
+}
@@ -0,0 +1,172 @@
+package importer
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dbear/internal/config"
+)
+
+const dbeaverDataSourcesJSON = `{
+  "folders": {"Production": {}},
+  "connections": {
+    "postgres-jdbc-1": {
+      "provider": "postgresql",
+      "driver": "postgres-jdbc",
+      "name": "Shop",
+      "folder": "Production",
+      "configuration": {
+        "host": "db.example.com",
+        "port": "5433",
+        "database": "shop",
+        "handlers": {
+          "postgre_ssl": {
+            "type": "CONFIG",
+            "enabled": true,
+            "properties": {"ssl.mode": "verify-full", "ssl.root.cert": "/etc/ssl/ca.pem"}
+          }
+        }
+      }
+    },
+    "mysql8-2": {
+      "provider": "mysql",
+      "driver": "mysql8",
+      "name": "Legacy",
+      "configuration": {"host": "localhost", "database": "legacy", "user": "root", "password": "plain"}
+    },
+    "sqlite-3": {
+      "provider": "generic",
+      "driver": "sqlite_jdbc",
+      "name": "Local",
+      "configuration": {"url": "jdbc:sqlite:local.db"}
+    },
+    "postgres-jdbc-4": {
+      "provider": "postgresql",
+      "driver": "postgres-jdbc",
+      "name": "Tunnelled",
+      "configuration": {
+        "host": "10.0.0.5",
+        "user": "admin",
+        "password": "s3cret",
+        "handlers": {"ssh_tunnel": {"type": "TUNNEL", "enabled": true}}
+      }
+    },
+    "postgres-jdbc-5": {
+      "provider": "postgresql",
+      "driver": "postgres-jdbc",
+      "name": "Keychain",
+      "configuration": {"host": "localhost", "user": "admin"}
+    },
+    "oracle-6": {
+      "provider": "oracle",
+      "driver": "oracle_thin",
+      "name": "Oracle",
+      "configuration": {"host": "localhost"}
+    }
+  }
+}`
+
+// encryptDBeaverCredentials encrypts credentials the way DBeaver writes
+// credentials-config.json without a master password
+func encryptDBeaverCredentials(t *testing.T, credentials string) []byte {
+	t.Helper()
+	key, _ := hex.DecodeString(dbeaverCredentialsKey)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	padding := aes.BlockSize - len(credentials)%aes.BlockSize
+	plain := append([]byte(credentials), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	iv := bytes.Repeat([]byte{7}, aes.BlockSize)
+	encrypted := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, plain)
+	return append(iv, encrypted...)
+}
+
+func TestDBeaverImport(t *testing.T) {
+	dir := t.TempDir()
+	credentials := `{"postgres-jdbc-1": {"#connection": {"user": "admin", "password": "s3cret"}}}`
+	files := map[string][]byte{
+		"data-sources.json":       []byte(dbeaverDataSourcesJSON),
+		"credentials-config.json": encryptDBeaverCredentials(t, credentials),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := NewDBeaverImporter()
+	connections, err := d.Import(filepath.Join(dir, "data-sources.json"), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []config.Connection{
+		{Name: "Legacy", Type: config.TypeMySQL, Host: "localhost", Port: 3306, Database: "legacy", Username: "root", Password: "plain"},
+		{
+			Name: "Shop", Type: config.TypePostgreSQL, Host: "db.example.com", Port: 5433, Database: "shop", Username: "admin", Password: "s3cret",
+			SSLMode: config.SSLModeVerifyFull, SSLRootCert: "/etc/ssl/ca.pem", Tags: []string{"Production"},
+		},
+		{Name: "Local", Type: config.TypeSQLite, Database: filepath.Join(dir, "local.db")},
+	}
+	if len(connections) != len(expected) {
+		t.Fatalf("got %d connections, expected %d: %+v", len(connections), len(expected), connections)
+	}
+	for i, conn := range connections {
+		want := expected[i]
+		if conn.Name != want.Name || conn.Type != want.Type || conn.Host != want.Host || conn.Port != want.Port ||
+			conn.Database != want.Database || conn.Username != want.Username || conn.Password != want.Password ||
+			conn.SSLMode != want.SSLMode || conn.SSLRootCert != want.SSLRootCert || strings.Join(conn.Tags, ",") != strings.Join(want.Tags, ",") {
+			t.Errorf("got connection %+v, expected %+v", conn, want)
+		}
+	}
+
+	skipped := map[string]string{}
+	for _, entry := range d.Skipped() {
+		skipped[entry.Name] = entry.Reason
+	}
+	expectedSkipped := map[string]string{
+		"Tunnelled": "ssh_tunnel",
+		"Keychain":  "missing password",
+		"Oracle":    "unsupported driver",
+	}
+	if len(skipped) != len(expectedSkipped) {
+		t.Fatalf("got skipped %v, expected %v", skipped, expectedSkipped)
+	}
+	for name, reason := range expectedSkipped {
+		if !strings.Contains(skipped[name], reason) {
+			t.Errorf("%s skipped with %q, expected it to mention %q", name, skipped[name], reason)
+		}
+	}
+
+	allowed, err := d.Import(filepath.Join(dir, "data-sources.json"), ImportOptions{DatabaseType: config.TypePostgreSQL, AllowMissingPasswords: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(allowed) != 2 || allowed[0].Name != "Shop" || allowed[1].Name != "Keychain" {
+		t.Fatalf("unexpected postgresql connections with missing passwords allowed: %+v", allowed)
+	}
+}
+
+func TestReadDBeaverCredentialsRejectsMasterPassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials-config.json")
+	if err := os.WriteFile(path, bytes.Repeat([]byte{1}, 3*aes.BlockSize+1), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readDBeaverCredentials(path); err == nil {
+		t.Fatal("expected an error for a file DBeaver did not encrypt with its default key")
+	}
+
+	missing, err := readDBeaverCredentials(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || missing != nil {
+		t.Fatalf("expected no credentials for a missing file, got %v, %v", missing, err)
+	}
+}
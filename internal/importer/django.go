@@ -0,0 +1,719 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+)
+
+// DjangoImporter implements the Importer interface for Django settings modules.
+// It reads the DATABASES literal of settings.py and proposes one connection per
+// database alias. Settings may be literals or environment lookups; aliases
+// using any other expression, such as BASE_DIR / "db.sqlite3", are skipped.
+type DjangoImporter struct {
+	skipped []SkippedEntry
+}
+
+// NewDjangoImporter creates a new DjangoImporter instance
+func NewDjangoImporter() *DjangoImporter {
+	return &DjangoImporter{}
+}
+
+var djangoDatabasesPattern = regexp.MustCompile(`(?m)^DATABASES\s*=`)
+
+// Skipped returns the database aliases the last Import call could not convert
+func (d *DjangoImporter) Skipped() []SkippedEntry {
+	return d.skipped
+}
+
+// Import imports connections from a Django settings.py file
+func (d *DjangoImporter) Import(filePath string, options ImportOptions) ([]config.Connection, error) {
+	d.skipped = nil
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	source := string(data)
+	loc := djangoDatabasesPattern.FindStringIndex(source)
+	if loc == nil {
+		return nil, fmt.Errorf("no DATABASES setting found in %s", filePath)
+	}
+
+	rootDir := djangoRoot(filePath)
+	lookup, err := ProjectLookup(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	source = source[loc[1]:]
+	parser := &pyParser{source: source, tokens: tokenizePython(source), lookup: lookup}
+	value, err := parser.parseTerm()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DATABASES: %w", err)
+	}
+
+	databases, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("DATABASES is not a dict literal")
+	}
+
+	prefix := options.ConnectionName
+	if prefix == "" {
+		prefix = projectName(rootDir)
+	}
+
+	aliases := make([]string, 0, len(databases))
+	for alias := range databases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	connections := []config.Connection{}
+	for _, alias := range aliases {
+		conn, err := djangoConnection(databases[alias], rootDir)
+		if err != nil {
+			d.skipped = append(d.skipped, SkippedEntry{Name: alias, Reason: err.Error()})
+			continue
+		}
+		if options.DatabaseType != "" && conn.Type != options.DatabaseType {
+			continue
+		}
+
+		conn.Name = alias
+		if prefix != "" {
+			conn.Name = prefix
+			if alias != "default" {
+				conn.Name = prefix + "-" + alias
+			}
+		}
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
+
+// djangoRoot returns the project root of a settings file: the nearest parent
+// directory holding manage.py, or the parent of the settings package.
+func djangoRoot(filePath string) string {
+	settingsDir, err := filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		return filepath.Dir(filePath)
+	}
+
+	for dir := settingsDir; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "manage.py")); err == nil {
+			return dir
+		}
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	return filepath.Dir(settingsDir)
+}
+
+func djangoConnection(value any, rootDir string) (config.Connection, error) {
+	if expression, ok := value.(pyUnsupported); ok {
+		return config.Connection{}, unsupportedError("the alias", expression)
+	}
+	if dbURL, ok := value.(pyDatabaseURL); ok {
+		if dbURL == "" {
+			return config.Connection{}, fmt.Errorf("database url is not set")
+		}
+		conn, err := parseConnectionString(string(dbURL), "")
+		if err != nil {
+			return config.Connection{}, err
+		}
+		if conn.Type == config.TypeSQLite {
+			path, err := connection.ExpandPath(conn.Database, rootDir)
+			if err != nil {
+				return config.Connection{}, err
+			}
+			conn.Database = path
+		}
+		return conn, nil
+	}
+
+	settings, ok := value.(map[string]any)
+	if !ok {
+		return config.Connection{}, fmt.Errorf("database settings are not a dict literal")
+	}
+	for key, setting := range settings {
+		if strings.HasPrefix(key, "**") {
+			return config.Connection{}, fmt.Errorf("the settings unpack %s, which dbear cannot read", strings.TrimPrefix(key, "**"))
+		}
+		if expression, ok := setting.(pyUnsupported); ok && isDjangoSetting(key) {
+			return config.Connection{}, unsupportedError(key, expression)
+		}
+	}
+
+	engine := pyString(settings["ENGINE"])
+	conn := config.Connection{}
+	switch {
+	case strings.Contains(engine, "postgresql") || strings.Contains(engine, "postgis"):
+		conn.Type = config.TypePostgreSQL
+	case strings.Contains(engine, "mysql"):
+		conn.Type = config.TypeMySQL
+	case strings.Contains(engine, "sqlite3") || strings.Contains(engine, "spatialite"):
+		conn.Type = config.TypeSQLite
+	case engine == "":
+		return config.Connection{}, fmt.Errorf("no ENGINE")
+	default:
+		return config.Connection{}, fmt.Errorf("unsupported engine '%s'", engine)
+	}
+
+	name := pyString(settings["NAME"])
+	if conn.Type == config.TypeSQLite {
+		if name == "" {
+			return config.Connection{}, fmt.Errorf("no NAME for the database file")
+		}
+		path, err := connection.ExpandPath(name, rootDir)
+		if err != nil {
+			return config.Connection{}, err
+		}
+		conn.Database = path
+		return conn, nil
+	}
+
+	conn.Database = name
+	conn.Username = pyString(settings["USER"])
+	conn.Password = pyString(settings["PASSWORD"])
+
+	// Django, like libpq, treats a HOST starting with / as a socket directory
+	host := pyString(settings["HOST"])
+	if strings.HasPrefix(host, "/") {
+		conn.Socket = host
+	} else {
+		conn.Host = host
+	}
+	if conn.Host == "" && conn.Socket == "" {
+		conn.Host = "localhost"
+	}
+
+	if portStr := pyString(settings["PORT"]); portStr != "" {
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return config.Connection{}, fmt.Errorf("invalid PORT '%s'", portStr)
+		}
+		conn.Port = port
+	} else if conn.Type == config.TypeMySQL {
+		conn.Port = 3306
+	} else {
+		conn.Port = 5432
+	}
+
+	if options, ok := settings["OPTIONS"].(map[string]any); ok {
+		if err := applyDjangoOptions(&conn, options); err != nil {
+			return config.Connection{}, err
+		}
+	}
+
+	return conn, nil
+}
+
+// applyDjangoOptions maps the OPTIONS that describe how to reach the database:
+// the TLS settings of psycopg and mysqlclient, the socket and the character
+// set. Others, such as init_command or isolation_level, configure the session
+// on the server and are left out, as the drivers would send them as
+// connection parameters.
+func applyDjangoOptions(conn *config.Connection, options map[string]any) error {
+	for key, optionValue := range options {
+		if expression, ok := optionValue.(pyUnsupported); ok {
+			return unsupportedError("OPTIONS "+key, expression)
+		}
+		// mysqlclient takes the certificate files as a dict
+		if ssl, ok := optionValue.(map[string]any); ok && key == "ssl" {
+			for sslKey, sslValue := range ssl {
+				if expression, ok := sslValue.(pyUnsupported); ok {
+					return unsupportedError("OPTIONS ssl "+sslKey, expression)
+				}
+				switch sslKey {
+				case "ca":
+					conn.SSLRootCert = pyString(sslValue)
+				case "cert":
+					conn.SSLCert = pyString(sslValue)
+				case "key":
+					conn.SSLKey = pyString(sslValue)
+				}
+			}
+			continue
+		}
+
+		text := pyString(optionValue)
+		if text == "" {
+			continue
+		}
+		switch key {
+		case "sslmode", "ssl_mode":
+			mode, err := parseSSLMode(text)
+			if err != nil {
+				return fmt.Errorf("OPTIONS %s: %w", key, err)
+			}
+			conn.SSLMode = mode
+		case "sslrootcert":
+			conn.SSLRootCert = text
+		case "sslcert":
+			conn.SSLCert = text
+		case "sslkey":
+			conn.SSLKey = text
+		case "unix_socket":
+			conn.Socket = text
+		case "charset":
+			if conn.Type == config.TypeMySQL {
+				if conn.Params == nil {
+					conn.Params = make(map[string]string)
+				}
+				conn.Params[key] = text
+			}
+		}
+	}
+	return nil
+}
+
+// isDjangoSetting tells whether key is one of the database settings dbear reads
+func isDjangoSetting(key string) bool {
+	switch key {
+	case "ENGINE", "NAME", "USER", "PASSWORD", "HOST", "PORT", "OPTIONS":
+		return true
+	}
+	return false
+}
+
+func unsupportedError(setting string, expression pyUnsupported) error {
+	return fmt.Errorf("%s is %s, which is neither a literal nor an environment lookup", setting, expression)
+}
+
+// pyDatabaseURL is the result of dj_database_url and django-environ helpers,
+// which build a settings dict from a database URL
+type pyDatabaseURL string
+
+// pyUnsupported is an expression dbear does not evaluate, as written in the
+// settings file
+type pyUnsupported string
+
+// pyString converts an evaluated literal to the string Django would end up using
+func pyString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type pyTokenKind int
+
+const (
+	pyTokenEOF pyTokenKind = iota
+	pyTokenName
+	pyTokenString
+	pyTokenNumber
+	pyTokenPunct
+)
+
+type pyToken struct {
+	kind pyTokenKind
+	text string
+	// start is the offset of the token in the source
+	start int
+}
+
+// tokenizePython splits Python source into the tokens of literal expressions.
+// Newlines are treated as whitespace, which is correct inside brackets and
+// harmless after the expression ends.
+func tokenizePython(source string) []pyToken {
+	tokens := []pyToken{}
+	i := 0
+	for i < len(source) {
+		c := source[i]
+		start := i
+		switch {
+		case c == '#':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\\':
+			i++
+		case c == '"' || c == '\'':
+			text, next := readPythonString(source, i)
+			tokens = append(tokens, pyToken{kind: pyTokenString, text: text, start: start})
+			i = next
+		case isVariableStart(c):
+			for i < len(source) && isVariableChar(source[i]) {
+				i++
+			}
+			word := source[start:i]
+			// String prefixes such as r'', b'' and f''
+			if i < len(source) && (source[i] == '"' || source[i] == '\'') && len(word) <= 2 && strings.Trim(strings.ToLower(word), "rbuf") == "" {
+				text, next := readPythonString(source, i)
+				tokens = append(tokens, pyToken{kind: pyTokenString, text: text, start: start})
+				i = next
+				continue
+			}
+			tokens = append(tokens, pyToken{kind: pyTokenName, text: word, start: start})
+		case c >= '0' && c <= '9':
+			for i < len(source) && (isVariableChar(source[i]) || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, pyToken{kind: pyTokenNumber, text: strings.ReplaceAll(source[start:i], "_", ""), start: start})
+		default:
+			if i+1 < len(source) && c == '*' && source[i+1] == '*' {
+				tokens = append(tokens, pyToken{kind: pyTokenPunct, text: "**", start: start})
+				i += 2
+				continue
+			}
+			tokens = append(tokens, pyToken{kind: pyTokenPunct, text: string(c), start: start})
+			i++
+		}
+	}
+	return append(tokens, pyToken{kind: pyTokenEOF, start: len(source)})
+}
+
+// readPythonString reads a quoted string starting at start, returning its
+// unescaped contents and the index after the closing quote
+func readPythonString(source string, start int) (string, int) {
+	quote := source[start]
+	delimiter := string(quote)
+	if strings.HasPrefix(source[start:], strings.Repeat(delimiter, 3)) {
+		delimiter = strings.Repeat(delimiter, 3)
+	}
+
+	var text strings.Builder
+	i := start + len(delimiter)
+	for i < len(source) {
+		if strings.HasPrefix(source[i:], delimiter) {
+			return text.String(), i + len(delimiter)
+		}
+		if source[i] == '\\' && i+1 < len(source) {
+			switch source[i+1] {
+			case 'n':
+				text.WriteByte('\n')
+			case 't':
+				text.WriteByte('\t')
+			default:
+				text.WriteByte(source[i+1])
+			}
+			i += 2
+			continue
+		}
+		text.WriteByte(source[i])
+		i++
+	}
+	return text.String(), i
+}
+
+// pyParser reads the values of DATABASES settings: literals, and lookups of
+// the environment through os.environ, os.getenv, python-decouple,
+// django-environ and dj_database_url. Other expressions are not evaluated
+// but kept as pyUnsupported.
+type pyParser struct {
+	source string
+	tokens []pyToken
+	pos    int
+	lookup LookupFunc
+}
+
+func (p *pyParser) peek() pyToken {
+	return p.tokens[p.pos]
+}
+
+func (p *pyParser) next() pyToken {
+	token := p.tokens[p.pos]
+	if token.kind != pyTokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+func (p *pyParser) accept(punct string) bool {
+	if token := p.peek(); token.kind == pyTokenPunct && token.text == punct {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *pyParser) expect(punct string) error {
+	if !p.accept(punct) {
+		return fmt.Errorf("expected '%s', found '%s'", punct, p.peek().text)
+	}
+	return nil
+}
+
+// atDelimiter tells whether the next token ends a value
+func (p *pyParser) atDelimiter() bool {
+	token := p.peek()
+	if token.kind == pyTokenEOF {
+		return true
+	}
+	return token.kind == pyTokenPunct && strings.Contains(",:)]}", token.text)
+}
+
+// parseValue parses a value of a dict, list or call. Values that are more
+// than a literal or a lookup, such as BASE_DIR / "db.sqlite3" or
+// os.getenv("HOST") or "localhost", are skipped and returned as pyUnsupported.
+func (p *pyParser) parseValue() (any, error) {
+	start := p.peek().start
+	value, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := value.(pyUnsupported); !ok && p.atDelimiter() {
+		return value, nil
+	}
+
+	// Skip to the end of the expression; a closing bracket at depth 0 belongs
+	// to the enclosing value
+	depth := 0
+	for depth > 0 || !p.atDelimiter() {
+		token := p.peek()
+		if token.kind == pyTokenEOF {
+			return nil, fmt.Errorf("unbalanced brackets")
+		}
+		if token.kind == pyTokenPunct {
+			switch token.text {
+			case "(", "[", "{":
+				depth++
+			case ")", "]", "}":
+				depth--
+			}
+		}
+		p.next()
+	}
+	return pyUnsupported(strings.Join(strings.Fields(p.source[start:p.peek().start]), " ")), nil
+}
+
+// parseTerm parses a literal or a lookup
+func (p *pyParser) parseTerm() (any, error) {
+	token := p.next()
+	switch token.kind {
+	case pyTokenString:
+		text := token.text
+		// Adjacent string literals are concatenated
+		for p.peek().kind == pyTokenString {
+			text += p.next().text
+		}
+		return text, nil
+	case pyTokenNumber:
+		return token.text, nil
+	case pyTokenName:
+		switch token.text {
+		case "True":
+			return true, nil
+		case "False":
+			return false, nil
+		case "None":
+			return nil, nil
+		}
+		return p.parseLookup(token.text)
+	case pyTokenPunct:
+		switch token.text {
+		case "{":
+			return p.parseDict()
+		case "[":
+			return p.parseSequence("]")
+		case "(":
+			return p.parseSequence(")")
+		case "-":
+			if number := p.peek(); number.kind == pyTokenNumber {
+				p.next()
+				return "-" + number.text, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unexpected '%s'", token.text)
+}
+
+// parseLookup parses what follows a name: environment lookups are resolved,
+// anything else, such as BASE_DIR, is unsupported
+func (p *pyParser) parseLookup(name string) (any, error) {
+	for p.accept(".") {
+		attr := p.next()
+		if attr.kind != pyTokenName {
+			return nil, fmt.Errorf("expected attribute name")
+		}
+		name += "." + attr.text
+	}
+
+	switch {
+	case p.accept("["):
+		key, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		if keyString, ok := key.(string); ok && (name == "os.environ" || name == "environ" || name == "env") {
+			value, _ := p.lookup(keyString)
+			return value, nil
+		}
+	case p.accept("("):
+		args, kwargs, err := p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+		return p.call(name, args, kwargs), nil
+	}
+	return pyUnsupported(name), nil
+}
+
+func (p *pyParser) parseDict() (any, error) {
+	dict := map[string]any{}
+	for !p.accept("}") {
+		if p.accept("**") {
+			// Dict unpacking merges another mapping we cannot see
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			expression := "**" + pyString(value)
+			dict[expression] = pyUnsupported(expression)
+		} else {
+			key, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			if keyString, ok := key.(string); ok {
+				dict[keyString] = value
+			}
+		}
+
+		if !p.accept(",") {
+			if err := p.expect("}"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return dict, nil
+}
+
+func (p *pyParser) parseSequence(closing string) (any, error) {
+	items := []any{}
+	for !p.accept(closing) {
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		if !p.accept(",") {
+			if err := p.expect(closing); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	// A parenthesized single expression is not a tuple
+	if closing == ")" && len(items) == 1 {
+		return items[0], nil
+	}
+	return items, nil
+}
+
+func (p *pyParser) parseArguments() ([]any, map[string]any, error) {
+	args := []any{}
+	kwargs := map[string]any{}
+	for !p.accept(")") {
+		token := p.peek()
+		if token.kind == pyTokenName && p.tokens[p.pos+1].kind == pyTokenPunct && p.tokens[p.pos+1].text == "=" {
+			p.pos += 2
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, nil, err
+			}
+			kwargs[token.text] = value
+		} else {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, nil, err
+			}
+			args = append(args, value)
+		}
+
+		if !p.accept(",") {
+			if err := p.expect(")"); err != nil {
+				return nil, nil, err
+			}
+			break
+		}
+	}
+	return args, kwargs, nil
+}
+
+// call evaluates the environment helpers: the value of a variable, or the
+// default given to the helper when it is not set
+func (p *pyParser) call(name string, args []any, kwargs map[string]any) any {
+	arg := func(index int, keyword string) (any, bool) {
+		if index >= 0 && index < len(args) {
+			return args[index], true
+		}
+		value, ok := kwargs[keyword]
+		return value, ok
+	}
+
+	// lookup returns the variable named by the argument at index or keyword,
+	// or by fallbackKey without one, and the default otherwise
+	lookup := func(index int, keyword, fallbackKey string, defaultIndex int) any {
+		key := fallbackKey
+		if value, ok := arg(index, keyword); ok {
+			keyString, isString := value.(string)
+			if !isString {
+				return pyUnsupported("")
+			}
+			key = keyString
+		}
+		if value, ok := p.lookup(key); ok {
+			return value
+		}
+		fallback, _ := arg(defaultIndex, "default")
+		return fallback
+	}
+
+	databaseURL := func(value any) any {
+		if text, ok := value.(string); ok || value == nil {
+			return pyDatabaseURL(text)
+		}
+		return pyUnsupported("")
+	}
+
+	switch name {
+	case "os.environ.get", "os.getenv", "environ.get":
+		return lookup(0, "key", "", 1)
+	case "env", "config", "decouple.config", "env.str", "env.int", "env.bool":
+		return lookup(0, "var", "", 1)
+	case "dj_database_url.config":
+		return databaseURL(lookup(-1, "env", "DATABASE_URL", 0))
+	case "dj_database_url.parse":
+		value, _ := arg(0, "url")
+		return databaseURL(value)
+	case "env.db", "env.db_url":
+		return databaseURL(lookup(0, "var", "DATABASE_URL", 1))
+	}
+	return pyUnsupported(name)
+}
@@ -0,0 +1,185 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dbear/internal/config"
+)
+
+const djangoSettings = `import os
+BASE_DIR = Path(__file__).resolve().parent.parent
+
+DATABASES = {
+    'default': {
+        'ENGINE': 'django.db.backends.postgresql',
+        'NAME': os.environ.get('DB_NAME', 'app'),
+        'USER': env('DB_USER', default='app'),
+        'PASSWORD': os.environ['DB_PASSWORD'],
+        'HOST': 'db.example.com',
+        'PORT': 5433,
+    },
+    'replica': {
+        'ENGINE': 'django.db.backends.postgresql',
+        'NAME': 'app',
+        'HOST': os.getenv('REPLICA_HOST') or 'localhost',
+    },
+    'lite': {'ENGINE': 'django.db.backends.sqlite3', 'NAME': BASE_DIR / 'db.sqlite3'},
+    'legacy': dj_database_url.config(default='mysql://app@localhost:3307/legacy'),
+    'shared': {**COMMON, 'NAME': 'shared'},
+    'custom': database_settings(),
+}
+`
+
+func TestDjangoImportReadsOnlyLiteralsAndLookups(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"manage.py":       "",
+		".env":            "DB_PASSWORD=s3cret\n",
+		"app/settings.py": djangoSettings,
+		"app/__init__.py": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := NewDjangoImporter()
+	connections, err := d.Import(filepath.Join(dir, "app", "settings.py"), ImportOptions{ConnectionName: "shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(connections) != 2 {
+		t.Fatalf("got %d connections, expected 2: %+v", len(connections), connections)
+	}
+	main, legacy := connections[0], connections[1]
+	if main.Name != "shop" || main.Database != "app" || main.Username != "app" || main.Password != "s3cret" || main.Host != "db.example.com" || main.Port != 5433 {
+		t.Fatalf("unexpected default connection: %+v", main)
+	}
+	if legacy.Name != "shop-legacy" || legacy.Port != 3307 || legacy.Database != "legacy" {
+		t.Fatalf("unexpected legacy connection: %+v", legacy)
+	}
+
+	skipped := map[string]string{}
+	for _, entry := range d.Skipped() {
+		skipped[entry.Name] = entry.Reason
+	}
+	expected := map[string]string{
+		"replica": "os.getenv('REPLICA_HOST') or 'localhost'",
+		"lite":    "BASE_DIR / 'db.sqlite3'",
+		"shared":  "COMMON",
+		"custom":  "database_settings()",
+	}
+	if len(skipped) != len(expected) {
+		t.Fatalf("got skipped %v, expected %v", skipped, expected)
+	}
+	for alias, expression := range expected {
+		if !strings.Contains(skipped[alias], expression) {
+			t.Fatalf("alias %s skipped with %q, expected it to mention %q", alias, skipped[alias], expression)
+		}
+	}
+}
+
+// writeDjangoProject writes settings.py and a manage.py next to it, returning
+// the path of settings.py
+func writeDjangoProject(t *testing.T, settings string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "manage.py"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "settings.py")
+	if err := os.WriteFile(path, []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDjangoImportRejectsTruncatedSettings(t *testing.T) {
+	tests := []string{
+		`DATABASES = {"default": {"NAME": BASE_DIR / ("x"`,
+		`DATABASES = {"default": {"NAME": os.path.join(BASE_DIR, [`,
+		`DATABASES = {"default": {"NAME": "app"`,
+		`DATABASES = {"default": get_settings(`,
+	}
+
+	for _, settings := range tests {
+		t.Run(settings, func(t *testing.T) {
+			done := make(chan error, 1)
+			go func() {
+				_, err := NewDjangoImporter().Import(writeDjangoProject(t, settings), ImportOptions{})
+				done <- err
+			}()
+
+			select {
+			case err := <-done:
+				if err == nil {
+					t.Fatal("expected an error for truncated settings")
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("import did not return on truncated settings")
+			}
+		})
+	}
+}
+
+func TestDjangoImportMapsOnlyConnectionOptions(t *testing.T) {
+	settings := `DATABASES = {
+    'default': {
+        'ENGINE': 'django.db.backends.postgresql',
+        'NAME': 'app',
+        'OPTIONS': {
+            'sslmode': 'verify-full',
+            'sslrootcert': '/etc/ssl/ca.pem',
+            'isolation_level': 3,
+            'options': '-c search_path=app',
+        },
+    },
+    'mysql': {
+        'ENGINE': 'django.db.backends.mysql',
+        'NAME': 'app',
+        'OPTIONS': {
+            'init_command': "SET sql_mode='STRICT_TRANS_TABLES'",
+            'charset': 'utf8mb4',
+            'ssl': {'ca': '/etc/ssl/ca.pem'},
+            'ssl_mode': 'REQUIRED',
+        },
+    },
+    'strict': {
+        'ENGINE': 'django.db.backends.postgresql',
+        'NAME': 'app',
+        'OPTIONS': {'sslmode': 'strict'},
+    },
+}
+`
+	d := NewDjangoImporter()
+	connections, err := d.Import(writeDjangoProject(t, settings), ImportOptions{ConnectionName: "shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(connections) != 2 {
+		t.Fatalf("got %d connections, expected 2: %+v", len(connections), connections)
+	}
+
+	pg, my := connections[0], connections[1]
+	if pg.SSLMode != config.SSLModeVerifyFull || pg.SSLRootCert != "/etc/ssl/ca.pem" || len(pg.Params) != 0 {
+		t.Fatalf("unexpected postgresql connection: %+v", pg)
+	}
+	if my.SSLMode != config.SSLModeRequire || my.SSLRootCert != "/etc/ssl/ca.pem" || len(my.Params) != 1 || my.Params["charset"] != "utf8mb4" {
+		t.Fatalf("unexpected mysql connection: %+v", my)
+	}
+
+	skipped := d.Skipped()
+	if len(skipped) != 1 || skipped[0].Name != "strict" || !strings.Contains(skipped[0].Reason, "unsupported sslmode") {
+		t.Fatalf("expected the strict alias to be skipped for its sslmode, got %+v", skipped)
+	}
+}
@@ -3,14 +3,18 @@ package importer
 import (
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"dbear/internal/config"
+	"dbear/internal/connection"
 )
 
 // EnvImporter implements the Importer interface for .env files
-type EnvImporter struct{}
+type EnvImporter struct {
+	skipped []SkippedEntry
+}
 
 // EnvImportOptions contains options specific to env file imports
 type EnvImportOptions struct {
@@ -18,6 +22,7 @@ type EnvImportOptions struct {
 	ConnectionStringKey string
 	HostKey             string
 	PortKey             string
+	SocketKey           string
 	DatabaseKey         string
 	UsernameKey         string
 	PasswordKey         string
@@ -39,17 +44,13 @@ func (e *EnvImporter) Import(filePath string, options ImportOptions) ([]config.C
 		ImportOptions: options,
 		HostKey:       "DB_HOST",
 		PortKey:       "DB_PORT",
+		SocketKey:     "DB_SOCKET",
 		DatabaseKey:   "DB_DATABASE",
 		UsernameKey:   "DB_USERNAME",
 		PasswordKey:   "DB_PASSWORD",
 	}
 
-	// Check if connection string mode is being used
-	if envOptions.ConnectionStringKey != "" {
-		return e.importFromConnectionString(envMap, envOptions)
-	}
-
-	return e.importFromVariables(envMap, envOptions)
+	return e.importFromEnvMap(envMap, filepath.Dir(filePath), envOptions)
 }
 
 // ImportWithOptions imports connections from an .env file with full env-specific options
@@ -59,12 +60,47 @@ func (e *EnvImporter) ImportWithOptions(filePath string, options EnvImportOption
 		return nil, err
 	}
 
+	return e.importFromEnvMap(envMap, filepath.Dir(filePath), options)
+}
+
+// importFromEnvMap imports connections from parsed env values. Relative SQLite
+// paths are resolved against baseDir, the directory of the env file.
+func (e *EnvImporter) importFromEnvMap(envMap map[string]string, baseDir string, options EnvImportOptions) ([]config.Connection, error) {
+	var connections []config.Connection
+	var err error
+
 	// Check if connection string mode is being used
 	if options.ConnectionStringKey != "" {
-		return e.importFromConnectionString(envMap, options)
+		connections, err = e.importFromConnectionString(envMap, options)
+	} else {
+		connections, err = e.importFromVariables(envMap, options)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return e.importFromVariables(envMap, options)
+	if err := resolveSQLitePaths(connections, baseDir); err != nil {
+		return nil, err
+	}
+
+	return connections, nil
+}
+
+// resolveSQLitePaths makes the file paths of SQLite connections absolute,
+// relative to baseDir
+func resolveSQLitePaths(connections []config.Connection, baseDir string) error {
+	for i := range connections {
+		if connections[i].Type != config.TypeSQLite {
+			continue
+		}
+		path, err := connection.ExpandPath(connections[i].Database, baseDir)
+		if err != nil {
+			return err
+		}
+		connections[i].Database = path
+	}
+
+	return nil
 }
 
 // importFromConnectionString imports a connection from a connection string in an env variable
@@ -123,7 +159,11 @@ func (e *EnvImporter) importFromVariables(envMap map[string]string, options EnvI
 		if database == "" {
 			return nil, fmt.Errorf("database key '%s' is required for SQLite", options.DatabaseKey)
 		}
-		host = "localhost"
+		return []config.Connection{{
+			Name:     options.ConnectionName,
+			Type:     options.DatabaseType,
+			Database: database,
+		}}, nil
 	}
 
 	conn := config.Connection{
@@ -131,6 +171,7 @@ func (e *EnvImporter) importFromVariables(envMap map[string]string, options EnvI
 		Type:     options.DatabaseType,
 		Host:     host,
 		Port:     port,
+		Socket:   GetEnvValue(envMap, options.SocketKey),
 		Database: database,
 		Username: username,
 		Password: password,
@@ -151,9 +192,9 @@ func parseConnectionString(connString string, defaultType string) (config.Connec
 
 	// Determine database type from scheme
 	switch {
-	case scheme == "postgres" || scheme == "postgresql":
+	case scheme == "postgres" || scheme == "postgresql" || scheme == "postgis":
 		dbType = config.TypePostgreSQL
-	case scheme == "mysql":
+	case scheme == "mysql" || scheme == "mysql2" || scheme == "trilogy" || scheme == "mariadb":
 		dbType = config.TypeMySQL
 	case scheme == "sqlite" || scheme == "sqlite3":
 		dbType = config.TypeSQLite
@@ -199,21 +240,118 @@ func parseConnectionString(connString string, defaultType string) (config.Connec
 		}
 	}
 
-	// Extract database name (path for SQLite, database name for others)
+	// SQLite URLs carry a file path rather than a host and database name:
+	// sqlite:relative.db, sqlite://relative/dir/file.db or sqlite:///absolute/file.db
+	if dbType == config.TypeSQLite {
+		dbPath := parsedURL.Opaque
+		if dbPath == "" {
+			dbPath = parsedURL.Host + parsedURL.Path
+		}
+		if dbPath == "" {
+			return config.Connection{}, fmt.Errorf("sqlite connection string has no database path")
+		}
+
+		conn.Database = dbPath
+		conn.Host = ""
+		conn.Port = 0
+		if parsedURL.Query().Get("mode") == "ro" {
+			conn.ReadOnly = true
+		}
+		return conn, nil
+	}
+
+	// Extract database name
 	if parsedURL.Path != "" {
 		// Remove leading slash
 		dbPath := strings.TrimPrefix(parsedURL.Path, "/")
 		conn.Database = dbPath
 	}
 
-	// SQLite specific handling
-	if dbType == config.TypeSQLite {
-		if conn.Host == "" {
-			conn.Host = "localhost"
+	// Extract driver parameters from the query string
+	for key, values := range parsedURL.Query() {
+		if len(values) == 0 {
+			continue
+		}
+		value := values[len(values)-1]
+
+		switch key {
+		case "sslmode", "ssl-mode":
+			mode, err := parseSSLMode(value)
+			if err != nil {
+				return config.Connection{}, err
+			}
+			conn.SSLMode = mode
+		case "sslrootcert":
+			conn.SSLRootCert = value
+		case "sslcert":
+			conn.SSLCert = value
+		case "sslkey":
+			conn.SSLKey = value
+		case "host":
+			// libpq URIs put socket directories in the host parameter
+			if strings.HasPrefix(value, "/") {
+				conn.Socket = value
+			} else {
+				conn.Host = value
+			}
+		default:
+			if frameworkParams[key] {
+				continue
+			}
+			if conn.Params == nil {
+				conn.Params = make(map[string]string)
+			}
+			conn.Params[key] = value
+		}
+	}
+
+	// Socket URLs have no host part to take a port from
+	if conn.Port == 0 {
+		switch dbType {
+		case config.TypePostgreSQL:
+			conn.Port = 5432
+		case config.TypeMySQL:
+			conn.Port = 3306
 		}
-		conn.Port = 0
 	}
 
 	return conn, nil
 }
 
+// frameworkParams are the query parameters ORMs and frameworks read from
+// their database URLs: connection pools, Prisma's schema and pgbouncer
+// switches and Rails adapter options. The drivers would send them to the
+// server, which rejects them.
+var frameworkParams = map[string]bool{
+	"schema":               true,
+	"connection_limit":     true,
+	"pool_timeout":         true,
+	"pgbouncer":            true,
+	"socket_timeout":       true,
+	"statement_cache_size": true,
+	"sslaccept":            true,
+	"pool":                 true,
+	"checkout_timeout":     true,
+	"idle_timeout":         true,
+	"reaping_frequency":    true,
+	"reconnect":            true,
+	"prepared_statements":  true,
+	"advisory_locks":       true,
+	"encoding":             true,
+}
+
+// parseSSLMode reads the sslmode of a connection URL: a libpq value, the
+// no-verify of node-postgres or a mysql --ssl-mode value
+func parseSSLMode(value string) (string, error) {
+	mode := strings.ToLower(strings.TrimSpace(value))
+	if config.IsValidSSLMode(mode) {
+		return mode, nil
+	}
+	if mode == "no-verify" {
+		return config.SSLModeRequire, nil
+	}
+	if mode := connection.SSLModeFromMySQL(value); mode != "" {
+		return mode, nil
+	}
+	return "", fmt.Errorf("unsupported sslmode '%s', expected disable, allow, prefer, require, verify-ca or verify-full", value)
+}
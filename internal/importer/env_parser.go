@@ -1,60 +1,330 @@
 package importer
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"strings"
 )
 
-// ParseEnvFile reads and parses an .env file, returning a map of key-value pairs
+// EnvSyntaxError reports a malformed line of an .env file
+type EnvSyntaxError struct {
+	Line    int
+	Message string
+}
+
+func (e *EnvSyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// ParseEnvFile reads and parses an .env file, returning a map of key-value pairs.
+//
+// It follows the dialect of docker compose's .env reader, which the Node
+// dotenv and dotenv-expand libraries share: optional "export " prefixes, "="
+// or ":" separators, comments on their own line or after a value preceded by
+// whitespace, single-quoted literal values, double-quoted values with \n, \t
+// and \" escapes, backtick quoted values, and quoted values spanning several
+// lines. $VAR and ${VAR} references in unquoted and double-quoted values are
+// expanded from the variables defined earlier in the file, then from the
+// process environment; a $NAME no variable answers to is kept as written. In
+// those values \$ and $$ stand for a literal dollar sign, while single-quoted
+// and backtick quoted values are never expanded. python-dotenv differs here:
+// it only expands ${VAR} and has no escape for a dollar sign, so a value
+// relying on either may read differently in a Python application. Keys
+// without a value are ignored.
 func ParseEnvFile(filePath string) (map[string]string, error) {
-	file, err := os.Open(filePath)
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open env file: %w", err)
 	}
-	defer file.Close()
 
-	envMap := make(map[string]string)
-	scanner := bufio.NewScanner(file)
+	envMap, err := parseEnv(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse env file %s: %w", filePath, err)
+	}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	return envMap, nil
+}
 
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+// GetEnvValue retrieves a value from the env map, returning empty string if not found
+func GetEnvValue(envMap map[string]string, key string) string {
+	return envMap[key]
+}
+
+// envParser walks the content of an .env file, tracking the current line
+type envParser struct {
+	data string
+	pos  int
+	line int
+}
+
+// parseEnv parses the content of an .env file
+func parseEnv(data string) (map[string]string, error) {
+	p := &envParser{data: strings.TrimPrefix(data, "\uFEFF"), line: 1}
+	envMap := make(map[string]string)
+	lookup := func(name string) (string, bool) {
+		if value, ok := envMap[name]; ok {
+			return value, true
 		}
+		return os.LookupEnv(name)
+	}
 
-		// Parse key=value format
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
+	for {
+		p.skipBlank()
+		if p.done() {
+			return envMap, nil
+		}
+		if p.peek() == '#' {
+			p.skipLine()
 			continue
 		}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+		startLine := p.line
+		key, value, ok, err := p.parseEntry(lookup)
+		if err != nil {
+			return nil, &EnvSyntaxError{Line: startLine, Message: err.Error()}
+		}
+		if ok {
+			envMap[key] = value
+		}
+	}
+}
+
+// parseEntry parses one KEY=value entry. ok is false for keys without a value.
+func (p *envParser) parseEntry(lookup LookupFunc) (string, string, bool, error) {
+	if strings.HasPrefix(p.data[p.pos:], "export") {
+		rest := p.data[p.pos+len("export"):]
+		if rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			p.pos += len("export")
+			p.skipSpaces()
+		}
+	}
+
+	start := p.pos
+	for !p.done() && isEnvKeyChar(p.peek()) {
+		p.pos++
+	}
+	key := p.data[start:p.pos]
+	if key == "" {
+		return "", "", false, fmt.Errorf("invalid key at %q", p.restOfLine())
+	}
+
+	p.skipSpaces()
+	if p.done() || p.peek() == '\n' || p.peek() == '\r' || p.peek() == '#' {
+		p.skipLine()
+		return key, "", false, nil
+	}
+	if p.peek() != '=' && p.peek() != ':' {
+		return "", "", false, fmt.Errorf("expected '=' after %s", key)
+	}
+	p.pos++
+	afterSeparator := p.pos
+	p.skipSpaces()
+
+	// KEY= # comment has an empty value
+	if !p.done() && p.peek() == '#' && p.pos > afterSeparator {
+		p.skipLine()
+		return key, "", true, nil
+	}
+
+	var value string
+	var err error
+	switch {
+	case p.done():
+		return key, "", true, nil
+	case p.peek() == '\'' || p.peek() == '`':
+		value, err = p.parseQuoted(p.peek())
+	case p.peek() == '"':
+		value, err = p.parseQuoted('"')
+		if err == nil {
+			value, err = Interpolate(keepUnknownReferences(value, lookup), lookup)
+		}
+	default:
+		// Like double-quoted values, unquoted ones escape a dollar sign with \$
+		unquoted := strings.ReplaceAll(p.parseUnquoted(), `\$`, "$$")
+		value, err = Interpolate(keepUnknownReferences(unquoted, lookup), lookup)
+		if err == nil {
+			return key, value, true, nil
+		}
+	}
+	if err != nil {
+		return "", "", false, err
+	}
+
+	// Only a comment may follow a quoted value
+	p.skipSpaces()
+	if !p.done() && p.peek() != '\n' && p.peek() != '\r' && p.peek() != '#' {
+		return "", "", false, fmt.Errorf("unexpected %q after quoted value of %s", p.restOfLine(), key)
+	}
+	p.skipLine()
+
+	return key, value, true, nil
+}
+
+// parseQuoted reads a value enclosed in quote, which may span several lines.
+// Double-quoted values support escape sequences; \$ is kept as $$ so that
+// interpolation leaves it alone.
+func (p *envParser) parseQuoted(quote byte) (string, error) {
+	p.pos++
+
+	var value strings.Builder
+	for !p.done() {
+		c := p.peek()
+		p.pos++
 
-		// Remove quotes if present
-		if len(value) >= 2 {
-			if (strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`)) ||
-				(strings.HasPrefix(value, `'`) && strings.HasSuffix(value, `'`)) {
-				value = value[1 : len(value)-1]
+		switch {
+		case c == quote:
+			return value.String(), nil
+		case c == '\n':
+			p.line++
+			value.WriteByte(c)
+		case c == '\\' && !p.done() && quote != '`':
+			next := p.peek()
+			if quote == '\'' {
+				// Single quotes only escape themselves and backslashes
+				if next == '\'' || next == '\\' {
+					value.WriteByte(next)
+					p.pos++
+				} else {
+					value.WriteByte(c)
+				}
+				continue
 			}
+
+			p.pos++
+			switch next {
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case '"', '\\':
+				value.WriteByte(next)
+			case '$':
+				value.WriteString("$$")
+			default:
+				value.WriteByte(c)
+				value.WriteByte(next)
+				if next == '\n' {
+					p.line++
+				}
+			}
+		default:
+			value.WriteByte(c)
 		}
+	}
 
-		envMap[key] = value
+	return "", fmt.Errorf("unterminated quoted value, missing closing %c", quote)
+}
+
+// keepUnknownReferences escapes the $NAME references of value to variables
+// lookup does not know, which Interpolate would drop, and the unterminated ${
+// it would fail on. Passwords such as pa$word hold a dollar sign far more
+// often than a reference to a missing variable; ${NAME} references are still
+// expanded.
+func keepUnknownReferences(value string, lookup LookupFunc) string {
+	if !strings.Contains(value, "$") {
+		return value
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("failed to read env file: %w", err)
+	var result strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		result.WriteByte(c)
+		if c != '$' || i+1 >= len(value) {
+			continue
+		}
+		if value[i+1] == '$' {
+			result.WriteByte('$')
+			i++
+			continue
+		}
+		if value[i+1] == '{' && matchingBrace(value, i+2) < 0 {
+			result.WriteByte('$')
+			continue
+		}
+		if !isVariableStart(value[i+1]) {
+			continue
+		}
+		end := i + 1
+		for end < len(value) && isVariableChar(value[end]) {
+			end++
+		}
+		if _, ok := lookup(value[i+1 : end]); !ok {
+			result.WriteByte('$')
+		}
 	}
+	return result.String()
+}
 
-	return envMap, nil
+// parseUnquoted reads a value up to the end of the line, dropping a trailing
+// comment, which must be preceded by whitespace
+func (p *envParser) parseUnquoted() string {
+	start := p.pos
+	p.skipLine()
+	value := strings.TrimRight(p.data[start:p.pos], "\r\n")
+
+	for i := 1; i < len(value); i++ {
+		if value[i] == '#' && (value[i-1] == ' ' || value[i-1] == '\t') {
+			value = value[:i]
+			break
+		}
+	}
+
+	return strings.TrimSpace(value)
 }
 
-// GetEnvValue retrieves a value from the env map, returning empty string if not found
-func GetEnvValue(envMap map[string]string, key string) string {
-	return envMap[key]
+func (p *envParser) done() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *envParser) peek() byte {
+	return p.data[p.pos]
+}
+
+// skipBlank skips whitespace, including line breaks
+func (p *envParser) skipBlank() {
+	for !p.done() {
+		switch p.peek() {
+		case '\n':
+			p.line++
+		case ' ', '\t', '\r':
+		default:
+			return
+		}
+		p.pos++
+	}
 }
 
+// skipSpaces skips spaces and tabs on the current line
+func (p *envParser) skipSpaces() {
+	for !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skipLine moves past the end of the current line
+func (p *envParser) skipLine() {
+	for !p.done() {
+		c := p.peek()
+		p.pos++
+		if c == '\n' {
+			p.line++
+			return
+		}
+	}
+}
+
+// restOfLine returns the text from the current position to the end of the line
+func (p *envParser) restOfLine() string {
+	rest := p.data[p.pos:]
+	if end := strings.IndexByte(rest, '\n'); end >= 0 {
+		rest = rest[:end]
+	}
+	return strings.TrimSpace(rest)
+}
+
+func isEnvKeyChar(c byte) bool {
+	return c == '_' || c == '.' || c == '-' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,47 @@
+package importer
+
+import "testing"
+
+func TestParseEnvKeepsDollarSignsOfPasswords(t *testing.T) {
+	t.Setenv("DBEAR_TEST_HOST", "db.example.com")
+
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{"DB_PASSWORD=pa$word", "pa$word"},
+		{"DB_PASSWORD=pa$word$", "pa$word$"},
+		{"DB_PASSWORD=pa$$word", "pa$word"},
+		{"DB_PASSWORD='pa$word'", "pa$word"},
+		{`DB_PASSWORD="pa\$word"`, "pa$word"},
+		{`DB_PASSWORD="pa$word"`, "pa$word"},
+		{`DB_PASSWORD="pa$$word"`, "pa$word"},
+		{`DB_PASSWORD=pa\$word`, "pa$word"},
+		{`DB_PASSWORD=\$DBEAR_TEST_HOST`, "$DBEAR_TEST_HOST"},
+		{`DB_PASSWORD="\${DBEAR_TEST_HOST}"`, "${DBEAR_TEST_HOST}"},
+		{`DB_PASSWORD='pa\$word'`, `pa\$word`},
+		{`DB_PASSWORD='$DBEAR_TEST_HOST'`, "$DBEAR_TEST_HOST"},
+		{"DB_PASSWORD=`pa\\$word`", `pa\$word`},
+		{"DB_PASSWORD=`${DBEAR_TEST_HOST}`", "${DBEAR_TEST_HOST}"},
+		{`DB_PASSWORD="a${b"`, "a${b"},
+		{"DB_PASSWORD=a${b", "a${b"},
+		{`DB_PASSWORD="$DBEAR_TEST_HOST"`, "db.example.com"},
+		{`DB_PASSWORD="${DBEAR_TEST_MISSING}"`, ""},
+		{"DB_PASSWORD=pa${DBEAR_TEST_MISSING}word", "paword"},
+		{"DB_PASSWORD=$DBEAR_TEST_HOST", "db.example.com"},
+		{"DB_PASSWORD=${DBEAR_TEST_HOST}:5432", "db.example.com:5432"},
+		{"DB_USER=app\nDB_PASSWORD=$DB_USER-$secret", "app-$secret"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			env, err := parseEnv(test.line + "\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value := env["DB_PASSWORD"]; value != test.expected {
+				t.Fatalf("got %q, expected %q", value, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package importer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dbear/internal/config"
+)
+
+// envStems are the key stems that mark a variable as part of a connection,
+// with the database type they imply, if any: DB_HOST, READ_DB_HOST,
+// DATABASE_HOST, POSTGRES_USER...
+var envStems = map[string]string{
+	"DB":       "",
+	"DATABASE": "",
+	"POSTGRES": config.TypePostgreSQL,
+	"MYSQL":    config.TypeMySQL,
+}
+
+// envFields maps key suffixes onto the connection field they configure
+var envFields = map[string]string{
+	"HOST":       "host",
+	"PORT":       "port",
+	"SOCKET":     "socket",
+	"DATABASE":   "database",
+	"NAME":       "database",
+	"DB":         "database",
+	"USERNAME":   "username",
+	"USER":       "username",
+	"PASSWORD":   "password",
+	"PASS":       "password",
+	"CONNECTION": "type",
+	"DRIVER":     "type",
+}
+
+// envURLSuffixes are the key suffixes of variables holding a connection URL
+var envURLSuffixes = []string{"_URL", "_URI", "_DSN"}
+
+// envGroup collects the variables sharing a prefix and stem
+type envGroup struct {
+	prefix string
+	stem   string
+	values map[string]string
+	keys   map[string]string
+}
+
+// Skipped returns the variables the last Scan call could not convert
+func (e *EnvImporter) Skipped() []SkippedEntry {
+	return e.skipped
+}
+
+// Scan detects every connection defined in an .env file: each group of
+// variables sharing a prefix (DB_*, READ_DB_*, ANALYTICS_DB_*...) and each
+// variable holding a connection URL (DATABASE_URL, READ_DATABASE_URL...).
+// Connections are named after options.ConnectionName, or the directory of the
+// file, followed by their prefix. options.DatabaseType is used for groups that
+// do not tell their database type.
+func (e *EnvImporter) Scan(filePath string, options ImportOptions) ([]config.Connection, error) {
+	e.skipped = nil
+
+	envMap, err := ParseEnvFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	baseName := options.ConnectionName
+	if baseName == "" {
+		baseName = projectName(filepath.Dir(filePath))
+	}
+
+	keys := make([]string, 0, len(envMap))
+	for key := range envMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	connections := []config.Connection{}
+	used := make(map[string]bool)
+	add := func(conn config.Connection, prefix string) {
+		for _, existing := range connections {
+			if sameEnvConnection(existing, conn) {
+				return
+			}
+		}
+		conn.Name = uniqueEnvName(envConnectionName(baseName, prefix), used)
+		connections = append(connections, conn)
+	}
+
+	for _, group := range envGroups(envMap, keys) {
+		conn, err := group.connection(options.DatabaseType)
+		if err != nil {
+			e.skipped = append(e.skipped, SkippedEntry{Name: group.prefix + group.stem + "_*", Reason: err.Error()})
+			continue
+		}
+		add(conn, group.prefix)
+	}
+
+	for _, key := range keys {
+		prefix, ok := envURLPrefix(key)
+		value := envMap[key]
+		if !ok || value == "" {
+			continue
+		}
+
+		conn, err := parseConnectionString(value, "")
+		if err != nil {
+			// Only report URLs that look like they were meant for a database
+			if strings.Contains(key, "DB") || strings.Contains(key, "DATABASE") {
+				e.skipped = append(e.skipped, SkippedEntry{Name: key, Reason: err.Error()})
+			}
+			continue
+		}
+		add(conn, prefix)
+	}
+
+	if options.DatabaseType != "" {
+		filtered := []config.Connection{}
+		for _, conn := range connections {
+			if conn.Type == options.DatabaseType {
+				filtered = append(filtered, conn)
+			}
+		}
+		connections = filtered
+	}
+
+	if err := resolveSQLitePaths(connections, filepath.Dir(filePath)); err != nil {
+		return nil, err
+	}
+
+	return connections, nil
+}
+
+// envGroups groups the connection variables among keys by prefix and stem
+func envGroups(envMap map[string]string, keys []string) []*envGroup {
+	groups := []*envGroup{}
+	byID := make(map[string]*envGroup)
+
+	for _, key := range keys {
+		prefix, stem, field, ok := splitEnvKey(key)
+		if !ok {
+			continue
+		}
+
+		id := prefix + stem
+		group, found := byID[id]
+		if !found {
+			group = &envGroup{prefix: prefix, stem: stem, values: map[string]string{}, keys: map[string]string{}}
+			byID[id] = group
+			groups = append(groups, group)
+		}
+		// The first key wins when several set the same field (DB_USER and DB_USERNAME)
+		if _, set := group.values[field]; !set {
+			group.values[field] = envMap[key]
+			group.keys[field] = key
+		}
+	}
+
+	return groups
+}
+
+// splitEnvKey splits keys such as READ_DB_HOST into their prefix (READ_), stem
+// (DB) and field (host)
+func splitEnvKey(key string) (string, string, string, bool) {
+	for suffix, field := range envFields {
+		rest, found := strings.CutSuffix(key, "_"+suffix)
+		if !found {
+			continue
+		}
+		for stem := range envStems {
+			if rest == stem {
+				return "", stem, field, true
+			}
+			if prefix, found := strings.CutSuffix(rest, "_"+stem); found && prefix != "" {
+				return prefix + "_", stem, field, true
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+// envURLPrefix returns the prefix of URL variables such as
+// ANALYTICS_DATABASE_URL (ANALYTICS_), or false for other keys
+func envURLPrefix(key string) (string, bool) {
+	for _, suffix := range envURLSuffixes {
+		rest, found := strings.CutSuffix(key, suffix)
+		if !found {
+			continue
+		}
+		for stem := range envStems {
+			if rest == stem {
+				return "", true
+			}
+			if prefix, found := strings.CutSuffix(rest, "_"+stem); found && prefix != "" {
+				return prefix + "_", true
+			}
+		}
+		return rest + "_", true
+	}
+	return "", false
+}
+
+// connection converts the group, falling back to defaultType when neither
+// the variables nor the stem tell the database type
+func (g *envGroup) connection(defaultType string) (config.Connection, error) {
+	if g.values["host"] == "" && g.values["socket"] == "" && g.values["database"] == "" {
+		return config.Connection{}, fmt.Errorf("no host, socket or database")
+	}
+
+	dbType := envStems[g.stem]
+	if driver := g.values["type"]; driver != "" {
+		dbType = driverType(driver)
+		if dbType == "" {
+			return config.Connection{}, fmt.Errorf("unsupported driver %s", driver)
+		}
+	}
+
+	port := 0
+	if value := g.values["port"]; value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return config.Connection{}, fmt.Errorf("invalid port value '%s'", value)
+		}
+		port = parsed
+	}
+
+	if dbType == "" {
+		switch port {
+		case 5432:
+			dbType = config.TypePostgreSQL
+		case 3306:
+			dbType = config.TypeMySQL
+		default:
+			dbType = defaultType
+		}
+	}
+	if dbType == "" {
+		return config.Connection{}, fmt.Errorf("cannot tell the database type, set %s%s_CONNECTION", g.prefix, g.stem)
+	}
+
+	if dbType == config.TypeSQLite {
+		if g.values["database"] == "" {
+			return config.Connection{}, fmt.Errorf("no database file")
+		}
+		return config.Connection{Type: dbType, Database: g.values["database"]}, nil
+	}
+
+	if port == 0 {
+		port = 5432
+		if dbType == config.TypeMySQL {
+			port = 3306
+		}
+	}
+
+	conn := config.Connection{
+		Type:     dbType,
+		Host:     g.values["host"],
+		Port:     port,
+		Socket:   g.values["socket"],
+		Database: g.values["database"],
+		Username: g.values["username"],
+		Password: g.values["password"],
+	}
+	if conn.Host == "" && conn.Socket == "" {
+		conn.Host = "localhost"
+	}
+
+	return conn, nil
+}
+
+// envConnectionName names a connection after the scan's base name and the
+// prefix of its variables: READ_ gives <base>-read
+func envConnectionName(baseName, prefix string) string {
+	suffix := strings.ToLower(strings.ReplaceAll(strings.Trim(prefix, "_"), "_", "-"))
+	switch {
+	case suffix == "":
+		return baseName
+	case baseName == "":
+		return suffix
+	default:
+		return baseName + "-" + suffix
+	}
+}
+
+// uniqueEnvName appends a counter to name until it is not in used, and marks
+// the result as used
+func uniqueEnvName(name string, used map[string]bool) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// sameEnvConnection reports whether two scanned connections point at the same
+// database as the same user, as when DATABASE_URL repeats the DB_* variables
+func sameEnvConnection(a, b config.Connection) bool {
+	return a.Type == b.Type && a.Host == b.Host && a.Port == b.Port && a.Socket == b.Socket &&
+		a.Database == b.Database && a.Username == b.Username
+}
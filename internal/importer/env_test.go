@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"testing"
+
+	"dbear/internal/config"
+)
+
+func TestParseConnectionStringSSLMode(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+		invalid  bool
+	}{
+		{"postgres://app@localhost/app?sslmode=verify-full", config.SSLModeVerifyFull, false},
+		{"postgres://app@localhost/app?sslmode=no-verify", config.SSLModeRequire, false},
+		{"mysql://app@localhost/app?ssl-mode=REQUIRED", config.SSLModeRequire, false},
+		{"mysql://app@localhost/app?sslmode=VERIFY_IDENTITY", config.SSLModeVerifyFull, false},
+		{"postgres://app@localhost/app?sslmode=strict", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			conn, err := parseConnectionString(test.url, "")
+			if test.invalid {
+				if err == nil {
+					t.Fatalf("expected an error, got sslmode %q", conn.SSLMode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if conn.SSLMode != test.expected {
+				t.Fatalf("got sslmode %q, expected %q", conn.SSLMode, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseConnectionStringDropsFrameworkParams(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected map[string]string
+	}{
+		{"postgres://app@localhost/app?schema=public&connection_limit=5&pgbouncer=true", nil},
+		{"mysql2://app@localhost/app?pool=5&encoding=utf8mb4&reconnect=true", nil},
+		{"postgres://app@localhost/app?application_name=web&pool=10", map[string]string{"application_name": "web"}},
+		{"mysql://app@localhost/app?time_zone=UTC&connection_limit=1", map[string]string{"time_zone": "UTC"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			conn, err := parseConnectionString(test.url, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(conn.Params) != len(test.expected) {
+				t.Fatalf("got params %v, expected %v", conn.Params, test.expected)
+			}
+			for key, value := range test.expected {
+				if conn.Params[key] != value {
+					t.Fatalf("got params %v, expected %v", conn.Params, test.expected)
+				}
+			}
+		})
+	}
+}
@@ -6,6 +6,9 @@ import "dbear/internal/config"
 type ImportOptions struct {
 	ConnectionName string
 	DatabaseType   string
+	// AllowMissingPasswords imports connections whose password cannot be
+	// recovered instead of skipping them
+	AllowMissingPasswords bool
 }
 
 // Importer defines the interface for all import types
@@ -13,3 +16,15 @@ type Importer interface {
 	Import(filePath string, options ImportOptions) ([]config.Connection, error)
 }
 
+// SkippedEntry describes an entry of an import source that could not be turned
+// into a connection
+type SkippedEntry struct {
+	Name   string
+	Reason string
+}
+
+// SkipReporter is implemented by importers that skip entries they cannot
+// convert, so callers can tell the user what was left out and why
+type SkipReporter interface {
+	Skipped() []SkippedEntry
+}
@@ -0,0 +1,84 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// iniSection is one [name] group of an INI-style file such as pg_service.conf
+// or my.cnf
+type iniSection struct {
+	Name   string
+	Values map[string]string
+}
+
+// parseINIFile reads the sections of an INI-style file in order. Keys outside a
+// section, comments (# and ;) and !include directives are ignored. Keys without
+// a value, such as my.cnf boolean options, map to an empty string.
+func parseINIFile(filePath string) ([]iniSection, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	sections := []iniSection{}
+	var current *iniSection
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			sections = append(sections, iniSection{
+				Name:   strings.TrimSpace(line[1 : len(line)-1]),
+				Values: map[string]string{},
+			})
+			current = &sections[len(sections)-1]
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, "=")
+		current.Values[strings.TrimSpace(key)] = unquoteINIValue(strings.TrimSpace(value))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+
+	return sections, nil
+}
+
+func unquoteINIValue(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+
+	quote := value[0]
+	if (quote != '"' && quote != '\'') || value[len(value)-1] != quote {
+		return value
+	}
+
+	inner := value[1 : len(value)-1]
+	if quote == '\'' {
+		return inner
+	}
+
+	var unescaped strings.Builder
+	for i := 0; i < len(inner); i++ {
+		if inner[i] == '\\' && i+1 < len(inner) {
+			i++
+		}
+		unescaped.WriteByte(inner[i])
+	}
+	return unescaped.String()
+}
@@ -0,0 +1,138 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LookupFunc resolves a variable name during interpolation
+type LookupFunc func(name string) (string, bool)
+
+// Interpolate expands $VAR and ${VAR} references in value using lookup. It
+// supports the shell-style forms used by docker compose and dotenv files:
+// ${VAR:-default}, ${VAR-default}, ${VAR:?error}, ${VAR?error} and $$ as an
+// escaped dollar sign. Unknown variables expand to an empty string.
+//
+// As in compose files, a backslash does not escape a dollar sign here;
+// ParseEnvFile turns the \$ of the .env values that allow it into $$ first.
+func Interpolate(value string, lookup LookupFunc) (string, error) {
+	if !strings.Contains(value, "$") {
+		return value, nil
+	}
+
+	var result strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '$' || i+1 >= len(value) {
+			result.WriteByte(c)
+			continue
+		}
+
+		next := value[i+1]
+		switch {
+		case next == '$':
+			result.WriteByte('$')
+			i++
+		case next == '{':
+			end := matchingBrace(value, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference in %q", value)
+			}
+			expanded, err := expandBraced(value[i+2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+			result.WriteString(expanded)
+			i = end
+		case isVariableStart(next):
+			end := i + 1
+			for end < len(value) && isVariableChar(value[end]) {
+				end++
+			}
+			resolved, _ := lookup(value[i+1 : end])
+			result.WriteString(resolved)
+			i = end - 1
+		default:
+			result.WriteByte(c)
+		}
+	}
+
+	return result.String(), nil
+}
+
+// expandBraced expands the inside of a ${...} reference
+func expandBraced(expr string, lookup LookupFunc) (string, error) {
+	nameEnd := 0
+	for nameEnd < len(expr) && isVariableChar(expr[nameEnd]) {
+		nameEnd++
+	}
+	name := expr[:nameEnd]
+	if name == "" {
+		return "", fmt.Errorf("invalid variable reference ${%s}", expr)
+	}
+
+	resolved, ok := lookup(name)
+	modifier := expr[nameEnd:]
+	if modifier == "" {
+		return resolved, nil
+	}
+
+	switch {
+	case strings.HasPrefix(modifier, ":-"):
+		if resolved == "" {
+			return Interpolate(modifier[2:], lookup)
+		}
+	case strings.HasPrefix(modifier, "-"):
+		if !ok {
+			return Interpolate(modifier[1:], lookup)
+		}
+	case strings.HasPrefix(modifier, ":?"):
+		if resolved == "" {
+			return "", fmt.Errorf("required variable %s is missing or empty: %s", name, modifier[2:])
+		}
+	case strings.HasPrefix(modifier, "?"):
+		if !ok {
+			return "", fmt.Errorf("required variable %s is missing: %s", name, modifier[1:])
+		}
+	case strings.HasPrefix(modifier, ":+"):
+		if resolved != "" {
+			return Interpolate(modifier[2:], lookup)
+		}
+		return "", nil
+	case strings.HasPrefix(modifier, "+"):
+		if ok {
+			return Interpolate(modifier[1:], lookup)
+		}
+		return "", nil
+	default:
+		return "", fmt.Errorf("invalid variable reference ${%s}", expr)
+	}
+
+	return resolved, nil
+}
+
+// matchingBrace returns the index of the brace closing a ${ opened before start,
+// allowing nested references in default values.
+func matchingBrace(value string, start int) int {
+	depth := 1
+	for i := start; i < len(value); i++ {
+		switch value[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isVariableStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isVariableChar(c byte) bool {
+	return isVariableStart(c) || (c >= '0' && c <= '9')
+}
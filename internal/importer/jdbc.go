@@ -0,0 +1,103 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+)
+
+// parseJDBCURL converts a JDBC URL as stored by Java-based clients into a
+// connection. Relative SQLite paths are resolved against baseDir.
+func parseJDBCURL(jdbcURL string, baseDir string) (config.Connection, error) {
+	if !strings.HasPrefix(jdbcURL, "jdbc:") {
+		return config.Connection{}, fmt.Errorf("not a JDBC URL")
+	}
+	rest := strings.TrimPrefix(jdbcURL, "jdbc:")
+
+	if strings.HasPrefix(rest, "sqlite:") {
+		path := strings.TrimPrefix(rest, "sqlite:")
+		if query := strings.Index(path, "?"); query >= 0 {
+			path = path[:query]
+		}
+		if path == "" || path == ":memory:" {
+			return config.Connection{}, fmt.Errorf("in-memory SQLite database")
+		}
+		absPath, err := connection.ExpandPath(path, baseDir)
+		if err != nil {
+			return config.Connection{}, err
+		}
+		return config.Connection{Type: config.TypeSQLite, Database: absPath}, nil
+	}
+
+	conn, err := parseConnectionString(rest, "")
+	if err != nil {
+		return config.Connection{}, err
+	}
+
+	// JDBC drivers take credentials and TLS switches as URL properties
+	for key, value := range conn.Params {
+		switch key {
+		case "user":
+			conn.Username = value
+		case "password":
+			conn.Password = value
+		case "ssl", "useSSL":
+			if value == "true" && conn.SSLMode == "" {
+				conn.SSLMode = config.SSLModeRequire
+			}
+		case "sslMode":
+			if conn.SSLMode == "" {
+				conn.SSLMode = connection.SSLModeFromMySQL(value)
+			}
+		case "sslfactory", "requireSSL", "verifyServerCertificate":
+			// Java-only TLS settings with no libpq or MySQL client equivalent
+		default:
+			continue
+		}
+		delete(conn.Params, key)
+	}
+	if len(conn.Params) == 0 {
+		conn.Params = nil
+	}
+
+	return conn, nil
+}
+
+// driverType maps the driver or provider identifiers used by GUI clients and
+// framework settings (Laravel's pgsql) onto dbear database types. It returns an
+// empty string for unsupported drivers.
+func driverType(driver string) string {
+	id := strings.ToLower(driver)
+	switch {
+	case strings.HasPrefix(id, "postgres") || id == "pgsql":
+		return config.TypePostgreSQL
+	case strings.HasPrefix(id, "mysql") || strings.HasPrefix(id, "mariadb"):
+		return config.TypeMySQL
+	case strings.HasPrefix(id, "sqlite"):
+		return config.TypeSQLite
+	default:
+		return ""
+	}
+}
+
+// checkPassword decides whether a converted connection without a password can
+// be imported. SQLite files and passwordless users are fine; everything else
+// needs options.AllowMissingPasswords.
+func checkPassword(conn config.Connection, options ImportOptions, reason string) error {
+	if !NeedsPassword(conn) || options.AllowMissingPasswords {
+		return nil
+	}
+	return fmt.Errorf("missing password (%s)", reason)
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
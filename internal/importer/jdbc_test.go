@@ -0,0 +1,88 @@
+package importer
+
+import (
+	"testing"
+
+	"dbear/internal/config"
+)
+
+func TestParseJDBCURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected config.Connection
+		valid    bool
+	}{
+		{
+			"jdbc:postgresql://db.example.com:5433/app?user=admin&password=s3cret&ssl=true&sslfactory=org.postgresql.ssl.NonValidatingFactory",
+			config.Connection{Type: config.TypePostgreSQL, Host: "db.example.com", Port: 5433, Database: "app", Username: "admin", Password: "s3cret", SSLMode: config.SSLModeRequire},
+			true,
+		},
+		{
+			"jdbc:postgresql://localhost/app?sslmode=verify-full",
+			config.Connection{Type: config.TypePostgreSQL, Host: "localhost", Port: 5432, Database: "app", SSLMode: config.SSLModeVerifyFull},
+			true,
+		},
+		{
+			"jdbc:mysql://db.example.com/shop?sslMode=VERIFY_CA&useSSL=true",
+			config.Connection{Type: config.TypeMySQL, Host: "db.example.com", Port: 3306, Database: "shop", SSLMode: config.SSLModeVerifyCA},
+			true,
+		},
+		{
+			"jdbc:mariadb://db.example.com:3307/shop?user=app",
+			config.Connection{Type: config.TypeMySQL, Host: "db.example.com", Port: 3307, Database: "shop", Username: "app"},
+			true,
+		},
+		{
+			"jdbc:sqlite:data/app.db?journal_mode=wal",
+			config.Connection{Type: config.TypeSQLite, Database: "/project/data/app.db"},
+			true,
+		},
+		{"jdbc:sqlite::memory:", config.Connection{}, false},
+		{"jdbc:sqlite:", config.Connection{}, false},
+		{"postgresql://localhost/app", config.Connection{}, false},
+		{"jdbc:oracle:thin:@localhost:1521:xe", config.Connection{}, false},
+	}
+
+	for _, test := range tests {
+		conn, err := parseJDBCURL(test.url, "/project")
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %+v", test.url, conn)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.url, err)
+			continue
+		}
+
+		want := test.expected
+		if conn.Type != want.Type || conn.Host != want.Host || conn.Port != want.Port || conn.Database != want.Database ||
+			conn.Username != want.Username || conn.Password != want.Password || conn.SSLMode != want.SSLMode || len(conn.Params) != 0 {
+			t.Errorf("%s: got %+v, expected %+v", test.url, conn, want)
+		}
+	}
+}
+
+func TestDriverType(t *testing.T) {
+	tests := []struct {
+		driver   string
+		expected string
+	}{
+		{"postgresql", config.TypePostgreSQL},
+		{"postgres-jdbc", config.TypePostgreSQL},
+		{"PostgreSQL", config.TypePostgreSQL},
+		{"pgsql", config.TypePostgreSQL},
+		{"mysql8", config.TypeMySQL},
+		{"MariaDB", config.TypeMySQL},
+		{"sqlite_jdbc", config.TypeSQLite},
+		{"oracle", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if dbType := driverType(test.driver); dbType != test.expected {
+			t.Errorf("%q: got %q, expected %q", test.driver, dbType, test.expected)
+		}
+	}
+}
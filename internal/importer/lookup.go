@@ -0,0 +1,38 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ProjectLookup resolves variables the way docker compose and the dotenv
+// libraries of most frameworks do: the shell environment first, then the .env
+// file in dir, if there is one.
+func ProjectLookup(dir string) (LookupFunc, error) {
+	dotEnv := map[string]string{}
+	dotEnvPath := filepath.Join(dir, ".env")
+	if _, err := os.Stat(dotEnvPath); err == nil {
+		parsed, err := ParseEnvFile(dotEnvPath)
+		if err != nil {
+			return nil, err
+		}
+		dotEnv = parsed
+	}
+
+	return func(name string) (string, bool) {
+		if value, ok := os.LookupEnv(name); ok {
+			return value, true
+		}
+		value, ok := dotEnv[name]
+		return value, ok
+	}, nil
+}
+
+// projectName derives a connection name prefix from the directory of a project
+func projectName(dir string) string {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(absDir)
+}
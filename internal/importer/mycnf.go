@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+)
+
+// MyCnfImporter implements the Importer interface for MySQL option files
+// (~/.my.cnf). Each [client] or [client-<suffix>] group becomes a connection,
+// so the groups used with --defaults-group-suffix map onto dbear connections.
+type MyCnfImporter struct {
+	skipped []SkippedEntry
+}
+
+// NewMyCnfImporter creates a new MyCnfImporter instance
+func NewMyCnfImporter() *MyCnfImporter {
+	return &MyCnfImporter{}
+}
+
+// MyCnfPath returns the per-user MySQL option file, ~/.my.cnf
+func MyCnfPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ".my.cnf"
+	}
+	return filepath.Join(homeDir, ".my.cnf")
+}
+
+// Skipped returns the groups the last Import call could not convert
+func (m *MyCnfImporter) Skipped() []SkippedEntry {
+	return m.skipped
+}
+
+// Import imports connections from a MySQL option file
+func (m *MyCnfImporter) Import(filePath string, options ImportOptions) ([]config.Connection, error) {
+	m.skipped = nil
+
+	if options.DatabaseType != "" && options.DatabaseType != config.TypeMySQL {
+		return []config.Connection{}, nil
+	}
+
+	sections, err := parseINIFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The mysql client reads its database from [mysql] groups, since
+	// mysqldump rejects the option in [client] groups
+	databases := map[string]string{}
+	for _, section := range sections {
+		if section.Name == "mysql" || strings.HasPrefix(section.Name, "mysql-") {
+			if database, ok := section.Values["database"]; ok {
+				databases[strings.TrimPrefix(section.Name, "mysql")] = database
+			}
+		}
+	}
+
+	connections := []config.Connection{}
+	for _, section := range sections {
+		if section.Name != "client" && !strings.HasPrefix(section.Name, "client-") {
+			continue
+		}
+
+		name := strings.TrimPrefix(section.Name, "client-")
+		if section.Name == "client" {
+			name = "mycnf"
+		}
+		if options.ConnectionName != "" {
+			name = options.ConnectionName + "-" + name
+		}
+
+		conn := config.Connection{
+			Name:     name,
+			Type:     config.TypeMySQL,
+			Host:     "localhost",
+			Port:     3306,
+			Database: databases[strings.TrimPrefix(section.Name, "client")],
+		}
+
+		valid := true
+		for rawKey, value := range section.Values {
+			// Option names accept dashes and underscores interchangeably
+			switch strings.ReplaceAll(rawKey, "_", "-") {
+			case "host":
+				conn.Host = value
+			case "port":
+				port, err := strconv.Atoi(value)
+				if err != nil {
+					m.skipped = append(m.skipped, SkippedEntry{Name: section.Name, Reason: "invalid port"})
+					valid = false
+				}
+				conn.Port = port
+			case "user":
+				conn.Username = value
+			case "password":
+				conn.Password = value
+			case "database":
+				conn.Database = value
+			case "socket":
+				conn.Socket = value
+			case "ssl-mode":
+				conn.SSLMode = connection.SSLModeFromMySQL(value)
+			case "ssl-ca":
+				conn.SSLRootCert = value
+			case "ssl-cert":
+				conn.SSLCert = value
+			case "ssl-key":
+				conn.SSLKey = value
+			case "default-character-set":
+				if conn.Params == nil {
+					conn.Params = make(map[string]string)
+				}
+				conn.Params["charset"] = value
+			}
+		}
+		if !valid {
+			continue
+		}
+
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
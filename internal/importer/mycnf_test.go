@@ -0,0 +1,76 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dbear/internal/config"
+)
+
+const testMyCnf = `[client]
+user=root
+password=r00t
+socket=/var/run/mysqld/mysqld.sock
+
+[mysql]
+database=app
+
+[client-prod]
+host=db.example.com
+port=3307
+user=admin
+password="pa#ss"
+ssl_mode=VERIFY_IDENTITY
+ssl-ca=/etc/ssl/ca.pem
+default_character_set=utf8mb4
+
+[mysql-prod]
+database=shop
+
+[client-broken]
+port=fifty
+
+[mysqldump]
+quick
+`
+
+func TestMyCnfImport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".my.cnf")
+	if err := os.WriteFile(path, []byte(testMyCnf), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMyCnfImporter()
+	connections, err := m.Import(path, ImportOptions{ConnectionName: "home"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []config.Connection{
+		{Name: "home-mycnf", Host: "localhost", Port: 3306, Socket: "/var/run/mysqld/mysqld.sock", Username: "root", Password: "r00t", Database: "app"},
+		{
+			Name: "home-prod", Host: "db.example.com", Port: 3307, Username: "admin", Password: "pa#ss", Database: "shop",
+			SSLMode: config.SSLModeVerifyFull, SSLRootCert: "/etc/ssl/ca.pem",
+		},
+	}
+	if len(connections) != len(expected) {
+		t.Fatalf("got %d connections, expected %d: %+v", len(connections), len(expected), connections)
+	}
+	for i, conn := range connections {
+		want := expected[i]
+		if conn.Name != want.Name || conn.Type != config.TypeMySQL || conn.Host != want.Host || conn.Port != want.Port ||
+			conn.Socket != want.Socket || conn.Username != want.Username || conn.Password != want.Password ||
+			conn.Database != want.Database || conn.SSLMode != want.SSLMode || conn.SSLRootCert != want.SSLRootCert {
+			t.Errorf("got connection %+v, expected %+v", conn, want)
+		}
+	}
+	if charset := connections[1].Params["charset"]; charset != "utf8mb4" {
+		t.Errorf("got charset %q, expected utf8mb4", charset)
+	}
+
+	skipped := m.Skipped()
+	if len(skipped) != 1 || skipped[0].Name != "client-broken" {
+		t.Fatalf("expected client-broken to be skipped, got %+v", skipped)
+	}
+}
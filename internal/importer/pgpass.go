@@ -0,0 +1,220 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+)
+
+// PgpassImporter implements the Importer interface for libpq password files
+// (~/.pgpass). Every line without wildcards in its host, database or user
+// fields becomes a connection, with the password of the first line matching
+// it, as libpq reads the file.
+type PgpassImporter struct {
+	skipped []SkippedEntry
+}
+
+// NewPgpassImporter creates a new PgpassImporter instance
+func NewPgpassImporter() *PgpassImporter {
+	return &PgpassImporter{}
+}
+
+// PgpassEntry is one hostname:port:database:username:password line of a
+// password file. Fields may be the * wildcard.
+type PgpassEntry struct {
+	Host     string
+	Port     string
+	Database string
+	Username string
+	Password string
+}
+
+// Matches reports whether the entry applies to the given connection parameters,
+// honouring * wildcards as libpq does
+func (e PgpassEntry) Matches(host, port, database, username string) bool {
+	match := func(field, value string) bool {
+		return field == "*" || field == value
+	}
+	return match(e.Host, host) && match(e.Port, port) && match(e.Database, database) && match(e.Username, username)
+}
+
+// MatchPgpassEntry returns the index of the first entry applying to conn, or
+// -1 when none does. libpq matches socket connections against localhost when
+// they use its default socket directory and against the directory otherwise;
+// both are accepted as the default depends on how libpq was built.
+func MatchPgpassEntry(entries []PgpassEntry, conn config.Connection) int {
+	hosts := []string{conn.Host}
+	port := conn.Port
+	if conn.Socket != "" {
+		var dir string
+		dir, port = connection.PostgreSQLSocketDir(conn)
+		hosts = []string{"localhost", dir}
+	}
+	if port == 0 {
+		port = 5432
+	}
+
+	for i, entry := range entries {
+		for _, host := range hosts {
+			if entry.Matches(host, strconv.Itoa(port), conn.Database, conn.Username) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// PgpassPath returns the password file libpq reads: $PGPASSFILE or ~/.pgpass
+func PgpassPath() string {
+	if path := os.Getenv("PGPASSFILE"); path != "" {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ".pgpass"
+	}
+	return filepath.Join(homeDir, ".pgpass")
+}
+
+// ParsePgpassFile reads the entries of a libpq password file
+func ParsePgpassFile(filePath string) ([]PgpassEntry, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open password file: %w", err)
+	}
+	defer file.Close()
+
+	entries := []PgpassEntry{}
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		fields := SplitPgpassLine(line)
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("line %d: expected 5 colon-separated fields, found %d", lineNumber, len(fields))
+		}
+
+		entries = append(entries, PgpassEntry{
+			Host:     fields[0],
+			Port:     fields[1],
+			Database: fields[2],
+			Username: fields[3],
+			Password: fields[4],
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read password file: %w", err)
+	}
+
+	return entries, nil
+}
+
+// SplitPgpassLine splits a password file line on unescaped colons and removes
+// the \: and \\ escapes
+func SplitPgpassLine(line string) []string {
+	fields := []string{}
+	var field strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line):
+			i++
+			field.WriteByte(line[i])
+		case line[i] == ':':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteByte(line[i])
+		}
+	}
+	return append(fields, field.String())
+}
+
+// Skipped returns the lines the last Import call could not convert
+func (p *PgpassImporter) Skipped() []SkippedEntry {
+	return p.skipped
+}
+
+// Import imports connections from a libpq password file
+func (p *PgpassImporter) Import(filePath string, options ImportOptions) ([]config.Connection, error) {
+	p.skipped = nil
+
+	if options.DatabaseType != "" && options.DatabaseType != config.TypePostgreSQL {
+		return []config.Connection{}, nil
+	}
+
+	entries, err := ParsePgpassFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	connections := []config.Connection{}
+	names := map[string]int{}
+	for i, entry := range entries {
+		label := fmt.Sprintf("%s:%s:%s:%s", entry.Host, entry.Port, entry.Database, entry.Username)
+		if entry.Host == "*" || entry.Database == "*" || entry.Username == "*" {
+			p.skipped = append(p.skipped, SkippedEntry{Name: label, Reason: "wildcard host, database or user"})
+			continue
+		}
+
+		port := 5432
+		if entry.Port != "*" && entry.Port != "" {
+			port, err = strconv.Atoi(entry.Port)
+			if err != nil {
+				p.skipped = append(p.skipped, SkippedEntry{Name: label, Reason: "invalid port"})
+				continue
+			}
+		}
+
+		conn := config.Connection{
+			Type:     config.TypePostgreSQL,
+			Host:     entry.Host,
+			Port:     port,
+			Database: entry.Database,
+			Username: entry.Username,
+			Password: entry.Password,
+		}
+		// libpq matches socket connections against the directory or localhost
+		if strings.HasPrefix(entry.Host, "/") {
+			conn.Host = ""
+			conn.Socket = entry.Host
+		}
+
+		// libpq uses the first line matching, which may be an earlier
+		// wildcard or duplicate line
+		if first := MatchPgpassEntry(entries, conn); first != i {
+			if !strings.ContainsRune(entries[first].Host+entries[first].Port+entries[first].Database+entries[first].Username, '*') {
+				p.skipped = append(p.skipped, SkippedEntry{Name: label, Reason: "an earlier line has the same host, port, database and user"})
+				continue
+			}
+			conn.Password = entries[first].Password
+		}
+
+		conn.Name = entry.Host + "-" + entry.Database
+		if conn.Socket != "" {
+			conn.Name = "local-" + entry.Database
+		}
+		if options.ConnectionName != "" {
+			conn.Name = options.ConnectionName + "-" + conn.Name
+		}
+		names[conn.Name]++
+		if names[conn.Name] > 1 {
+			conn.Name += "-" + entry.Username
+		}
+
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
@@ -0,0 +1,92 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dbear/internal/config"
+)
+
+const testPgpass = `# staging
+*:*:*:readonly:wildcard
+db.example.com:5432:app:readonly:shadowed
+db.example.com:5432:app:admin:first
+db.example.com:5432:app:admin:duplicate
+localhost:5432:local:app:tcp
+/var/run/postgresql:5432:sock:app:socket
+db.example.com:*:*:*:fallback
+`
+
+func writePgpass(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".pgpass")
+	if err := os.WriteFile(path, []byte(testPgpass), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMatchPgpassEntryTakesTheFirstMatchingLine(t *testing.T) {
+	entries, err := ParsePgpassFile(writePgpass(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		conn     config.Connection
+		password string
+	}{
+		{"earlier wildcard", config.Connection{Host: "db.example.com", Port: 5432, Database: "app", Username: "readonly"}, "wildcard"},
+		{"first of duplicates", config.Connection{Host: "db.example.com", Port: 5432, Database: "app", Username: "admin"}, "first"},
+		{"later wildcard", config.Connection{Host: "db.example.com", Port: 6432, Database: "other", Username: "admin"}, "fallback"},
+		{"socket in the default directory", config.Connection{Socket: "/tmp", Port: 5432, Database: "local", Username: "app"}, "tcp"},
+		{"socket in its directory", config.Connection{Socket: "/var/run/postgresql", Database: "sock", Username: "app"}, "socket"},
+		{"socket file", config.Connection{Socket: "/var/run/postgresql/.s.PGSQL.5432", Database: "sock", Username: "app"}, "socket"},
+		{"no match", config.Connection{Host: "other.example.com", Port: 5432, Database: "app", Username: "admin"}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if password := lookupPgpassPassword(entries, test.conn); password != test.password {
+				t.Fatalf("got password %q, expected %q", password, test.password)
+			}
+		})
+	}
+}
+
+func TestPgpassImportUsesThePasswordLibpqWould(t *testing.T) {
+	importer := NewPgpassImporter()
+	connections, err := importer.Import(writePgpass(t), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passwords := map[string]string{}
+	for _, conn := range connections {
+		passwords[conn.Name] = conn.Password
+	}
+	expected := map[string]string{
+		"db.example.com-app":       "wildcard",
+		"db.example.com-app-admin": "first",
+		"localhost-local":          "tcp",
+		"local-sock":               "socket",
+	}
+	if len(passwords) != len(expected) {
+		t.Fatalf("got connections %v, expected %v", passwords, expected)
+	}
+	for name, password := range expected {
+		if passwords[name] != password {
+			t.Fatalf("got connections %v, expected %v", passwords, expected)
+		}
+	}
+
+	skipped := map[string]string{}
+	for _, entry := range importer.Skipped() {
+		skipped[entry.Name] = entry.Reason
+	}
+	if _, found := skipped["db.example.com:5432:app:admin"]; !found {
+		t.Fatalf("duplicate line not skipped: %v", skipped)
+	}
+}
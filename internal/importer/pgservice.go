@@ -0,0 +1,131 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"dbear/internal/config"
+)
+
+// PgServiceImporter implements the Importer interface for libpq connection
+// service files (~/.pg_service.conf). Each [service] becomes a connection named
+// after the service. Passwords missing from the service file are looked up in
+// the password file, as libpq does.
+type PgServiceImporter struct {
+	skipped []SkippedEntry
+}
+
+// NewPgServiceImporter creates a new PgServiceImporter instance
+func NewPgServiceImporter() *PgServiceImporter {
+	return &PgServiceImporter{}
+}
+
+// PgServicePath returns the service file libpq reads: $PGSERVICEFILE or
+// ~/.pg_service.conf
+func PgServicePath() string {
+	if path := os.Getenv("PGSERVICEFILE"); path != "" {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ".pg_service.conf"
+	}
+	return filepath.Join(homeDir, ".pg_service.conf")
+}
+
+// Skipped returns the services the last Import call could not convert
+func (p *PgServiceImporter) Skipped() []SkippedEntry {
+	return p.skipped
+}
+
+// Import imports connections from a libpq service file
+func (p *PgServiceImporter) Import(filePath string, options ImportOptions) ([]config.Connection, error) {
+	p.skipped = nil
+
+	if options.DatabaseType != "" && options.DatabaseType != config.TypePostgreSQL {
+		return []config.Connection{}, nil
+	}
+
+	sections, err := parseINIFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// A missing password file only means there is nothing to fill in
+	passwords, _ := ParsePgpassFile(PgpassPath())
+
+	connections := []config.Connection{}
+	for _, section := range sections {
+		conn := config.Connection{
+			Name: section.Name,
+			Type: config.TypePostgreSQL,
+			Port: 5432,
+		}
+		if options.ConnectionName != "" {
+			conn.Name = options.ConnectionName + "-" + section.Name
+		}
+
+		valid := true
+		for key, value := range section.Values {
+			switch key {
+			case "host", "hostaddr":
+				if strings.HasPrefix(value, "/") {
+					conn.Socket = value
+				} else if key == "host" || conn.Host == "" {
+					conn.Host = value
+				}
+			case "port":
+				port, err := strconv.Atoi(value)
+				if err != nil {
+					p.skipped = append(p.skipped, SkippedEntry{Name: section.Name, Reason: "invalid port"})
+					valid = false
+				}
+				conn.Port = port
+			case "dbname":
+				conn.Database = value
+			case "user":
+				conn.Username = value
+			case "password":
+				conn.Password = value
+			case "sslmode":
+				conn.SSLMode = value
+			case "sslrootcert":
+				conn.SSLRootCert = value
+			case "sslcert":
+				conn.SSLCert = value
+			case "sslkey":
+				conn.SSLKey = value
+			default:
+				if conn.Params == nil {
+					conn.Params = make(map[string]string)
+				}
+				conn.Params[key] = value
+			}
+		}
+		if !valid {
+			continue
+		}
+
+		if conn.Host == "" && conn.Socket == "" {
+			conn.Host = "localhost"
+		}
+		if conn.Password == "" {
+			conn.Password = lookupPgpassPassword(passwords, conn)
+		}
+
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
+
+// lookupPgpassPassword returns the password of the first password file entry
+// matching conn
+func lookupPgpassPassword(entries []PgpassEntry, conn config.Connection) string {
+	if i := MatchPgpassEntry(entries, conn); i >= 0 {
+		return entries[i].Password
+	}
+	return ""
+}
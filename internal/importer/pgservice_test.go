@@ -0,0 +1,134 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dbear/internal/config"
+)
+
+const testPgService = `# shared services
+[prod]
+host=db.example.com
+port=5433
+dbname=app
+user=admin
+password="pa ss"
+sslmode=verify-full
+sslrootcert=/etc/ssl/ca.pem
+application_name=dbear
+
+[staging]
+host = db.example.com
+dbname = app
+user = admin
+
+[local]
+host=/var/run/postgresql
+dbname=sock
+user=app
+
+[ipv4]
+hostaddr=10.0.0.5
+user=app
+
+[broken]
+port=fifty
+`
+
+func TestPgServiceImport(t *testing.T) {
+	dir := t.TempDir()
+	servicePath := filepath.Join(dir, "pg_service.conf")
+	if err := os.WriteFile(servicePath, []byte(testPgService), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PGPASSFILE", writePgpass(t))
+
+	p := NewPgServiceImporter()
+	connections, err := p.Import(servicePath, ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []config.Connection{
+		{
+			Name: "prod", Host: "db.example.com", Port: 5433, Database: "app", Username: "admin", Password: "pa ss",
+			SSLMode: config.SSLModeVerifyFull, SSLRootCert: "/etc/ssl/ca.pem",
+			Params: map[string]string{"application_name": "dbear"},
+		},
+		{Name: "staging", Host: "db.example.com", Port: 5432, Database: "app", Username: "admin", Password: "first"},
+		{Name: "local", Socket: "/var/run/postgresql", Port: 5432, Database: "sock", Username: "app", Password: "socket"},
+		{Name: "ipv4", Host: "10.0.0.5", Port: 5432, Username: "app"},
+	}
+	if len(connections) != len(expected) {
+		t.Fatalf("got %d connections, expected %d: %+v", len(connections), len(expected), connections)
+	}
+	for i, conn := range connections {
+		want := expected[i]
+		if conn.Name != want.Name || conn.Type != config.TypePostgreSQL || conn.Host != want.Host || conn.Socket != want.Socket ||
+			conn.Port != want.Port || conn.Database != want.Database || conn.Username != want.Username || conn.Password != want.Password ||
+			conn.SSLMode != want.SSLMode || conn.SSLRootCert != want.SSLRootCert || len(conn.Params) != len(want.Params) {
+			t.Errorf("got connection %+v, expected %+v", conn, want)
+		}
+		for key, value := range want.Params {
+			if conn.Params[key] != value {
+				t.Errorf("%s: got %s=%q, expected %q", conn.Name, key, conn.Params[key], value)
+			}
+		}
+	}
+
+	skipped := p.Skipped()
+	if len(skipped) != 1 || skipped[0].Name != "broken" {
+		t.Fatalf("expected the broken service to be skipped, got %+v", skipped)
+	}
+
+	other, err := p.Import(servicePath, ImportOptions{DatabaseType: config.TypeMySQL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(other) != 0 {
+		t.Fatalf("expected no connections for a mysql import, got %+v", other)
+	}
+}
+
+func TestParseINIFile(t *testing.T) {
+	content := `ignored=outside
+; comment
+!includedir /etc/mysql/conf.d/
+[client]
+user = app
+password = "a\"b\\c"
+host='db.example.com'
+skip-ssl
+[ mysql ]
+database=app
+`
+	path := filepath.Join(t.TempDir(), "my.cnf")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	sections, err := parseINIFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []iniSection{
+		{Name: "client", Values: map[string]string{"user": "app", "password": `a"b\c`, "host": "db.example.com", "skip-ssl": ""}},
+		{Name: "mysql", Values: map[string]string{"database": "app"}},
+	}
+	if len(sections) != len(expected) {
+		t.Fatalf("got %d sections, expected %d: %+v", len(sections), len(expected), sections)
+	}
+	for i, section := range sections {
+		if section.Name != expected[i].Name || len(section.Values) != len(expected[i].Values) {
+			t.Fatalf("got section %+v, expected %+v", section, expected[i])
+		}
+		for key, value := range expected[i].Values {
+			if got, ok := section.Values[key]; !ok || got != value {
+				t.Errorf("[%s] %s: got %q, expected %q", section.Name, key, got, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// parsePlistFile decodes an XML property list into dictionaries
+// (map[string]any), arrays ([]any), strings, int64, float64 and bool values.
+// Binary property lists are rejected with a hint on how to convert them.
+func parsePlistFile(filePath string) (any, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if bytes.HasPrefix(data, []byte("bplist")) {
+		return nil, fmt.Errorf("binary property list, convert it first with: plutil -convert xml1 -o converted.plist %s", filePath)
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("empty property list")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse property list: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local == "plist" {
+			continue
+		}
+		return decodePlistValue(decoder, start)
+	}
+}
+
+// decodePlistValue decodes the element opened by start
+func decodePlistValue(decoder *xml.Decoder, start xml.StartElement) (any, error) {
+	switch start.Name.Local {
+	case "dict":
+		dict := make(map[string]any)
+		key := ""
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch element := token.(type) {
+			case xml.StartElement:
+				if element.Name.Local == "key" {
+					if err := decoder.DecodeElement(&key, &element); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				value, err := decodePlistValue(decoder, element)
+				if err != nil {
+					return nil, err
+				}
+				dict[key] = value
+			case xml.EndElement:
+				return dict, nil
+			}
+		}
+	case "array":
+		array := []any{}
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch element := token.(type) {
+			case xml.StartElement:
+				value, err := decodePlistValue(decoder, element)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, value)
+			case xml.EndElement:
+				return array, nil
+			}
+		}
+	case "true", "false":
+		if err := decoder.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	var text string
+	if err := decoder.DecodeElement(&text, &start); err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+
+	switch start.Name.Local {
+	case "integer":
+		return strconv.ParseInt(text, 10, 64)
+	case "real":
+		return strconv.ParseFloat(text, 64)
+	default:
+		// string, date and data values are kept as text
+		return text, nil
+	}
+}
+
+// plistString returns dict[key] when it holds a string or a number
+func plistString(dict map[string]any, key string) string {
+	switch value := dict[key].(type) {
+	case string:
+		return value
+	case int64:
+		return strconv.FormatInt(value, 10)
+	default:
+		return ""
+	}
+}
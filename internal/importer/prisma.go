@@ -0,0 +1,195 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+)
+
+// PrismaImporter implements the Importer interface for Prisma schema files. It
+// proposes one connection per datasource block.
+type PrismaImporter struct {
+	skipped []SkippedEntry
+}
+
+// NewPrismaImporter creates a new PrismaImporter instance
+func NewPrismaImporter() *PrismaImporter {
+	return &PrismaImporter{}
+}
+
+var (
+	prismaDatasourcePattern = regexp.MustCompile(`(?s)datasource\s+(\w+)\s*\{(.*?)\}`)
+	prismaFieldPattern      = regexp.MustCompile(`(?m)^\s*(\w+)\s*=\s*(.+?)\s*$`)
+	prismaEnvPattern        = regexp.MustCompile(`^env\(\s*"([^"]+)"\s*\)$`)
+)
+
+// Skipped returns the datasources the last Import call could not convert
+func (p *PrismaImporter) Skipped() []SkippedEntry {
+	return p.skipped
+}
+
+// Import imports connections from a schema.prisma file
+func (p *PrismaImporter) Import(filePath string, options ImportOptions) ([]config.Connection, error) {
+	p.skipped = nil
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prisma schema: %w", err)
+	}
+
+	schemaDir := filepath.Dir(filePath)
+	rootDir := schemaDir
+	if filepath.Base(schemaDir) == "prisma" {
+		rootDir = filepath.Dir(schemaDir)
+	}
+
+	lookup, err := prismaLookup(rootDir, schemaDir)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := options.ConnectionName
+	if prefix == "" {
+		prefix = projectName(rootDir)
+	}
+
+	connections := []config.Connection{}
+	for _, block := range prismaDatasourcePattern.FindAllStringSubmatch(stripPrismaComments(string(data)), -1) {
+		name := block[1]
+		fields := map[string]string{}
+		for _, field := range prismaFieldPattern.FindAllStringSubmatch(block[2], -1) {
+			fields[field[1]] = field[2]
+		}
+
+		conn, err := prismaConnection(fields, schemaDir, lookup)
+		if err != nil {
+			p.skipped = append(p.skipped, SkippedEntry{Name: name, Reason: err.Error()})
+			continue
+		}
+		if options.DatabaseType != "" && conn.Type != options.DatabaseType {
+			continue
+		}
+
+		conn.Name = name
+		if prefix != "" {
+			conn.Name = prefix
+			if name != "db" {
+				conn.Name = prefix + "-" + name
+			}
+		}
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
+
+// prismaLookup resolves env() references like the Prisma CLI: the shell
+// environment, then the project root .env, then a .env next to the schema.
+func prismaLookup(rootDir, schemaDir string) (LookupFunc, error) {
+	rootLookup, err := ProjectLookup(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if schemaDir == rootDir {
+		return rootLookup, nil
+	}
+
+	schemaEnv := map[string]string{}
+	schemaEnvPath := filepath.Join(schemaDir, ".env")
+	if _, err := os.Stat(schemaEnvPath); err == nil {
+		parsed, err := ParseEnvFile(schemaEnvPath)
+		if err != nil {
+			return nil, err
+		}
+		schemaEnv = parsed
+	}
+
+	return func(name string) (string, bool) {
+		if value, ok := rootLookup(name); ok {
+			return value, true
+		}
+		value, ok := schemaEnv[name]
+		return value, ok
+	}, nil
+}
+
+func prismaConnection(fields map[string]string, schemaDir string, lookup LookupFunc) (config.Connection, error) {
+	provider, err := prismaValue(fields["provider"], lookup)
+	if err != nil {
+		return config.Connection{}, err
+	}
+
+	dbType := ""
+	switch provider {
+	case "postgresql", "postgres":
+		dbType = config.TypePostgreSQL
+	case "mysql":
+		dbType = config.TypeMySQL
+	case "sqlite":
+		dbType = config.TypeSQLite
+	default:
+		return config.Connection{}, fmt.Errorf("unsupported provider '%s'", provider)
+	}
+
+	rawURL, err := prismaValue(fields["url"], lookup)
+	if err != nil {
+		return config.Connection{}, err
+	}
+	if rawURL == "" {
+		return config.Connection{}, fmt.Errorf("datasource url is empty")
+	}
+
+	if dbType == config.TypeSQLite {
+		// Prisma resolves file: URLs relative to the schema file
+		path := strings.TrimPrefix(strings.TrimPrefix(rawURL, "file:"), "sqlite:")
+		if query := strings.Index(path, "?"); query >= 0 {
+			path = path[:query]
+		}
+		absPath, err := connection.ExpandPath(path, schemaDir)
+		if err != nil {
+			return config.Connection{}, err
+		}
+		return config.Connection{Type: dbType, Database: absPath}, nil
+	}
+
+	return parseConnectionString(rawURL, dbType)
+}
+
+// prismaValue evaluates a datasource field: a string literal or env("KEY")
+func prismaValue(raw string, lookup LookupFunc) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if match := prismaEnvPattern.FindStringSubmatch(raw); match != nil {
+		value, ok := lookup(match[1])
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", match[1])
+		}
+		return value, nil
+	}
+	if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' {
+		return raw[1 : len(raw)-1], nil
+	}
+	return "", fmt.Errorf("unsupported value %s", raw)
+}
+
+// stripPrismaComments removes // comments, leaving string literals intact
+func stripPrismaComments(schema string) string {
+	lines := strings.Split(schema, "\n")
+	for i, line := range lines {
+		inString := false
+		for j := 0; j+1 < len(line); j++ {
+			switch {
+			case line[j] == '"' && (j == 0 || line[j-1] != '\\'):
+				inString = !inString
+			case !inString && line[j] == '/' && line[j+1] == '/':
+				lines[i] = line[:j]
+				j = len(line)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,326 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RailsImporter implements the Importer interface for Rails config/database.yml
+// files. It proposes one connection per environment, or per database for
+// environments with several databases.
+type RailsImporter struct {
+	skipped []SkippedEntry
+}
+
+// NewRailsImporter creates a new RailsImporter instance
+func NewRailsImporter() *RailsImporter {
+	return &RailsImporter{}
+}
+
+var (
+	erbTagPattern = regexp.MustCompile(`(?s)<%(=?)(.*?)-?%>`)
+	// ENV["KEY"], ENV.fetch("KEY"), ENV.fetch("KEY", "default") and
+	// ENV.fetch("KEY") { "default" }
+	erbEnvPattern     = regexp.MustCompile(`ENV(?:\[\s*["']([^"']+)["']\s*\]|\.fetch\(\s*["']([^"']+)["']\s*(?:,\s*([^)]*))?\)(?:\s*\{\s*([^}]*)\})?)`)
+	erbDefaultPattern = regexp.MustCompile(`\|\|\s*(.+)$`)
+)
+
+// Skipped returns the entries the last Import call could not convert
+func (r *RailsImporter) Skipped() []SkippedEntry {
+	return r.skipped
+}
+
+// Import imports connections from a Rails database.yml file
+func (r *RailsImporter) Import(filePath string, options ImportOptions) ([]config.Connection, error) {
+	r.skipped = nil
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database.yml: %w", err)
+	}
+
+	rootDir := railsRoot(filePath)
+	lookup, err := ProjectLookup(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := renderERB(string(data), lookup)
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(rendered), &document); err != nil {
+		return nil, fmt.Errorf("failed to parse database.yml: %w", err)
+	}
+
+	var environments map[string]map[string]any
+	if err := document.Decode(&environments); err != nil {
+		return nil, fmt.Errorf("failed to parse database.yml: %w", err)
+	}
+
+	// Anchored top-level entries such as "default: &default" are templates
+	// merged into the real environments. An anchored environment such as
+	// "development: &dev" names a database and is kept.
+	if len(document.Content) > 0 {
+		root := document.Content[0]
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			name := root.Content[i].Value
+			if root.Content[i+1].Anchor != "" && (name == "default" || !hasRailsDatabase(environments[name])) {
+				delete(environments, name)
+			}
+		}
+	}
+
+	prefix := options.ConnectionName
+	if prefix == "" {
+		prefix = projectName(rootDir)
+	}
+
+	envNames := make([]string, 0, len(environments))
+	for name := range environments {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+
+	connections := []config.Connection{}
+	for _, envName := range envNames {
+		settings := environments[envName]
+		if settings == nil {
+			continue
+		}
+
+		entries := map[string]map[string]any{}
+		if isRailsDatabaseConfig(settings) {
+			entries[envName] = settings
+		} else {
+			// Multiple databases: development: { primary: {...}, cache: {...} }
+			for dbName, value := range settings {
+				if nested, ok := value.(map[string]any); ok && isRailsDatabaseConfig(nested) {
+					entries[envName+"-"+dbName] = nested
+				}
+			}
+		}
+
+		entryNames := make([]string, 0, len(entries))
+		for name := range entries {
+			entryNames = append(entryNames, name)
+		}
+		sort.Strings(entryNames)
+
+		for _, entryName := range entryNames {
+			conn, err := railsConnection(entries[entryName], rootDir)
+			if err != nil {
+				r.skipped = append(r.skipped, SkippedEntry{Name: entryName, Reason: err.Error()})
+				continue
+			}
+			if options.DatabaseType != "" && conn.Type != options.DatabaseType {
+				continue
+			}
+
+			conn.Name = entryName
+			if prefix != "" {
+				conn.Name = prefix + "-" + entryName
+			}
+			connections = append(connections, conn)
+		}
+	}
+
+	return connections, nil
+}
+
+// railsRoot returns the application root for a database.yml path, which is the
+// parent of the config directory.
+func railsRoot(filePath string) string {
+	dir := filepath.Dir(filePath)
+	if filepath.Base(dir) == "config" {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+// renderERB evaluates the small subset of ERB that database.yml files use:
+// <%= ENV[...] %> lookups with optional defaults. Other output tags render as
+// empty strings and control tags are dropped.
+func renderERB(template string, lookup LookupFunc) string {
+	return erbTagPattern.ReplaceAllStringFunc(template, func(tag string) string {
+		parts := erbTagPattern.FindStringSubmatch(tag)
+		if parts[1] != "=" {
+			return ""
+		}
+		return evaluateERBExpression(strings.TrimSpace(parts[2]), lookup)
+	})
+}
+
+func evaluateERBExpression(expr string, lookup LookupFunc) string {
+	if literal, ok := rubyLiteral(expr); ok {
+		return literal
+	}
+
+	match := erbEnvPattern.FindStringSubmatch(expr)
+	if match == nil {
+		return ""
+	}
+
+	key := match[1]
+	if key == "" {
+		key = match[2]
+	}
+	if value, ok := lookup(key); ok {
+		return value
+	}
+
+	for _, fallback := range []string{match[3], match[4]} {
+		if literal, ok := rubyLiteral(strings.TrimSpace(fallback)); ok {
+			return literal
+		}
+	}
+
+	if defaultMatch := erbDefaultPattern.FindStringSubmatch(expr); defaultMatch != nil {
+		if literal, ok := rubyLiteral(strings.TrimSpace(defaultMatch[1])); ok {
+			return literal
+		}
+	}
+
+	return ""
+}
+
+// rubyLiteral returns the value of a quoted string or integer literal
+func rubyLiteral(expr string) (string, bool) {
+	if len(expr) >= 2 {
+		quote := expr[0]
+		if (quote == '"' || quote == '\'') && expr[len(expr)-1] == quote {
+			return expr[1 : len(expr)-1], true
+		}
+	}
+	if _, err := strconv.Atoi(expr); err == nil {
+		return expr, true
+	}
+	return "", false
+}
+
+func isRailsDatabaseConfig(settings map[string]any) bool {
+	_, hasAdapter := settings["adapter"]
+	_, hasURL := settings["url"]
+	return hasAdapter || hasURL
+}
+
+// hasRailsDatabase tells whether an environment names a database, directly or
+// in one of its nested database configurations
+func hasRailsDatabase(settings map[string]any) bool {
+	if railsString(settings, "database") != "" || railsString(settings, "url") != "" {
+		return true
+	}
+	for _, value := range settings {
+		if nested, ok := value.(map[string]any); ok && isRailsDatabaseConfig(nested) && hasRailsDatabase(nested) {
+			return true
+		}
+	}
+	return false
+}
+
+func railsConnection(settings map[string]any, rootDir string) (config.Connection, error) {
+	conn := config.Connection{}
+
+	if rawURL := railsString(settings, "url"); rawURL != "" {
+		parsed, err := parseConnectionString(rawURL, "")
+		if err != nil {
+			return config.Connection{}, err
+		}
+		conn = parsed
+	}
+
+	if adapter := railsString(settings, "adapter"); adapter != "" {
+		switch adapter {
+		case "postgresql", "postgis":
+			conn.Type = config.TypePostgreSQL
+		case "mysql2", "trilogy":
+			conn.Type = config.TypeMySQL
+		case "sqlite3":
+			conn.Type = config.TypeSQLite
+		default:
+			return config.Connection{}, fmt.Errorf("unsupported adapter '%s'", adapter)
+		}
+	}
+	if conn.Type == "" {
+		return config.Connection{}, fmt.Errorf("no adapter or url")
+	}
+
+	// Keys next to url override the parts of the url, as in Rails
+	setIfPresent := func(target *string, key string) {
+		if value := railsString(settings, key); value != "" {
+			*target = value
+		}
+	}
+	setIfPresent(&conn.Host, "host")
+	setIfPresent(&conn.Database, "database")
+	setIfPresent(&conn.Username, "username")
+	setIfPresent(&conn.Password, "password")
+	setIfPresent(&conn.Socket, "socket")
+	setIfPresent(&conn.SSLRootCert, "sslrootcert")
+	setIfPresent(&conn.SSLCert, "sslcert")
+	setIfPresent(&conn.SSLKey, "sslkey")
+
+	if value := railsString(settings, "sslmode"); value != "" {
+		mode, err := parseSSLMode(value)
+		if err != nil {
+			return config.Connection{}, err
+		}
+		conn.SSLMode = mode
+	}
+
+	if portStr := railsString(settings, "port"); portStr != "" {
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return config.Connection{}, fmt.Errorf("invalid port '%s'", portStr)
+		}
+		conn.Port = port
+	}
+
+	if conn.Type == config.TypeSQLite {
+		if conn.Database == "" {
+			return config.Connection{}, fmt.Errorf("no database file")
+		}
+		path, err := connection.ExpandPath(conn.Database, rootDir)
+		if err != nil {
+			return config.Connection{}, err
+		}
+		conn.Database = path
+		conn.Host = ""
+		conn.Port = 0
+		return conn, nil
+	}
+
+	if conn.Database == "" {
+		return config.Connection{}, fmt.Errorf("no database name")
+	}
+	if conn.Host == "" && conn.Socket == "" {
+		conn.Host = "localhost"
+	}
+	if conn.Port == 0 {
+		conn.Port = 5432
+		if conn.Type == config.TypeMySQL {
+			conn.Port = 3306
+		}
+	}
+
+	return conn, nil
+}
+
+// railsString returns a database.yml value as a string, whatever YAML type it
+// was decoded as.
+func railsString(settings map[string]any, key string) string {
+	value, ok := settings[key]
+	if !ok || value == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(value))
+}
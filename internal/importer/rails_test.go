@@ -0,0 +1,80 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRailsImportKeepsAnchoredEnvironments(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		expected []string
+	}{
+		{
+			name: "default template",
+			yaml: `default: &default
+  adapter: postgresql
+  host: localhost
+development:
+  <<: *default
+  database: app_dev
+`,
+			expected: []string{"app-development"},
+		},
+		{
+			name: "anchored environment",
+			yaml: `development: &dev
+  adapter: postgresql
+  host: localhost
+  database: app_dev
+test:
+  <<: *dev
+  database: app_test
+`,
+			expected: []string{"app-development", "app-test"},
+		},
+		{
+			name: "anchored template without a database",
+			yaml: `base: &base
+  adapter: mysql2
+  host: localhost
+production:
+  <<: *base
+  database: app
+`,
+			expected: []string{"app-production"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "config")
+			if err := os.Mkdir(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, "database.yml")
+			if err := os.WriteFile(path, []byte(test.yaml), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			connections, err := NewRailsImporter().Import(path, ImportOptions{ConnectionName: "app"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := []string{}
+			for _, conn := range connections {
+				names = append(names, conn.Name)
+			}
+			if len(names) != len(test.expected) {
+				t.Fatalf("got connections %v, expected %v", names, test.expected)
+			}
+			for i := range names {
+				if names[i] != test.expected[i] {
+					t.Fatalf("got connections %v, expected %v", names, test.expected)
+				}
+			}
+		})
+	}
+}
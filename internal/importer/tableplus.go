@@ -0,0 +1,141 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"dbear/internal/config"
+)
+
+// TablePlusImporter implements the Importer interface for TablePlus'
+// Connections.plist. Group names are read from the ConnectionGroups.plist next
+// to it and become connection tags. Passwords live in the macOS keychain and
+// are never available.
+type TablePlusImporter struct {
+	skipped []SkippedEntry
+}
+
+// NewTablePlusImporter creates a new TablePlusImporter instance
+func NewTablePlusImporter() *TablePlusImporter {
+	return &TablePlusImporter{}
+}
+
+// TablePlusPath returns the location of TablePlus' connection list
+func TablePlusPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "Connections.plist"
+	}
+	return filepath.Join(homeDir, "Library/Application Support/com.tinyapp.TablePlus/Data/Connections.plist")
+}
+
+// Skipped returns the connections the last Import call could not convert
+func (t *TablePlusImporter) Skipped() []SkippedEntry {
+	return t.skipped
+}
+
+// Import imports connections from a TablePlus Connections.plist file
+func (t *TablePlusImporter) Import(filePath string, options ImportOptions) ([]config.Connection, error) {
+	t.skipped = nil
+
+	root, err := parsePlistFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	entries, ok := root.([]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected TablePlus connection list format")
+	}
+
+	// The group file is optional: connections are simply left untagged
+	groups := make(map[string]string)
+	if groupRoot, err := parsePlistFile(filepath.Join(filepath.Dir(filePath), "ConnectionGroups.plist")); err == nil {
+		groupEntries, _ := groupRoot.([]any)
+		for _, entry := range groupEntries {
+			if group, ok := entry.(map[string]any); ok {
+				groups[plistString(group, "ID")] = plistString(group, "Name")
+			}
+		}
+	}
+
+	baseDir := filepath.Dir(filePath)
+	connections := []config.Connection{}
+	for _, entry := range entries {
+		dict, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		conn, err := tablePlusConnection(dict, baseDir)
+		if err == nil {
+			err = checkPassword(conn, options, "stored in the macOS keychain")
+		}
+		if err != nil {
+			t.skipped = append(t.skipped, SkippedEntry{Name: plistString(dict, "ConnectionName"), Reason: err.Error()})
+			continue
+		}
+		if options.DatabaseType != "" && conn.Type != options.DatabaseType {
+			continue
+		}
+		if group := groups[plistString(dict, "GroupID")]; group != "" {
+			conn.Tags = []string{group}
+		}
+
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
+
+// tablePlusConnection converts one entry of the connection list
+func tablePlusConnection(dict map[string]any, baseDir string) (config.Connection, error) {
+	driver := plistString(dict, "Driver")
+	dbType := driverType(driver)
+	if dbType == "" {
+		return config.Connection{}, fmt.Errorf("unsupported driver %s", driver)
+	}
+	if useSSH, _ := dict["isOverSSH"].(bool); useSSH {
+		return config.Connection{}, fmt.Errorf("connects through an SSH tunnel, which is not supported")
+	}
+
+	conn := config.Connection{
+		Name: plistString(dict, "ConnectionName"),
+		Type: dbType,
+	}
+
+	if dbType == config.TypeSQLite {
+		path := plistString(dict, "DatabasePath")
+		if path == "" {
+			return config.Connection{}, fmt.Errorf("no database file")
+		}
+		conn.Database = path
+		if !filepath.IsAbs(path) {
+			conn.Database = filepath.Join(baseDir, path)
+		}
+		return conn, nil
+	}
+
+	conn.Host = plistString(dict, "DatabaseHost")
+	conn.Database = plistString(dict, "DatabaseName")
+	conn.Username = plistString(dict, "DatabaseUser")
+	if useSocket, _ := dict["isUseSocket"].(bool); useSocket {
+		conn.Socket = plistString(dict, "DatabaseSocket")
+		conn.Host = ""
+	}
+
+	if port := plistString(dict, "DatabasePort"); port != "" {
+		parsed, err := strconv.Atoi(port)
+		if err != nil {
+			return config.Connection{}, fmt.Errorf("invalid port %s", port)
+		}
+		conn.Port = parsed
+	} else if dbType == config.TypePostgreSQL {
+		conn.Port = 5432
+	} else {
+		conn.Port = 3306
+	}
+
+	return conn, nil
+}
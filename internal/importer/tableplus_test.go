@@ -0,0 +1,183 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dbear/internal/config"
+)
+
+const tablePlusConnections = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<array>
+	<dict>
+		<key>ConnectionName</key>
+		<string>Shop</string>
+		<key>Driver</key>
+		<string>PostgreSQL</string>
+		<key>DatabaseHost</key>
+		<string>db.example.com</string>
+		<key>DatabasePort</key>
+		<string>5433</string>
+		<key>DatabaseName</key>
+		<string>shop</string>
+		<key>GroupID</key>
+		<string>G1</string>
+		<key>isOverSSH</key>
+		<false/>
+	</dict>
+	<dict>
+		<key>ConnectionName</key>
+		<string>Local MySQL</string>
+		<key>Driver</key>
+		<string>MySQL</string>
+		<key>DatabaseHost</key>
+		<string>127.0.0.1</string>
+		<key>DatabasePort</key>
+		<integer>3306</integer>
+		<key>DatabaseSocket</key>
+		<string>/tmp/mysql.sock</string>
+		<key>isUseSocket</key>
+		<true/>
+	</dict>
+	<dict>
+		<key>ConnectionName</key>
+		<string>Notes</string>
+		<key>Driver</key>
+		<string>SQLite</string>
+		<key>DatabasePath</key>
+		<string>notes.db</string>
+	</dict>
+	<dict>
+		<key>ConnectionName</key>
+		<string>Bastion</string>
+		<key>Driver</key>
+		<string>PostgreSQL</string>
+		<key>isOverSSH</key>
+		<true/>
+	</dict>
+	<dict>
+		<key>ConnectionName</key>
+		<string>Cache</string>
+		<key>Driver</key>
+		<string>Redis</string>
+	</dict>
+</array>
+</plist>
+`
+
+const tablePlusGroups = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<array>
+	<dict>
+		<key>ID</key>
+		<string>G1</string>
+		<key>Name</key>
+		<string>Production</string>
+	</dict>
+</array>
+</plist>
+`
+
+func TestTablePlusImport(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Connections.plist":      tablePlusConnections,
+		"ConnectionGroups.plist": tablePlusGroups,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tp := NewTablePlusImporter()
+	connections, err := tp.Import(filepath.Join(dir, "Connections.plist"), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []config.Connection{
+		{Name: "Shop", Type: config.TypePostgreSQL, Host: "db.example.com", Port: 5433, Database: "shop", Tags: []string{"Production"}},
+		{Name: "Local MySQL", Type: config.TypeMySQL, Socket: "/tmp/mysql.sock", Port: 3306},
+		{Name: "Notes", Type: config.TypeSQLite, Database: filepath.Join(dir, "notes.db")},
+	}
+	if len(connections) != len(expected) {
+		t.Fatalf("got %d connections, expected %d: %+v", len(connections), len(expected), connections)
+	}
+	for i, conn := range connections {
+		want := expected[i]
+		if conn.Name != want.Name || conn.Type != want.Type || conn.Host != want.Host || conn.Socket != want.Socket ||
+			conn.Port != want.Port || conn.Database != want.Database || strings.Join(conn.Tags, ",") != strings.Join(want.Tags, ",") {
+			t.Errorf("got connection %+v, expected %+v", conn, want)
+		}
+	}
+
+	skipped := map[string]string{}
+	for _, entry := range tp.Skipped() {
+		skipped[entry.Name] = entry.Reason
+	}
+	if len(skipped) != 2 || !strings.Contains(skipped["Bastion"], "SSH") || !strings.Contains(skipped["Cache"], "unsupported driver") {
+		t.Fatalf("unexpected skipped connections: %v", skipped)
+	}
+}
+
+func TestParsePlistFile(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>name</key>
+	<string> padded </string>
+	<key>count</key>
+	<integer>42</integer>
+	<key>ratio</key>
+	<real>0.5</real>
+	<key>enabled</key>
+	<true/>
+	<key>nested</key>
+	<array>
+		<false/>
+		<dict/>
+		<array/>
+	</array>
+</dict>
+</plist>
+`
+	path := filepath.Join(t.TempDir(), "test.plist")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := parsePlistFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict, ok := root.(map[string]any)
+	if !ok {
+		t.Fatalf("got %T, expected a dictionary", root)
+	}
+	if dict["name"] != "padded" || dict["count"] != int64(42) || dict["ratio"] != 0.5 || dict["enabled"] != true {
+		t.Fatalf("unexpected scalar values: %+v", dict)
+	}
+	if plistString(dict, "count") != "42" || plistString(dict, "enabled") != "" {
+		t.Fatalf("unexpected plistString results for %+v", dict)
+	}
+	nested, ok := dict["nested"].([]any)
+	if !ok || len(nested) != 3 || nested[0] != false {
+		t.Fatalf("unexpected nested array: %+v", dict["nested"])
+	}
+	if _, ok := nested[1].(map[string]any); !ok {
+		t.Fatalf("got %T, expected an empty dictionary", nested[1])
+	}
+
+	binary := filepath.Join(t.TempDir(), "binary.plist")
+	if err := os.WriteFile(binary, []byte("bplist00\x00"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parsePlistFile(binary); err == nil || !strings.Contains(err.Error(), "plutil") {
+		t.Fatalf("expected a conversion hint for a binary property list, got %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"dbear/internal/config"
+	"dbear/internal/query"
+)
+
+// sampleSize is how many records column types are inferred from
+const sampleSize = 1000
+
+// columnType is the inferred type of a column of a created table
+type columnType int
+
+const (
+	typeUnknown columnType = iota
+	typeInteger
+	typeNumeric
+	typeFloat
+	typeBoolean
+	typeDate
+	typeTimestamp
+	typeText
+)
+
+var decimalPattern = regexp.MustCompile(`^-?[0-9]+\.[0-9]+$`)
+
+// inferredColumn tracks the type of a column over the sampled values
+type inferredColumn struct {
+	typ columnType
+	// integerDigits and scale size MySQL DECIMAL columns
+	integerDigits int
+	scale         int
+}
+
+// add widens the type of the column to hold value
+func (c *inferredColumn) add(value any) {
+	if value == nil {
+		return
+	}
+
+	typ := valueType(value)
+	if typ == typeNumeric || typ == typeInteger {
+		digits := strings.TrimPrefix(textValue(value), "-")
+		integer, fraction, _ := strings.Cut(digits, ".")
+		c.integerDigits = max(c.integerDigits, len(integer))
+		c.scale = max(c.scale, len(fraction))
+	}
+
+	switch {
+	case c.typ == typeUnknown || c.typ == typ:
+		c.typ = typ
+	case isNumber(c.typ) && isNumber(typ):
+		// integer < numeric < float
+		c.typ = max(c.typ, typ)
+	case (c.typ == typeDate && typ == typeTimestamp) || (c.typ == typeTimestamp && typ == typeDate):
+		c.typ = typeTimestamp
+	default:
+		c.typ = typeText
+	}
+}
+
+func isNumber(typ columnType) bool {
+	return typ == typeInteger || typ == typeNumeric || typ == typeFloat
+}
+
+// valueType returns the narrowest type holding a single value
+func valueType(value any) columnType {
+	if _, ok := value.(bool); ok {
+		return typeBoolean
+	}
+
+	text := textValue(value)
+	if _, err := query.ConvertValue(query.KindInteger, text); err == nil {
+		return typeInteger
+	}
+	if decimalPattern.MatchString(text) {
+		return typeNumeric
+	}
+	if _, err := query.ConvertValue(query.KindFloat, text); err == nil {
+		return typeFloat
+	}
+	if _, err := query.ConvertValue(query.KindBoolean, text); err == nil {
+		return typeBoolean
+	}
+	if _, err := time.Parse("2006-01-02", text); err == nil {
+		return typeDate
+	}
+	if t, err := query.ConvertValue(query.KindTime, text); err == nil && t != nil {
+		return typeTimestamp
+	}
+	return typeText
+}
+
+// textValue returns the text of a string or json.Number value
+func textValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// kind returns the kind values of the column are converted to
+func (c *inferredColumn) kind() query.Kind {
+	switch c.typ {
+	case typeInteger:
+		return query.KindInteger
+	case typeNumeric:
+		return query.KindNumeric
+	case typeFloat:
+		return query.KindFloat
+	case typeBoolean:
+		return query.KindBoolean
+	case typeDate, typeTimestamp:
+		return query.KindTime
+	default:
+		return query.KindString
+	}
+}
+
+// sqlTypes are the column types declared for each inferred type
+var sqlTypes = map[string]map[columnType]string{
+	config.TypePostgreSQL: {
+		typeInteger:   "BIGINT",
+		typeNumeric:   "NUMERIC",
+		typeFloat:     "DOUBLE PRECISION",
+		typeBoolean:   "BOOLEAN",
+		typeDate:      "DATE",
+		typeTimestamp: "TIMESTAMPTZ",
+	},
+	config.TypeMySQL: {
+		typeInteger:   "BIGINT",
+		typeFloat:     "DOUBLE",
+		typeBoolean:   "BOOLEAN",
+		typeDate:      "DATE",
+		typeTimestamp: "DATETIME(6)",
+	},
+	config.TypeSQLite: {
+		typeInteger:   "INTEGER",
+		typeNumeric:   "NUMERIC",
+		typeFloat:     "REAL",
+		typeBoolean:   "BOOLEAN",
+		typeDate:      "DATE",
+		typeTimestamp: "DATETIME",
+	},
+}
+
+// sqlType returns the column type declared for the column on dbType. Columns
+// without values are TEXT.
+func (c *inferredColumn) sqlType(dbType string) string {
+	if dbType == config.TypeMySQL && c.typ == typeNumeric {
+		// Leave room for larger values than the sampled ones
+		scale := min(c.scale, 30)
+		return fmt.Sprintf("DECIMAL(%d,%d)", min(65, c.integerDigits+scale+10), scale)
+	}
+
+	if typ, ok := sqlTypes[dbType][c.typ]; ok {
+		return typ
+	}
+	return "TEXT"
+}
@@ -0,0 +1,352 @@
+package loader
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"dbear/internal/config"
+	"dbear/internal/query"
+	"dbear/internal/transfer"
+
+	"github.com/lib/pq"
+)
+
+// DefaultBatchSize is how many rows a multi-row INSERT holds by default
+const DefaultBatchSize = 1000
+
+// Options configures a load
+type Options struct {
+	Table string
+	// Mapping renames file columns to table columns. Columns mapped to an
+	// empty name are skipped, the others keep their name.
+	Mapping map[string]string
+	// Create creates the table from the column types inferred from the file
+	// when it does not exist. Values are converted to the inferred types only
+	// for a table created so; existing tables convert text themselves.
+	Create bool
+	// Truncate empties the table before loading
+	Truncate bool
+	// BatchSize is how many rows each INSERT holds, when COPY and LOAD DATA
+	// are not used
+	BatchSize int
+}
+
+// loader reads the rows of a source in the column order of the table
+type loader struct {
+	conn    config.Connection
+	source  Source
+	table   string
+	columns []string
+	// positions are the indexes of the loaded columns in the source records
+	positions []int
+	// kinds are the types values are converted to, only known when the load
+	// creates the table
+	kinds []query.Kind
+	// sampled holds the records read to infer the column types
+	sampled [][]any
+	rows    int64
+}
+
+// Load inserts the records of source into a table of conn in one transaction
+// and returns how many rows were loaded. PostgreSQL tables are loaded with
+// COPY, MySQL ones with LOAD DATA LOCAL INFILE when the server allows it and
+// SQLite ones with batched INSERTs. A table created by the load is created in
+// the transaction, or dropped when the load fails on MySQL, whose CREATE
+// TABLE commits right away.
+func Load(ctx context.Context, db *sql.DB, conn config.Connection, source Source, options Options) (count int64, err error) {
+	if conn.ReadOnly {
+		return 0, fmt.Errorf("refusing to load into %q: the connection is read-only", conn.Name)
+	}
+	if err := transfer.ValidateDestination(conn); err != nil {
+		return 0, err
+	}
+
+	l := &loader{conn: conn, source: source, table: query.QuoteIdentifier(conn.Type, options.Table)}
+	if err := l.mapColumns(options.Mapping); err != nil {
+		return 0, err
+	}
+
+	create := ""
+	if options.Create && !tableExists(ctx, db, l.table) {
+		if create, err = l.inferTable(); err != nil {
+			return 0, err
+		}
+	}
+
+	if create != "" && conn.Type == config.TypeMySQL {
+		if _, err := db.ExecContext(ctx, create); err != nil {
+			return 0, fmt.Errorf("failed to create table: %w", err)
+		}
+		defer func() {
+			if err != nil {
+				db.ExecContext(context.Background(), "DROP TABLE "+l.table)
+			}
+		}()
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if create != "" && conn.Type != config.TypeMySQL {
+		if _, err := tx.ExecContext(ctx, create); err != nil {
+			return 0, fmt.Errorf("failed to create table: %w", err)
+		}
+	}
+
+	if options.Truncate {
+		statement := "DELETE FROM " + l.table
+		if conn.Type == config.TypePostgreSQL {
+			statement = "TRUNCATE TABLE " + l.table
+		}
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return 0, fmt.Errorf("failed to truncate %s: %w", options.Table, err)
+		}
+	}
+
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	switch conn.Type {
+	case config.TypePostgreSQL:
+		err = l.copyRows(ctx, tx, options.Table)
+	case config.TypeMySQL:
+		if localInfileEnabled(ctx, tx) {
+			err = l.loadDataRows(ctx, tx)
+		} else {
+			err = l.insertRows(ctx, tx, batchSize, 65535)
+		}
+	case config.TypeSQLite:
+		err = l.insertRows(ctx, tx, batchSize, 32766)
+	default:
+		err = fmt.Errorf("unsupported database type: %s", conn.Type)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return l.rows, nil
+}
+
+// mapColumns picks the source columns to load and names their table columns
+func (l *loader) mapColumns(mapping map[string]string) error {
+	sourceColumns := l.source.Columns()
+	known := map[string]bool{}
+	for _, name := range sourceColumns {
+		known[name] = true
+	}
+	for name := range mapping {
+		if !known[name] {
+			return fmt.Errorf("mapped column %s is not in the file", name)
+		}
+	}
+
+	seen := map[string]bool{}
+	for i, name := range sourceColumns {
+		if renamed, ok := mapping[name]; ok {
+			if renamed == "" {
+				continue
+			}
+			name = renamed
+		}
+		if name == "" {
+			return fmt.Errorf("column %d of the file has no name", i+1)
+		}
+		if seen[name] {
+			return fmt.Errorf("column %s is loaded more than once", name)
+		}
+		seen[name] = true
+
+		l.columns = append(l.columns, name)
+		l.positions = append(l.positions, i)
+	}
+
+	if len(l.columns) == 0 {
+		return fmt.Errorf("no column to load")
+	}
+	return nil
+}
+
+// tableExists reports whether table, quoted, can be read from
+func tableExists(ctx context.Context, db *sql.DB, table string) bool {
+	rows, err := db.QueryContext(ctx, "SELECT 1 FROM "+table+" WHERE 1 = 0")
+	if err != nil {
+		return false
+	}
+	rows.Close()
+	return true
+}
+
+// inferTable samples the first records to infer the column types, which
+// values are then converted to, and returns the statement creating the table
+func (l *loader) inferTable() (string, error) {
+	inferred := make([]inferredColumn, len(l.columns))
+	for len(l.sampled) < sampleSize {
+		record, err := l.source.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("row %d: %w", len(l.sampled)+1, err)
+		}
+		l.sampled = append(l.sampled, record)
+		for i, position := range l.positions {
+			inferred[i].add(record[position])
+		}
+	}
+
+	definitions := make([]string, len(l.columns))
+	l.kinds = make([]query.Kind, len(l.columns))
+	for i, name := range l.columns {
+		definitions[i] = query.QuoteName(l.conn.Type, name) + " " + inferred[i].sqlType(l.conn.Type)
+		l.kinds[i] = inferred[i].kind()
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s (\n  %s\n)", l.table, strings.Join(definitions, ",\n  ")), nil
+}
+
+// next returns the values of the next row in the order of the table columns,
+// or io.EOF after the last one
+func (l *loader) next() ([]any, error) {
+	var record []any
+	if len(l.sampled) > 0 {
+		record = l.sampled[0]
+		l.sampled = l.sampled[1:]
+	} else {
+		var err error
+		record, err = l.source.Next()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", l.rows+1, err)
+		}
+	}
+
+	values := make([]any, len(l.positions))
+	for i, position := range l.positions {
+		value, err := l.convert(i, record[position])
+		if err != nil {
+			return nil, fmt.Errorf("row %d, column %s: %w", l.rows+1, l.columns[i], err)
+		}
+		values[i] = value
+	}
+	l.rows++
+	return values, nil
+}
+
+// convert turns a source value into the value inserted in column i. Values
+// are passed as text for the database to convert unless the table was
+// created from their inferred type.
+func (l *loader) convert(i int, value any) (any, error) {
+	if number, ok := value.(json.Number); ok {
+		value = number.String()
+	}
+	if l.kinds == nil || value == nil {
+		return value, nil
+	}
+
+	converted, err := query.ConvertValue(l.kinds[i], value)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite stores dates as they are written
+	if l.conn.Type == config.TypeSQLite && l.kinds[i] == query.KindTime {
+		return value, nil
+	}
+	return converted, nil
+}
+
+// copyRows streams the rows with COPY FROM STDIN
+func (l *loader) copyRows(ctx context.Context, tx *sql.Tx, table string) error {
+	statement := pq.CopyIn(table, l.columns...)
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		statement = pq.CopyInSchema(schema, name, l.columns...)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, statement)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for {
+		values, err := l.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.ExecContext(ctx, values...); err != nil {
+			return err
+		}
+	}
+
+	// Flush the buffered rows and wait for the server to check them
+	_, err = stmt.ExecContext(ctx)
+	return err
+}
+
+// insertRows inserts the rows with multi-row INSERTs holding up to batchSize
+// rows and maxParams parameters
+func (l *loader) insertRows(ctx context.Context, tx *sql.Tx, batchSize, maxParams int) error {
+	batchSize = max(1, min(batchSize, maxParams/len(l.columns)))
+
+	quoted := make([]string, len(l.columns))
+	for i, name := range l.columns {
+		quoted[i] = query.QuoteName(l.conn.Type, name)
+	}
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", l.table, strings.Join(quoted, ", "))
+	tuple := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(l.columns)), ", ") + ")"
+	statement := func(rows int) string {
+		return prefix + strings.TrimSuffix(strings.Repeat(tuple+", ", rows), ", ")
+	}
+
+	var full *sql.Stmt
+	defer func() {
+		if full != nil {
+			full.Close()
+		}
+	}()
+
+	batch := make([]any, 0, batchSize*len(l.columns))
+	for {
+		values, err := l.next()
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if err == nil {
+			batch = append(batch, values...)
+		}
+		rows := len(batch) / len(l.columns)
+
+		switch {
+		case rows == batchSize:
+			if full == nil {
+				if full, err = tx.PrepareContext(ctx, statement(batchSize)); err != nil {
+					return err
+				}
+			}
+			if _, err := full.ExecContext(ctx, batch...); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		case err == io.EOF && rows > 0:
+			_, err := tx.ExecContext(ctx, statement(rows), batch...)
+			return err
+		case err == io.EOF:
+			return nil
+		}
+	}
+}
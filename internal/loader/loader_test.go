@@ -0,0 +1,101 @@
+package loader
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"dbear/internal/config"
+
+	_ "modernc.org/sqlite"
+)
+
+// recordSource is a Source over records held in memory, failing after failAt
+// records when failAt is positive
+type recordSource struct {
+	columns []string
+	records [][]any
+	failAt  int
+	read    int
+}
+
+func (s *recordSource) Columns() []string { return s.columns }
+
+func (s *recordSource) Next() ([]any, error) {
+	if s.failAt > 0 && s.read == s.failAt {
+		return nil, fmt.Errorf("malformed record")
+	}
+	if s.read == len(s.records) {
+		return nil, io.EOF
+	}
+	s.read++
+	return s.records[s.read-1], nil
+}
+
+func (s *recordSource) Close() error { return nil }
+
+func openTestDB(t *testing.T) (*sql.DB, config.Connection) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, config.Connection{Name: "test", Type: config.TypeSQLite, Database: path}
+}
+
+func TestLoadCreatesTableOnlyWhenLoadSucceeds(t *testing.T) {
+	db, conn := openTestDB(t)
+	// The load fails after the sample, once the table is created
+	source := &recordSource{columns: []string{"id", "name"}, failAt: sampleSize + 10}
+	for i := 0; i < sampleSize+20; i++ {
+		source.records = append(source.records, []any{fmt.Sprint(i + 1), "name"})
+	}
+
+	if _, err := Load(context.Background(), db, conn, source, Options{Table: "people", Create: true}); err == nil {
+		t.Fatal("expected the load to fail")
+	}
+	if tableExists(context.Background(), db, `"people"`) {
+		t.Fatal("table created by a failed load was left behind")
+	}
+
+	source = &recordSource{columns: []string{"id", "name"}, records: [][]any{{"1", "ada"}, {"2", "grace"}}}
+	count, err := Load(context.Background(), db, conn, source, Options{Table: "people", Create: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("loaded %d rows, expected 2", count)
+	}
+	var typ string
+	if err := db.QueryRow(`SELECT typeof(id) FROM people LIMIT 1`).Scan(&typ); err != nil {
+		t.Fatal(err)
+	}
+	if typ != "integer" {
+		t.Fatalf("id stored as %s, expected the inferred integer", typ)
+	}
+}
+
+func TestLoadKeepsValuesAsTextForExistingTables(t *testing.T) {
+	db, conn := openTestDB(t)
+	if _, err := db.Exec(`CREATE TABLE codes (code TEXT, label TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+
+	source := &recordSource{columns: []string{"code", "label"}, records: [][]any{{"007", "bond"}, {"042", "answer"}}}
+	if _, err := Load(context.Background(), db, conn, source, Options{Table: "codes", Create: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	var code string
+	if err := db.QueryRow(`SELECT code FROM codes WHERE label = 'bond'`).Scan(&code); err != nil {
+		t.Fatal(err)
+	}
+	if code != "007" {
+		t.Fatalf("got code %q, expected the text of the file", code)
+	}
+}
@@ -0,0 +1,152 @@
+package loader
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"dbear/internal/query"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// readerHandlers numbers the reader handlers registered with the MySQL driver
+var readerHandlers atomic.Int64
+
+// localInfileEnabled reports whether the server accepts LOAD DATA LOCAL INFILE
+func localInfileEnabled(ctx context.Context, tx *sql.Tx) bool {
+	var enabled bool
+	if err := tx.QueryRowContext(ctx, "SELECT @@GLOBAL.local_infile").Scan(&enabled); err != nil {
+		return false
+	}
+	return enabled
+}
+
+// loadDataRows streams the rows to LOAD DATA LOCAL INFILE as CSV through a
+// reader registered with the driver
+func (l *loader) loadDataRows(ctx context.Context, tx *sql.Tx) error {
+	reader, writer := io.Pipe()
+	name := fmt.Sprintf("dbear-load-%d", readerHandlers.Add(1))
+	mysql.RegisterReaderHandler(name, func() io.Reader { return reader })
+	defer mysql.DeregisterReaderHandler(name)
+
+	written := make(chan error, 1)
+	go func() {
+		err := l.writeLoadData(writer)
+		writer.CloseWithError(err)
+		written <- err
+	}()
+
+	quoted := make([]string, len(l.columns))
+	for i, name := range l.columns {
+		quoted[i] = query.QuoteName(l.conn.Type, name)
+	}
+	statement := fmt.Sprintf(
+		`LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s CHARACTER SET utf8mb4 `+
+			`FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '"' ESCAPED BY '' `+
+			`LINES TERMINATED BY '\n' (%s)`,
+		name, l.table, strings.Join(quoted, ", "),
+	)
+	result, err := tx.ExecContext(ctx, statement)
+	// Unblock the writer when the server stopped reading early
+	reader.Close()
+	// A writer cut off by the server fails with io.ErrClosedPipe, which says
+	// less than the error of the statement; errors reading the input say more
+	writeErr := <-written
+	if writeErr != nil && !errors.Is(writeErr, io.ErrClosedPipe) {
+		return writeErr
+	}
+	if err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+
+	// LOCAL turns invalid values and duplicate keys into warnings, which must
+	// not go unnoticed
+	if loaded, err := result.RowsAffected(); err == nil && loaded != l.rows {
+		return fmt.Errorf("%d of %d rows were not loaded, duplicate keys may have been skipped", l.rows-loaded, l.rows)
+	}
+	return loadDataWarnings(ctx, tx)
+}
+
+// loadDataWarnings returns an error holding the first warnings of the load
+func loadDataWarnings(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, "SHOW WARNINGS LIMIT 3")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var messages []string
+	for rows.Next() {
+		var level, message string
+		var code int
+		if err := rows.Scan(&level, &code, &message); err != nil {
+			return err
+		}
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(messages) > 0 {
+		return fmt.Errorf("the server rejected values: %s", strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// writeLoadData writes the rows as the CSV expected by loadDataRows. Strings
+// are always enclosed in quotes, so that the bare word NULL is read as NULL.
+func (l *loader) writeLoadData(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	for {
+		values, err := l.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		for i, value := range values {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			out.WriteString(loadDataField(value))
+		}
+		if err := out.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+// loadDataField formats a single value for LOAD DATA
+func loadDataField(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return `"` + v.UTC().Format("2006-01-02 15:04:05.999999") + `"`
+	default:
+		return `"` + strings.ReplaceAll(query.FormatValue(v, ""), `"`, `""`) + `"`
+	}
+}
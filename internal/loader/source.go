@@ -0,0 +1,263 @@
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Source reads the records of a file one at a time. Values are nil, strings,
+// booleans or json.Number, in the order of Columns.
+type Source interface {
+	Columns() []string
+	// Next returns the next record, or io.EOF after the last one
+	Next() ([]any, error)
+	Close() error
+}
+
+// DetectFormat returns the format matching the extension of path, CSV unless
+// it is .json, .jsonl or .ndjson
+func DetectFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".jsonl", ".ndjson":
+		return FormatJSON
+	default:
+		return FormatCSV
+	}
+}
+
+// OpenSource opens a CSV file with a header row, or a JSON file holding an
+// array of objects or one object per line
+func OpenSource(path, format string) (Source, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	var source Source
+	switch format {
+	case FormatCSV:
+		source, err = newCSVSource(file)
+	case FormatJSON:
+		source, err = newJSONSource(file)
+	default:
+		err = fmt.Errorf("unsupported file format: %s", format)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return source, nil
+}
+
+// csvSource reads CSV records. Empty fields are NULL, as in PostgreSQL's COPY
+// and the CSV files of `dbear export`.
+type csvSource struct {
+	file    *os.File
+	reader  *csv.Reader
+	columns []string
+}
+
+func newCSVSource(file *os.File) (*csvSource, error) {
+	reader := csv.NewReader(bufio.NewReader(file))
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("the file is empty, a header row is expected")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	for i, name := range header {
+		columns[i] = strings.TrimSpace(name)
+	}
+	// Spreadsheet exports start with a byte order mark
+	if len(columns) > 0 {
+		columns[0] = strings.TrimPrefix(columns[0], "\ufeff")
+	}
+
+	return &csvSource{file: file, reader: reader, columns: columns}, nil
+}
+
+func (s *csvSource) Columns() []string {
+	return s.columns
+}
+
+func (s *csvSource) Next() ([]any, error) {
+	record, err := s.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]any, len(record))
+	for i, field := range record {
+		if field != "" {
+			values[i] = field
+		}
+	}
+	return values, nil
+}
+
+func (s *csvSource) Close() error {
+	return s.file.Close()
+}
+
+// jsonSource reads objects from a JSON array or a stream of objects. The keys
+// of the first object make the columns; later objects may leave some out.
+type jsonSource struct {
+	file    *os.File
+	decoder *json.Decoder
+	array   bool
+	columns []string
+	index   map[string]int
+	first   []any
+	objects int
+}
+
+func newJSONSource(file *os.File) (*jsonSource, error) {
+	reader := bufio.NewReader(file)
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	s := &jsonSource{file: file, decoder: decoder, index: map[string]int{}}
+
+	if bom, _ := reader.Peek(3); string(bom) == "\ufeff" {
+		reader.Discard(3)
+	}
+	start, err := reader.Peek(1)
+	for err == nil && bytes.ContainsAny(start, " \t\r\n") {
+		reader.ReadByte()
+		start, err = reader.Peek(1)
+	}
+	if err == io.EOF {
+		return nil, fmt.Errorf("the file is empty")
+	}
+	if start[0] == '[' {
+		s.array = true
+		if _, err := decoder.Token(); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	}
+
+	keys, values, err := s.readObject()
+	if err == io.EOF {
+		return nil, fmt.Errorf("the file holds no object")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		s.index[key] = i
+	}
+	s.columns = keys
+	s.first = values
+
+	return s, nil
+}
+
+func (s *jsonSource) Columns() []string {
+	return s.columns
+}
+
+func (s *jsonSource) Next() ([]any, error) {
+	if s.first != nil {
+		values := s.first
+		s.first = nil
+		return values, nil
+	}
+
+	keys, values, err := s.readObject()
+	if err != nil {
+		return nil, err
+	}
+
+	record := make([]any, len(s.columns))
+	for i, key := range keys {
+		at, ok := s.index[key]
+		if !ok {
+			return nil, fmt.Errorf("object %d: key %s is not in the first object", s.objects, key)
+		}
+		record[at] = values[i]
+	}
+	return record, nil
+}
+
+// readObject decodes the next object, keeping the order of its keys. Nested
+// objects and arrays are kept as JSON text.
+func (s *jsonSource) readObject() ([]string, []any, error) {
+	if s.array && !s.decoder.More() {
+		return nil, nil, io.EOF
+	}
+
+	s.objects++
+	token, err := s.decoder.Token()
+	if err == io.EOF {
+		return nil, nil, io.EOF
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("object %d: invalid JSON: %w", s.objects, err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("object %d: expected an object, got %v", s.objects, token)
+	}
+
+	keys := []string{}
+	values := []any{}
+	for s.decoder.More() {
+		token, err := s.decoder.Token()
+		if err != nil {
+			return nil, nil, fmt.Errorf("object %d: invalid JSON: %w", s.objects, err)
+		}
+		key, _ := token.(string)
+
+		var raw json.RawMessage
+		if err := s.decoder.Decode(&raw); err != nil {
+			return nil, nil, fmt.Errorf("object %d: invalid JSON: %w", s.objects, err)
+		}
+		value, err := jsonValue(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("object %d, key %s: %w", s.objects, key, err)
+		}
+
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	if _, err := s.decoder.Token(); err != nil {
+		return nil, nil, fmt.Errorf("object %d: invalid JSON: %w", s.objects, err)
+	}
+
+	return keys, values, nil
+}
+
+// jsonValue converts a raw JSON value into a record value
+func jsonValue(raw json.RawMessage) (any, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return string(trimmed), nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (s *jsonSource) Close() error {
+	return s.file.Close()
+}
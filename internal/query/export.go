@@ -0,0 +1,236 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"dbear/internal/config"
+)
+
+// Kind is the type an exported column is written as
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInteger
+	KindFloat
+	// KindNumeric holds exact decimals, written as strings to keep their precision
+	KindNumeric
+	KindBoolean
+	KindTime
+	KindBinary
+)
+
+// Column is a column of an export
+type Column struct {
+	Name string
+	Kind Kind
+}
+
+// RowWriter writes the rows of an export as they are read. Values are nil or
+// of the Go type of their column kind: string for KindString and KindNumeric,
+// int64, float64, bool, time.Time and []byte for the others.
+type RowWriter interface {
+	WriteHeader(columns []Column) error
+	WriteRow(values []any) error
+	Close() error
+}
+
+// Export streams the rows of statement into w, holding one row at a time, and
+// returns how many were written. w is closed on success.
+func Export(ctx context.Context, db *sql.DB, statement string, w RowWriter) (int64, error) {
+	rows, err := db.QueryContext(ctx, statement)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	columns := make([]Column, len(columnTypes))
+	for i, columnType := range columnTypes {
+		columns[i] = Column{Name: columnType.Name(), Kind: columnKind(columnType.DatabaseTypeName())}
+	}
+	if err := w.WriteHeader(columns); err != nil {
+		return 0, err
+	}
+
+	values := make([]any, len(columns))
+	pointers := make([]any, len(values))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	var count int64
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return count, err
+		}
+		for i, value := range values {
+			converted, err := ConvertValue(columns[i].Kind, value)
+			if err != nil {
+				return count, fmt.Errorf("row %d, column %s: %w", count+1, columns[i].Name, err)
+			}
+			values[i] = converted
+		}
+		if err := w.WriteRow(values); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	return count, w.Close()
+}
+
+// columnKind maps the database type name drivers report onto a Kind.
+// SQLite reports the declared type, which may carry a size.
+func columnKind(databaseType string) Kind {
+	name := strings.ToUpper(strings.TrimSpace(databaseType))
+	if open := strings.IndexByte(name, '('); open >= 0 {
+		name = strings.TrimSpace(name[:open])
+	}
+
+	switch name {
+	case "INT", "INTEGER", "INT2", "INT4", "INT8", "SMALLINT", "BIGINT", "TINYINT", "MEDIUMINT",
+		"SERIAL", "BIGSERIAL", "SMALLSERIAL", "YEAR",
+		"UNSIGNED INT", "UNSIGNED SMALLINT", "UNSIGNED TINYINT", "UNSIGNED MEDIUMINT":
+		return KindInteger
+	case "UNSIGNED BIGINT", "NUMERIC", "DECIMAL", "UNSIGNED DECIMAL":
+		// Unsigned BIGINT values may not fit an int64
+		return KindNumeric
+	case "FLOAT", "FLOAT4", "FLOAT8", "DOUBLE", "DOUBLE PRECISION", "REAL", "UNSIGNED FLOAT", "UNSIGNED DOUBLE":
+		return KindFloat
+	case "BOOL", "BOOLEAN":
+		return KindBoolean
+	case "TIMESTAMP", "TIMESTAMPTZ", "DATETIME", "DATE":
+		return KindTime
+	case "BYTEA", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY":
+		return KindBinary
+	default:
+		return KindString
+	}
+}
+
+// timeLayouts are the text forms of timestamps and dates drivers return,
+// MySQL without parseTime and SQLite in particular
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// ConvertValue converts a scanned or parsed value to the Go type of kind
+func ConvertValue(kind Kind, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if b, ok := value.([]byte); ok && kind != KindBinary {
+		value = string(b)
+	}
+
+	switch kind {
+	case KindInteger:
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case float64:
+			if v == float64(int64(v)) {
+				return int64(v), nil
+			}
+		case bool:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case string:
+			return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		}
+	case KindFloat:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int64:
+			return float64(v), nil
+		case string:
+			return strconv.ParseFloat(strings.TrimSpace(v), 64)
+		}
+	case KindNumeric:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case int64:
+			return strconv.FormatInt(v, 10), nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		}
+	case KindBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case int64:
+			return v != 0, nil
+		case string:
+			return strconv.ParseBool(strings.TrimSpace(v))
+		}
+	case KindTime:
+		switch v := value.(type) {
+		case time.Time:
+			return v, nil
+		case int64:
+			// SQLite stores timestamps as Unix times too
+			return time.Unix(v, 0).UTC(), nil
+		case string:
+			// MySQL zero dates have no time.Time equivalent
+			if strings.HasPrefix(v, "0000-00-00") {
+				return nil, nil
+			}
+			for _, layout := range timeLayouts {
+				if t, err := time.Parse(layout, v); err == nil {
+					return t, nil
+				}
+			}
+		}
+	case KindBinary:
+		switch v := value.(type) {
+		case []byte:
+			return v, nil
+		case string:
+			return []byte(v), nil
+		}
+	default:
+		return FormatValue(value, ""), nil
+	}
+
+	return nil, fmt.Errorf("cannot convert %T value %v", value, value)
+}
+
+// QuoteIdentifier quotes a table name, which may be qualified by its schema,
+// for dbType
+func QuoteIdentifier(dbType, name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = QuoteName(dbType, part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// QuoteName quotes a single identifier, such as a column name, for dbType
+func QuoteName(dbType, name string) string {
+	quote := `"`
+	if dbType == config.TypeMySQL {
+		quote = "`"
+	}
+	return quote + strings.ReplaceAll(name, quote, quote+quote) + quote
+}
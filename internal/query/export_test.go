@@ -0,0 +1,157 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"dbear/internal/config"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestColumnKind(t *testing.T) {
+	tests := []struct {
+		databaseType string
+		expected     Kind
+	}{
+		{"INT4", KindInteger},
+		{"bigint", KindInteger},
+		{"INTEGER", KindInteger},
+		{"UNSIGNED INT", KindInteger},
+		{"UNSIGNED BIGINT", KindNumeric},
+		{"DECIMAL(10, 2)", KindNumeric},
+		{"NUMERIC", KindNumeric},
+		{"FLOAT8", KindFloat},
+		{"DOUBLE PRECISION", KindFloat},
+		{"BOOL", KindBoolean},
+		{"TIMESTAMPTZ", KindTime},
+		{"DATE", KindTime},
+		{"BYTEA", KindBinary},
+		{"VARBINARY(16)", KindBinary},
+		{"VARCHAR(255)", KindString},
+		{"JSONB", KindString},
+		{"", KindString},
+	}
+
+	for _, test := range tests {
+		if kind := columnKind(test.databaseType); kind != test.expected {
+			t.Errorf("%q: got kind %d, expected %d", test.databaseType, kind, test.expected)
+		}
+	}
+}
+
+func TestConvertValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     Kind
+		value    any
+		expected any
+		valid    bool
+	}{
+		{"nil", KindInteger, nil, nil, true},
+		{"integer text", KindInteger, []byte(" 42 "), int64(42), true},
+		{"whole float", KindInteger, 3.0, int64(3), true},
+		{"fractional float", KindInteger, 3.5, nil, false},
+		{"boolean integer", KindInteger, true, int64(1), true},
+		{"float text", KindFloat, "1.25", 1.25, true},
+		{"integer float", KindFloat, int64(2), 2.0, true},
+		{"numeric text", KindNumeric, []byte("12345678901234567890.01"), "12345678901234567890.01", true},
+		{"numeric float", KindNumeric, 0.1, "0.1", true},
+		{"boolean text", KindBoolean, "t", true, true},
+		{"boolean integer", KindBoolean, int64(0), false, true},
+		{"invalid boolean", KindBoolean, "maybe", nil, false},
+		{"timestamp text", KindTime, "2024-01-02 03:04:05", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), true},
+		{"timestamp with zone", KindTime, "2024-01-02 03:04:05+00", time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", 0)), true},
+		{"date text", KindTime, "2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), true},
+		{"unix time", KindTime, int64(0), time.Unix(0, 0).UTC(), true},
+		{"zero date", KindTime, "0000-00-00 00:00:00", nil, true},
+		{"invalid time", KindTime, "yesterday", nil, false},
+		{"binary text", KindBinary, "ab", []byte("ab"), true},
+		{"string", KindString, []byte("ada"), "ada", true},
+		{"string from integer", KindString, int64(7), "7", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			converted, err := ConvertValue(test.kind, test.value)
+			if !test.valid {
+				if err == nil {
+					t.Fatalf("expected an error, got %#v", converted)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expectedTime, ok := test.expected.(time.Time); ok {
+				if convertedTime, ok := converted.(time.Time); !ok || !convertedTime.Equal(expectedTime) {
+					t.Fatalf("got %#v, expected %s", converted, expectedTime)
+				}
+				return
+			}
+			if !reflect.DeepEqual(converted, test.expected) {
+				t.Fatalf("got %#v, expected %#v", converted, test.expected)
+			}
+		})
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		dbType   string
+		name     string
+		expected string
+	}{
+		{config.TypePostgreSQL, "users", `"users"`},
+		{config.TypePostgreSQL, "public.users", `"public"."users"`},
+		{config.TypePostgreSQL, `we"ird`, `"we""ird"`},
+		{config.TypeMySQL, "shop.orders", "`shop`.`orders`"},
+		{config.TypeMySQL, "we`ird", "`we``ird`"},
+		{config.TypeSQLite, "users", `"users"`},
+	}
+
+	for _, test := range tests {
+		if quoted := QuoteIdentifier(test.dbType, test.name); quoted != test.expected {
+			t.Errorf("%s %s: got %s, expected %s", test.dbType, test.name, quoted, test.expected)
+		}
+	}
+}
+
+func TestExportSQLite(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	setup := `CREATE TABLE users (id integer, name text, score real, active boolean, created timestamp, avatar blob);
+INSERT INTO users VALUES (1, 'ada', 1.5, 1, '2024-01-02 03:04:05', x'ff00'), (2, NULL, 2, 0, NULL, NULL)`
+	if _, err := Run(context.Background(), db, config.TypeSQLite, setup, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+	writer, err := NewRowWriter("jsonl", &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := Export(context.Background(), db, "SELECT * FROM users ORDER BY id", writer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("got %d rows, expected 2", count)
+	}
+
+	expected := `{"id":1,"name":"ada","score":1.5,"active":true,"created":"2024-01-02T03:04:05Z","avatar":"/wA="}
+{"id":2,"name":null,"score":2,"active":false,"created":null,"avatar":null}
+`
+	if buffer.String() != expected {
+		t.Fatalf("got:\n%s\nexpected:\n%s", buffer.String(), expected)
+	}
+}
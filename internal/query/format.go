@@ -0,0 +1,182 @@
+package query
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	FormatTable    = "table"
+	FormatCSV      = "csv"
+	FormatJSONL    = "jsonl"
+	FormatMarkdown = "markdown"
+)
+
+// Formats lists the output formats of Write
+var Formats = []string{FormatTable, FormatCSV, FormatJSONL, FormatMarkdown}
+
+// IsValidFormat reports whether format is one of Formats
+func IsValidFormat(format string) bool {
+	for _, valid := range Formats {
+		if format == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Write prints the rows of result to w in format
+func Write(w io.Writer, format string, result Result) error {
+	switch format {
+	case FormatTable:
+		return writeTable(w, result)
+	case FormatCSV:
+		return writeCSV(w, result)
+	case FormatJSONL:
+		return writeJSONL(w, result)
+	case FormatMarkdown:
+		return writeMarkdown(w, result)
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// FormatValue renders a value as text, with NULL as null
+func FormatValue(value any, null string) string {
+	switch v := value.(type) {
+	case nil:
+		return null
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func writeTable(w io.Writer, result Result) error {
+	cells := make([][]string, len(result.Rows))
+	widths := make([]int, len(result.Columns))
+	for i, column := range result.Columns {
+		widths[i] = runewidth.StringWidth(column)
+	}
+	for r, row := range result.Rows {
+		cells[r] = make([]string, len(row))
+		for i, value := range row {
+			cell := strings.NewReplacer("\r", `\r`, "\n", `\n`, "\t", `\t`).Replace(FormatValue(value, "NULL"))
+			cells[r][i] = cell
+			widths[i] = max(widths[i], runewidth.StringWidth(cell))
+		}
+	}
+
+	line := func(values []string) string {
+		padded := make([]string, len(values))
+		for i, value := range values {
+			padded[i] = runewidth.FillRight(value, widths[i])
+		}
+		return strings.TrimRight(" "+strings.Join(padded, " | "), " ")
+	}
+	separators := make([]string, len(widths))
+	for i, width := range widths {
+		separators[i] = strings.Repeat("-", width+2)
+	}
+
+	var b strings.Builder
+	b.WriteString(line(result.Columns) + "\n")
+	b.WriteString(strings.Join(separators, "+") + "\n")
+	for _, row := range cells {
+		b.WriteString(line(row) + "\n")
+	}
+	if len(cells) == 1 {
+		b.WriteString("(1 row)\n")
+	} else {
+		fmt.Fprintf(&b, "(%d rows)\n", len(cells))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeCSV(w io.Writer, result Result) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(result.Columns); err != nil {
+		return err
+	}
+
+	record := make([]string, len(result.Columns))
+	for _, row := range result.Rows {
+		for i, value := range row {
+			record[i] = FormatValue(value, "")
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeJSONL writes one object per row, keeping the column order
+func writeJSONL(w io.Writer, result Result) error {
+	keys := make([]string, len(result.Columns))
+	for i, column := range result.Columns {
+		key, err := json.Marshal(column)
+		if err != nil {
+			return err
+		}
+		keys[i] = string(key)
+	}
+
+	for _, row := range result.Rows {
+		fields := make([]string, len(row))
+		for i, value := range row {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				// Driver types without a JSON form are written as text
+				encoded, _ = json.Marshal(FormatValue(value, ""))
+			}
+			fields[i] = keys[i] + ":" + string(encoded)
+		}
+		if _, err := fmt.Fprintf(w, "{%s}\n", strings.Join(fields, ",")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeMarkdown(w io.Writer, result Result) error {
+	escape := strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+	line := func(values []string) string {
+		return "| " + strings.Join(values, " | ") + " |\n"
+	}
+
+	var b strings.Builder
+	header := make([]string, len(result.Columns))
+	separators := make([]string, len(result.Columns))
+	for i, column := range result.Columns {
+		header[i] = escape.Replace(column)
+		separators[i] = "---"
+	}
+	b.WriteString(line(header))
+	b.WriteString(line(separators))
+
+	for _, row := range result.Rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			cells[i] = escape.Replace(FormatValue(value, "NULL"))
+		}
+		b.WriteString(line(cells))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
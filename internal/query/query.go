@@ -0,0 +1,152 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Result is the outcome of one statement. Statements returning rows fill
+// Columns and Rows; the others only RowsAffected.
+type Result struct {
+	Statement    string
+	Columns      []string
+	Rows         [][]any
+	RowsAffected int64
+	// Truncated is set when the rows stopped at the limit
+	Truncated bool
+}
+
+// HasRows reports whether the statement returned a result set
+func (r Result) HasRows() bool {
+	return r.Columns != nil
+}
+
+// rowKeywords are the first keywords of statements returning rows
+var rowKeywords = map[string]bool{
+	"SELECT":   true,
+	"WITH":     true,
+	"VALUES":   true,
+	"TABLE":    true,
+	"SHOW":     true,
+	"EXPLAIN":  true,
+	"DESCRIBE": true,
+	"DESC":     true,
+	"PRAGMA":   true,
+}
+
+// Run executes the statements of script, written for dbType, one after the other on a single
+// connection, so session settings and transactions carry over. Result sets
+// stop after limit rows when limit is positive. It returns the results of the
+// statements run so far along with the first error.
+func Run(ctx context.Context, db *sql.DB, dbType, script string, limit int) ([]Result, error) {
+	statements := SplitStatements(script, dbType)
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("no statement to run")
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	results := []Result{}
+	for _, statement := range statements {
+		result, err := runStatement(ctx, conn, statement, limit)
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", summarize(statement), err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func runStatement(ctx context.Context, conn *sql.Conn, statement string, limit int) (Result, error) {
+	result := Result{Statement: statement}
+
+	if !returnsRows(statement) {
+		res, err := conn.ExecContext(ctx, statement)
+		if err != nil {
+			return result, err
+		}
+		// Not every driver and statement reports affected rows
+		result.RowsAffected, _ = res.RowsAffected()
+		return result, nil
+	}
+
+	rows, err := conn.QueryContext(ctx, statement)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	result.Columns, err = rows.Columns()
+	if err != nil {
+		return result, err
+	}
+
+	for rows.Next() {
+		if limit > 0 && len(result.Rows) == limit {
+			result.Truncated = true
+			break
+		}
+
+		values := make([]any, len(result.Columns))
+		pointers := make([]any, len(values))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return result, err
+		}
+		for i, value := range values {
+			values[i] = normalize(value)
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// returnsRows tells from its first keyword, or a RETURNING clause, whether
+// statement returns rows. Leading comments are skipped, # ones included as no
+// statement starts with #.
+func returnsRows(statement string) bool {
+	statement = stripLeadingComments(statement, true)
+	fields := strings.Fields(strings.TrimLeft(statement, "( \t\r\n"))
+	if len(fields) == 0 {
+		return false
+	}
+	if rowKeywords[strings.ToUpper(fields[0])] {
+		return true
+	}
+	for _, field := range fields {
+		if strings.EqualFold(field, "RETURNING") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize turns the byte slices drivers scan text into strings
+func normalize(value any) any {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
+
+// summarize shortens statement for error messages
+func summarize(statement string) string {
+	summary := []rune(strings.Join(strings.Fields(statement), " "))
+	if len(summary) > 60 {
+		summary = append(summary[:57], []rune("...")...)
+	}
+	return string(summary)
+}
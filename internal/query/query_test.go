@@ -0,0 +1,80 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"dbear/internal/config"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestReturnsRows(t *testing.T) {
+	tests := []struct {
+		statement string
+		expected  bool
+	}{
+		{"SELECT 1", true},
+		{"select 1", true},
+		{"(SELECT 1) UNION (SELECT 2)", true},
+		{"WITH t AS (SELECT 1) SELECT * FROM t", true},
+		{"VALUES (1)", true},
+		{"SHOW TABLES", true},
+		{"EXPLAIN SELECT 1", true},
+		{"PRAGMA table_info(users)", true},
+		{"INSERT INTO users (name) VALUES ('a') RETURNING id", true},
+		{"INSERT INTO users (name) VALUES ('a')", false},
+		{"UPDATE users SET name = 'a'", false},
+		{"CREATE TABLE users (id int)", false},
+		{"-- report\nSELECT 1", true},
+		{"/* report */ SELECT 1", true},
+		{"/* multi\nline */\n-- and more\nSELECT 1", true},
+		{"# report\nSELECT 1", true},
+		{"-- SELECT\nDELETE FROM users", false},
+		{"-- only a comment", false},
+		{"/* unterminated SELECT", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.statement, func(t *testing.T) {
+			if got := returnsRows(test.statement); got != test.expected {
+				t.Fatalf("got %v, expected %v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestRunReturnsRowsOfCommentedStatements(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	script := `CREATE TABLE users (id integer, name text);
+INSERT INTO users VALUES (1, 'ada'), (2, 'grace');
+-- report
+SELECT name FROM users ORDER BY id;
+/* count */ SELECT count(*) FROM users`
+	results, err := Run(context.Background(), db, config.TypeSQLite, script, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("got %d results, expected 4", len(results))
+	}
+
+	if results[1].HasRows() || results[1].RowsAffected != 2 {
+		t.Fatalf("unexpected insert result: %+v", results[1])
+	}
+	report := results[2]
+	if !report.HasRows() || len(report.Rows) != 1 || report.Rows[0][0] != "ada" || !report.Truncated {
+		t.Fatalf("unexpected report result: %+v", report)
+	}
+	if count := results[3]; !count.HasRows() || count.Rows[0][0] != int64(2) {
+		t.Fatalf("unexpected count result: %+v", count)
+	}
+}
@@ -0,0 +1,119 @@
+package query
+
+import (
+	"strings"
+
+	"dbear/internal/config"
+)
+
+// SplitStatements splits script on the semicolons ending its statements,
+// skipping those inside quotes and comments, as written for dbType: MySQL
+// also has # comments and backslash escapes, PostgreSQL dollar-quoted bodies.
+// Empty statements are dropped.
+func SplitStatements(script, dbType string) []string {
+	mysql := dbType == config.TypeMySQL
+	statements := []string{}
+	start := 0
+
+	add := func(end int) {
+		if statement := strings.TrimSpace(script[start:end]); statement != "" && !onlyComments(statement, mysql) {
+			statements = append(statements, statement)
+		}
+	}
+
+	for i := 0; i < len(script); i++ {
+		switch c := script[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(script, i, c, mysql)
+		case c == '-' && strings.HasPrefix(script[i:], "--"), c == '#' && mysql:
+			i = skipLine(script, i)
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			if end := strings.Index(script[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(script)
+			}
+		case c == '$' && dbType == config.TypePostgreSQL:
+			if tag, ok := dollarTag(script[i:]); ok {
+				if end := strings.Index(script[i+len(tag):], tag); end >= 0 {
+					i += len(tag) + end + len(tag) - 1
+				} else {
+					i = len(script)
+				}
+			}
+		case c == ';':
+			add(i)
+			start = i + 1
+		}
+	}
+	add(len(script))
+
+	return statements
+}
+
+// skipQuoted returns the index of the quote closing the one at start. Doubled
+// quotes stay inside, and so do backslash escapes when backslashes is set.
+func skipQuoted(script string, start int, quote byte, backslashes bool) int {
+	for i := start + 1; i < len(script); i++ {
+		switch script[i] {
+		case '\\':
+			if backslashes && quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(script) && script[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(script)
+}
+
+// skipLine returns the index of the end of the line holding start
+func skipLine(script string, start int) int {
+	if end := strings.IndexByte(script[start:], '\n'); end >= 0 {
+		return start + end
+	}
+	return len(script)
+}
+
+// dollarTag returns the $tag$ opening a dollar-quoted string at the start of s
+func dollarTag(s string) (string, bool) {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '$' {
+			return s[:i+1], true
+		}
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 1 && c >= '0' && c <= '9')) {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// onlyComments reports whether statement holds nothing but comments
+func onlyComments(statement string, hashComments bool) bool {
+	return stripLeadingComments(statement, hashComments) == ""
+}
+
+// stripLeadingComments removes the whitespace and comments before the first
+// keyword of statement
+func stripLeadingComments(statement string, hashComments bool) string {
+	for {
+		statement = strings.TrimSpace(statement)
+		switch {
+		case strings.HasPrefix(statement, "--"), hashComments && strings.HasPrefix(statement, "#"):
+			statement = statement[skipLine(statement, 0):]
+		case strings.HasPrefix(statement, "/*"):
+			end := strings.Index(statement[2:], "*/")
+			if end < 0 {
+				return ""
+			}
+			statement = statement[end+4:]
+		default:
+			return statement
+		}
+	}
+}
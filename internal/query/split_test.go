@@ -0,0 +1,45 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"dbear/internal/config"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name     string
+		dbType   string
+		script   string
+		expected []string
+	}{
+		{"single", config.TypePostgreSQL, "SELECT 1", []string{"SELECT 1"}},
+		{"trailing semicolon", config.TypePostgreSQL, "SELECT 1;\n", []string{"SELECT 1"}},
+		{"several", config.TypeSQLite, "SELECT 1; SELECT 2;;", []string{"SELECT 1", "SELECT 2"}},
+		{"quoted semicolon", config.TypePostgreSQL, "SELECT ';'; SELECT 2", []string{"SELECT ';'", "SELECT 2"}},
+		{"doubled quote", config.TypePostgreSQL, "SELECT 'it''s;'; SELECT 2", []string{"SELECT 'it''s;'", "SELECT 2"}},
+		{"quoted identifier", config.TypePostgreSQL, `SELECT 1 AS "a;b"; SELECT 2`, []string{`SELECT 1 AS "a;b"`, "SELECT 2"}},
+		{"line comment", config.TypePostgreSQL, "SELECT 1; -- done; really\nSELECT 2", []string{"SELECT 1", "-- done; really\nSELECT 2"}},
+		{"block comment", config.TypePostgreSQL, "SELECT /* ; */ 1; SELECT 2", []string{"SELECT /* ; */ 1", "SELECT 2"}},
+		{"only comments", config.TypePostgreSQL, "SELECT 1; -- done\n/* end */", []string{"SELECT 1"}},
+		{"dollar quoted", config.TypePostgreSQL, "CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql; SELECT 2",
+			[]string{"CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql", "SELECT 2"}},
+		{"anonymous dollar quote", config.TypePostgreSQL, "DO $$ BEGIN; END $$; SELECT 2", []string{"DO $$ BEGIN; END $$", "SELECT 2"}},
+		{"dollar outside postgresql", config.TypeSQLite, "SELECT $a; SELECT 2", []string{"SELECT $a", "SELECT 2"}},
+		{"mysql hash comment", config.TypeMySQL, "SELECT 1; # done; really\nSELECT 2", []string{"SELECT 1", "# done; really\nSELECT 2"}},
+		{"mysql backslash escape", config.TypeMySQL, `SELECT 'a\';'; SELECT 2`, []string{`SELECT 'a\';'`, "SELECT 2"}},
+		{"backslash outside mysql", config.TypePostgreSQL, `SELECT 'a\'; SELECT 2`, []string{`SELECT 'a\'`, "SELECT 2"}},
+		{"backtick", config.TypeMySQL, "SELECT 1 AS `a;b`; SELECT 2", []string{"SELECT 1 AS `a;b`", "SELECT 2"}},
+		{"empty", config.TypePostgreSQL, " ;\n; ", []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statements := SplitStatements(test.script, test.dbType)
+			if strings.Join(statements, "|") != strings.Join(test.expected, "|") || len(statements) != len(test.expected) {
+				t.Fatalf("got %q, expected %q", statements, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package query
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	ExportCSV     = "csv"
+	ExportJSONL   = "jsonl"
+	ExportParquet = "parquet"
+)
+
+// ExportFormats lists the file formats of NewRowWriter
+var ExportFormats = []string{ExportCSV, ExportJSONL, ExportParquet}
+
+// parquetRowGroupSize bounds the rows a parquet export buffers before writing
+// them out
+const parquetRowGroupSize = 65536
+
+// NewRowWriter creates the RowWriter writing format to w
+func NewRowWriter(format string, w io.Writer) (RowWriter, error) {
+	switch format {
+	case ExportCSV:
+		return &csvRowWriter{writer: csv.NewWriter(w)}, nil
+	case ExportJSONL:
+		return &jsonlRowWriter{writer: bufio.NewWriter(w)}, nil
+	case ExportParquet:
+		return &parquetRowWriter{out: w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// exportText renders a converted value as text: timestamps in RFC 3339 and
+// binary values in base64
+func exportText(value any) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	default:
+		return FormatValue(v, "")
+	}
+}
+
+type csvRowWriter struct {
+	writer *csv.Writer
+	record []string
+}
+
+func (w *csvRowWriter) WriteHeader(columns []Column) error {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	w.record = make([]string, len(columns))
+	return w.writer.Write(names)
+}
+
+func (w *csvRowWriter) WriteRow(values []any) error {
+	for i, value := range values {
+		w.record[i] = exportText(value)
+	}
+	return w.writer.Write(w.record)
+}
+
+func (w *csvRowWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// jsonlRowWriter writes one object per row, keeping the column order
+type jsonlRowWriter struct {
+	writer *bufio.Writer
+	keys   []string
+}
+
+func (w *jsonlRowWriter) WriteHeader(columns []Column) error {
+	w.keys = make([]string, len(columns))
+	for i, column := range columns {
+		key, err := json.Marshal(column.Name)
+		if err != nil {
+			return err
+		}
+		w.keys[i] = string(key)
+	}
+	return nil
+}
+
+func (w *jsonlRowWriter) WriteRow(values []any) error {
+	fields := make([]string, len(values))
+	for i, value := range values {
+		// encoding/json writes []byte in base64 and time.Time in RFC 3339
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			// NaN and infinite floats have no JSON form
+			encoded, _ = json.Marshal(exportText(value))
+		}
+		fields[i] = w.keys[i] + ":" + string(encoded)
+	}
+
+	_, err := fmt.Fprintf(w.writer, "{%s}\n", strings.Join(fields, ","))
+	return err
+}
+
+func (w *jsonlRowWriter) Close() error {
+	return w.writer.Flush()
+}
+
+// parquetRowWriter writes a file whose columns are all optional. Parquet
+// groups order their columns by name.
+type parquetRowWriter struct {
+	out     io.Writer
+	writer  *parquet.Writer
+	columns []Column
+	// order maps each parquet column onto its position in the rows
+	order []int
+}
+
+func (w *parquetRowWriter) WriteHeader(columns []Column) error {
+	group := parquet.Group{}
+	for _, column := range columns {
+		if _, duplicate := group[column.Name]; duplicate {
+			return fmt.Errorf("duplicate column name %s, parquet needs unique names: use aliases", column.Name)
+		}
+		group[column.Name] = parquet.Optional(parquetNode(column.Kind))
+	}
+
+	schema := parquet.NewSchema("row", group)
+	position := map[string]int{}
+	for i, column := range columns {
+		position[column.Name] = i
+	}
+	for _, field := range schema.Fields() {
+		w.order = append(w.order, position[field.Name()])
+	}
+
+	w.columns = columns
+	w.writer = parquet.NewWriter(w.out, schema,
+		parquet.Compression(&parquet.Snappy),
+		parquet.MaxRowsPerRowGroup(parquetRowGroupSize),
+	)
+	return nil
+}
+
+func parquetNode(kind Kind) parquet.Node {
+	switch kind {
+	case KindInteger:
+		return parquet.Int(64)
+	case KindFloat:
+		return parquet.Leaf(parquet.DoubleType)
+	case KindBoolean:
+		return parquet.Leaf(parquet.BooleanType)
+	case KindTime:
+		return parquet.Timestamp(parquet.Microsecond)
+	case KindBinary:
+		return parquet.Leaf(parquet.ByteArrayType)
+	default:
+		return parquet.String()
+	}
+}
+
+func (w *parquetRowWriter) WriteRow(values []any) error {
+	row := make(parquet.Row, len(w.order))
+	for columnIndex, position := range w.order {
+		value := values[position]
+		if value == nil {
+			row[columnIndex] = parquet.NullValue().Level(0, 0, columnIndex)
+			continue
+		}
+
+		var parquetValue parquet.Value
+		switch v := value.(type) {
+		case int64:
+			parquetValue = parquet.Int64Value(v)
+		case float64:
+			parquetValue = parquet.DoubleValue(v)
+		case bool:
+			parquetValue = parquet.BooleanValue(v)
+		case time.Time:
+			parquetValue = parquet.Int64Value(v.UnixMicro())
+		case []byte:
+			parquetValue = parquet.ByteArrayValue(v)
+		case string:
+			parquetValue = parquet.ByteArrayValue([]byte(v))
+		default:
+			return fmt.Errorf("column %s: unexpected %T value", w.columns[position].Name, value)
+		}
+		row[columnIndex] = parquetValue.Level(0, 1, columnIndex)
+	}
+
+	_, err := w.writer.WriteRows([]parquet.Row{row})
+	return err
+}
+
+func (w *parquetRowWriter) Close() error {
+	return w.writer.Close()
+}
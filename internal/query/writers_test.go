@@ -0,0 +1,98 @@
+package query
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+var writerColumns = []Column{
+	{Name: "id", Kind: KindInteger},
+	{Name: "name", Kind: KindString},
+	{Name: "score", Kind: KindFloat},
+	{Name: "active", Kind: KindBoolean},
+	{Name: "created", Kind: KindTime},
+	{Name: "avatar", Kind: KindBinary},
+}
+
+var writerRows = [][]any{
+	{int64(1), "ada, \"the first\"", 1.5, true, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), []byte{0xff, 0x00}},
+	{int64(2), nil, math.NaN(), false, nil, nil},
+}
+
+func writeRows(t *testing.T, format string) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer, err := NewRowWriter(format, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.WriteHeader(writerColumns); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range writerRows {
+		if err := writer.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestTextRowWriters(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{ExportCSV, `id,name,score,active,created,avatar
+1,"ada, ""the first""",1.5,true,2024-01-02T03:04:05Z,/wA=
+2,,NaN,false,,
+`},
+		{ExportJSONL, `{"id":1,"name":"ada, \"the first\"","score":1.5,"active":true,"created":"2024-01-02T03:04:05Z","avatar":"/wA="}
+{"id":2,"name":null,"score":"NaN","active":false,"created":null,"avatar":null}
+`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			if got := string(writeRows(t, test.format)); got != test.expected {
+				t.Fatalf("got:\n%s\nexpected:\n%s", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestParquetRowWriter(t *testing.T) {
+	data := writeRows(t, ExportParquet)
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows := file.NumRows(); rows != int64(len(writerRows)) {
+		t.Fatalf("got %d rows, expected %d", rows, len(writerRows))
+	}
+
+	fields := map[string]bool{}
+	for _, field := range file.Schema().Fields() {
+		if !field.Optional() {
+			t.Fatalf("column %s is not optional", field.Name())
+		}
+		fields[field.Name()] = true
+	}
+	for _, column := range writerColumns {
+		if !fields[column.Name] {
+			t.Fatalf("column %s missing from the parquet schema", column.Name)
+		}
+	}
+}
+
+func TestNewRowWriterRejectsUnknownFormats(t *testing.T) {
+	if _, err := NewRowWriter("xlsx", &bytes.Buffer{}); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,43 @@
+package redact
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLineWriterMasksSecretsSplitAcrossWrites(t *testing.T) {
+	const secret = "sentinel-Pa55w0rd"
+	tests := []struct {
+		name   string
+		writes []string
+	}{
+		{"whole line", []string{"error: " + secret + "\n"}},
+		{"split secret", []string{"error: sentinel-", "Pa55w0rd\n"}},
+		{"byte by byte", strings.Split("error: "+secret+"\n", "")},
+		{"no final newline", []string{"error: sentinel-Pa", "55w0rd"}},
+		{"several lines", []string{"first\nerror: sent", "inel-Pa55w0rd\nlast\n"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := NewLineWriter(New(secret), &out)
+			for _, write := range test.writes {
+				if _, err := w.Write([]byte(write)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.Contains(out.String(), secret) || !strings.Contains(out.String(), "error: "+Mask) {
+				t.Fatalf("secret not masked: %q", out.String())
+			}
+			if written := strings.Join(test.writes, ""); out.String() != strings.ReplaceAll(written, secret, Mask) {
+				t.Fatalf("got %q, expected the writes with the secret masked", out.String())
+			}
+		})
+	}
+}
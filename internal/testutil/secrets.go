@@ -0,0 +1,68 @@
+// Package testutil holds the helpers tests share to check that passwords stay
+// off command lines and out of output.
+package testutil
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"dbear/internal/connection"
+)
+
+// Sentinel is the password of test connections, which must never show up on
+// a command line or unmasked in output
+const Sentinel = "sentinel-Pa55w0rd"
+
+// CheckCommandLine fails the test when the password is an argument of cmd
+func CheckCommandLine(t *testing.T, cmd *exec.Cmd) {
+	t.Helper()
+	for _, arg := range cmd.Args {
+		if strings.Contains(arg, Sentinel) {
+			t.Fatalf("password on the command line: %q", cmd.Args)
+		}
+	}
+}
+
+// AddedEnv returns the variables cmd sets on top of the environment of the test
+func AddedEnv(cmd *exec.Cmd) []string {
+	inherited := len(os.Environ())
+	if len(cmd.Env) < inherited {
+		return cmd.Env
+	}
+	return cmd.Env[inherited:]
+}
+
+// EnvHoldsPassword reports whether one of the variables cmd sets holds the
+// password
+func EnvHoldsPassword(cmd *exec.Cmd) bool {
+	for _, entry := range AddedEnv(cmd) {
+		if strings.Contains(entry, Sentinel) {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretFileHoldsPassword reports whether file holds the password, failing the
+// test when file is readable by others than its owner. A nil file holds
+// nothing.
+func SecretFileHoldsPassword(t *testing.T, file *connection.SecretFile) bool {
+	t.Helper()
+	if file == nil {
+		return false
+	}
+	info, err := os.Stat(file.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Fatalf("secret file has mode %o, expected 600", mode)
+	}
+	content, err := os.ReadFile(file.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Contains(string(content), Sentinel)
+}
@@ -4,20 +4,181 @@ import (
 	"bytes"
 	"fmt"
 	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"dbear/internal/config"
+	"dbear/internal/connection"
 )
 
-func DumpDatabase(conn config.Connection, dockerImage string, schemas []string) ([]byte, error) {
+const dockerCertDir = "/dbear/certs"
+
+// dockerSSLFiles describes the certificate files of a connection as seen from
+// inside the dump/restore container.
+type dockerSSLFiles struct {
+	mountArgs []string
+	rootCert  string
+	cert      string
+	key       string
+}
+
+func buildDockerSSLFiles(conn config.Connection) dockerSSLFiles {
+	files := dockerSSLFiles{}
+
+	mount := func(hostPath, name string) string {
+		if hostPath == "" {
+			return ""
+		}
+		if absPath, err := filepath.Abs(hostPath); err == nil {
+			hostPath = absPath
+		}
+		containerPath := dockerCertDir + "/" + name
+		files.mountArgs = append(files.mountArgs, "-v", fmt.Sprintf("%s:%s:ro", hostPath, containerPath))
+		return containerPath
+	}
+
+	files.rootCert = mount(conn.SSLRootCert, "root.crt")
+	files.cert = mount(conn.SSLCert, "client.crt")
+	files.key = mount(conn.SSLKey, "client.key")
+
+	return files
+}
+
+// dockerSocketMountArgs mounts the directory holding the unix socket of conn at
+// the same path inside the container, so clients can reach it unchanged.
+func dockerSocketMountArgs(conn config.Connection) []string {
+	if !connection.UsesSocket(conn) {
+		return nil
+	}
+	dir := connection.SocketDir(conn)
+	return []string{"-v", fmt.Sprintf("%s:%s", dir, dir)}
+}
+
+func postgreSQLEnv(conn config.Connection, sslFiles dockerSSLFiles) []string {
+	host, port := conn.Host, conn.Port
+	if connection.UsesSocket(conn) {
+		host, port = connection.PostgreSQLSocketDir(conn)
+	}
+
+	env := []string{
+		fmt.Sprintf("PGHOST=%s", host),
+		fmt.Sprintf("PGPORT=%d", port),
+		fmt.Sprintf("PGUSER=%s", conn.Username),
+		fmt.Sprintf("PGPASSWORD=%s", conn.Password),
+		fmt.Sprintf("PGDATABASE=%s", conn.Database),
+	}
+
+	if conn.SSLMode != "" {
+		env = append(env, fmt.Sprintf("PGSSLMODE=%s", conn.SSLMode))
+	}
+	if sslFiles.rootCert != "" {
+		env = append(env, fmt.Sprintf("PGSSLROOTCERT=%s", sslFiles.rootCert))
+	}
+	if sslFiles.cert != "" {
+		env = append(env, fmt.Sprintf("PGSSLCERT=%s", sslFiles.cert))
+	}
+	if sslFiles.key != "" {
+		env = append(env, fmt.Sprintf("PGSSLKEY=%s", sslFiles.key))
+	}
+
+	return env
+}
+
+func mySQLAddressArgs(conn config.Connection) []string {
+	if connection.UsesSocket(conn) {
+		return []string{"-S", conn.Socket}
+	}
+	return []string{"-h", conn.Host, "-P", fmt.Sprintf("%d", conn.Port)}
+}
+
+func mySQLSSLArgs(conn config.Connection, sslFiles dockerSSLFiles) []string {
+	args := []string{}
+
+	if mode := connection.MySQLClientSSLMode(conn.SSLMode); mode != "" {
+		args = append(args, fmt.Sprintf("--ssl-mode=%s", mode))
+	}
+	if sslFiles.rootCert != "" {
+		args = append(args, fmt.Sprintf("--ssl-ca=%s", sslFiles.rootCert))
+	}
+	if sslFiles.cert != "" {
+		args = append(args, fmt.Sprintf("--ssl-cert=%s", sslFiles.cert))
+	}
+	if sslFiles.key != "" {
+		args = append(args, fmt.Sprintf("--ssl-key=%s", sslFiles.key))
+	}
+
+	return args
+}
+
+// dockerCredentials hands the password of a connection to the container
+// through a file, keeping it off the docker command line
+type dockerCredentials struct {
+	// runArgs are the docker run arguments passing the file
+	runArgs []string
+	// clientArgs must come first among the arguments of the mysql clients
+	clientArgs []string
+	file       *connection.SecretFile
+}
+
+// buildDockerCredentials passes the PostgreSQL environment in an --env-file,
+// and the MySQL password in an option file mounted into the container
+func buildDockerCredentials(conn config.Connection, sslFiles dockerSSLFiles) (dockerCredentials, error) {
+	credentials := dockerCredentials{}
+
+	switch conn.Type {
+	case config.TypePostgreSQL:
+		file, err := connection.WriteDockerEnvFile(postgreSQLEnv(conn, sslFiles))
+		if err != nil {
+			return credentials, err
+		}
+		credentials.file = file
+		credentials.runArgs = []string{"--env-file", file.Path}
+	case config.TypeMySQL:
+		if conn.Password == "" {
+			return credentials, nil
+		}
+		file, err := connection.WriteMySQLOptionFile(conn)
+		if err != nil {
+			return credentials, err
+		}
+		containerPath := dockerCertDir + "/client.cnf"
+		credentials.file = file
+		credentials.runArgs = []string{"-v", fmt.Sprintf("%s:%s:ro", file.Path, containerPath)}
+		credentials.clientArgs = []string{"--defaults-extra-file=" + containerPath}
+	}
+
+	return credentials, nil
+}
+
+func DumpDatabase(conn config.Connection, dockerImage string, options DumpOptions) ([]byte, error) {
 	var dumpCmd *exec.Cmd
+	var credentials dockerCredentials
+	var err error
 
 	if conn.Type == config.TypePostgreSQL {
-		dumpCmd = buildPostgreSQLDumpCommand(conn, dockerImage, schemas)
+		dumpCmd, credentials, err = buildPostgreSQLDumpCommand(conn, dockerImage, options)
 	} else if conn.Type == config.TypeMySQL {
-		dumpCmd = buildMySQLDumpCommand(conn, dockerImage)
+		// mysqldump only takes and ignores tables by exact name
+		var tables, ignored []string
+		tables, err = matchingMySQLTables(conn, options.Tables)
+		if err != nil {
+			return nil, err
+		}
+		if len(options.Tables) > 0 && len(tables) == 0 {
+			return nil, fmt.Errorf("no table of '%s' matches %s", conn.Name, strings.Join(options.Tables, ", "))
+		}
+		ignored, err = matchingMySQLTables(conn, options.ExcludeTables)
+		if err != nil {
+			return nil, err
+		}
+		dumpCmd, credentials, err = buildMySQLDumpCommand(conn, dockerImage, tables, ignored)
 	} else {
 		return nil, fmt.Errorf("unsupported database type for docker dump: %s", conn.Type)
 	}
+	if err != nil {
+		return nil, err
+	}
+	defer credentials.file.Remove()
 
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -33,14 +194,20 @@ func DumpDatabase(conn config.Connection, dockerImage string, schemas []string)
 
 func RestoreDatabase(conn config.Connection, dockerImage string, dumpData []byte) error {
 	var restoreCmd *exec.Cmd
+	var credentials dockerCredentials
+	var err error
 
 	if conn.Type == config.TypePostgreSQL {
-		restoreCmd = buildPostgreSQLRestoreCommand(conn, dockerImage, dumpData)
+		restoreCmd, credentials, err = buildPostgreSQLRestoreCommand(conn, dockerImage, dumpData)
 	} else if conn.Type == config.TypeMySQL {
-		restoreCmd = buildMySQLRestoreCommand(conn, dockerImage, dumpData)
+		restoreCmd, credentials, err = buildMySQLRestoreCommand(conn, dockerImage, dumpData)
 	} else {
 		return fmt.Errorf("unsupported database type for docker restore: %s", conn.Type)
 	}
+	if err != nil {
+		return err
+	}
+	defer credentials.file.Remove()
 
 	var stderr bytes.Buffer
 	restoreCmd.Stderr = &stderr
@@ -52,13 +219,11 @@ func RestoreDatabase(conn config.Connection, dockerImage string, dumpData []byte
 	return nil
 }
 
-func buildPostgreSQLDumpCommand(conn config.Connection, dockerImage string, schemas []string) *exec.Cmd {
-	env := []string{
-		fmt.Sprintf("PGHOST=%s", conn.Host),
-		fmt.Sprintf("PGPORT=%d", conn.Port),
-		fmt.Sprintf("PGUSER=%s", conn.Username),
-		fmt.Sprintf("PGPASSWORD=%s", conn.Password),
-		fmt.Sprintf("PGDATABASE=%s", conn.Database),
+func buildPostgreSQLDumpCommand(conn config.Connection, dockerImage string, options DumpOptions) (*exec.Cmd, dockerCredentials, error) {
+	sslFiles := buildDockerSSLFiles(conn)
+	credentials, err := buildDockerCredentials(conn, sslFiles)
+	if err != nil {
+		return nil, credentials, err
 	}
 
 	args := []string{
@@ -66,28 +231,31 @@ func buildPostgreSQLDumpCommand(conn config.Connection, dockerImage string, sche
 		"--rm",
 		"--network", "host",
 	}
-
-	for _, envVar := range env {
-		args = append(args, "-e", envVar)
-	}
+	args = append(args, sslFiles.mountArgs...)
+	args = append(args, dockerSocketMountArgs(conn)...)
+	args = append(args, credentials.runArgs...)
 
 	args = append(args, dockerImage, "pg_dump", "--no-owner", "--no-acl", "-Fc")
-	if len(schemas) > 0 {
-		for _, schema := range schemas {
+	if len(options.Schemas) > 0 {
+		for _, schema := range options.Schemas {
 			args = append(args, "-n", schema)
 		}
 	}
+	for _, pattern := range options.Tables {
+		args = append(args, "--table="+pattern)
+	}
+	for _, pattern := range options.ExcludeTables {
+		args = append(args, "--exclude-table="+pattern)
+	}
 
-	return exec.Command("docker", args...)
+	return exec.Command("docker", args...), credentials, nil
 }
 
-func buildPostgreSQLRestoreCommand(conn config.Connection, dockerImage string, dumpData []byte) *exec.Cmd {
-	env := []string{
-		fmt.Sprintf("PGHOST=%s", conn.Host),
-		fmt.Sprintf("PGPORT=%d", conn.Port),
-		fmt.Sprintf("PGUSER=%s", conn.Username),
-		fmt.Sprintf("PGPASSWORD=%s", conn.Password),
-		fmt.Sprintf("PGDATABASE=%s", conn.Database),
+func buildPostgreSQLRestoreCommand(conn config.Connection, dockerImage string, dumpData []byte) (*exec.Cmd, dockerCredentials, error) {
+	sslFiles := buildDockerSSLFiles(conn)
+	credentials, err := buildDockerCredentials(conn, sslFiles)
+	if err != nil {
+		return nil, credentials, err
 	}
 
 	args := []string{
@@ -96,54 +264,129 @@ func buildPostgreSQLRestoreCommand(conn config.Connection, dockerImage string, d
 		"--network", "host",
 		"-i",
 	}
-
-	for _, e := range env {
-		args = append(args, "-e", e)
-	}
+	args = append(args, sslFiles.mountArgs...)
+	args = append(args, dockerSocketMountArgs(conn)...)
+	args = append(args, credentials.runArgs...)
 
 	args = append(args, dockerImage, "pg_restore", "--no-owner", "--no-acl", "--disable-triggers", "-d", conn.Database)
 
 	cmd := exec.Command("docker", args...)
 	cmd.Stdin = bytes.NewReader(dumpData)
 
-	return cmd
+	return cmd, credentials, nil
 }
 
-func buildMySQLDumpCommand(conn config.Connection, dockerImage string) *exec.Cmd {
+func buildMySQLDumpCommand(conn config.Connection, dockerImage string, tables, ignoredTables []string) (*exec.Cmd, dockerCredentials, error) {
+	sslFiles := buildDockerSSLFiles(conn)
+	credentials, err := buildDockerCredentials(conn, sslFiles)
+	if err != nil {
+		return nil, credentials, err
+	}
+
 	args := []string{
 		"run",
 		"--rm",
 		"--network", "host",
-		dockerImage,
-		"mysqldump",
-		"-h", conn.Host,
-		"-P", fmt.Sprintf("%d", conn.Port),
-		"-u", conn.Username,
-		fmt.Sprintf("-p%s", conn.Password),
-		conn.Database,
 	}
+	args = append(args, sslFiles.mountArgs...)
+	args = append(args, dockerSocketMountArgs(conn)...)
+	args = append(args, credentials.runArgs...)
+	args = append(args, dockerImage, "mysqldump")
+	args = append(args, credentials.clientArgs...)
+	args = append(args, mySQLAddressArgs(conn)...)
+	args = append(args, "-u", conn.Username)
+	args = append(args, mySQLSSLArgs(conn, sslFiles)...)
+	for _, table := range ignoredTables {
+		args = append(args, "--ignore-table="+conn.Database+"."+table)
+	}
+	args = append(args, conn.Database)
+	args = append(args, tables...)
 
-	return exec.Command("docker", args...)
+	return exec.Command("docker", args...), credentials, nil
 }
 
-func buildMySQLRestoreCommand(conn config.Connection, dockerImage string, dumpData []byte) *exec.Cmd {
+func buildMySQLRestoreCommand(conn config.Connection, dockerImage string, dumpData []byte) (*exec.Cmd, dockerCredentials, error) {
+	sslFiles := buildDockerSSLFiles(conn)
+	credentials, err := buildDockerCredentials(conn, sslFiles)
+	if err != nil {
+		return nil, credentials, err
+	}
+
 	args := []string{
 		"run",
 		"--rm",
 		"--network", "host",
 		"-i",
-		dockerImage,
-		"mysql",
-		"-h", conn.Host,
-		"-P", fmt.Sprintf("%d", conn.Port),
-		"-u", conn.Username,
-		fmt.Sprintf("-p%s", conn.Password),
-		conn.Database,
 	}
+	args = append(args, sslFiles.mountArgs...)
+	args = append(args, dockerSocketMountArgs(conn)...)
+	args = append(args, credentials.runArgs...)
+	args = append(args, dockerImage, "mysql")
+	args = append(args, credentials.clientArgs...)
+	args = append(args, mySQLAddressArgs(conn)...)
+	args = append(args, "-u", conn.Username)
+	args = append(args, mySQLSSLArgs(conn, sslFiles)...)
+	args = append(args, conn.Database)
 
 	cmd := exec.Command("docker", args...)
 	cmd.Stdin = bytes.NewReader(dumpData)
 
-	return cmd
+	return cmd, credentials, nil
+}
+
+// DockerClientCommand runs the client of the official image matching the
+// server version of conn, psql or mysql, in an interactive container, given a
+// terminal when terminal is set. The caller removes the returned file, which
+// holds the password, once the client has exited.
+func DockerClientCommand(conn config.Connection, terminal bool) (*exec.Cmd, *connection.SecretFile, error) {
+	version, err := DetectVersion(conn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to detect database version: %w", err)
+	}
+
+	dockerImage := GetDockerImage(conn.Type, version)
+	if dockerImage == "" {
+		return nil, nil, fmt.Errorf("no docker image for %s connections", conn.Type)
+	}
+
+	return buildDockerClientCommand(conn, dockerImage, terminal)
 }
 
+func buildDockerClientCommand(conn config.Connection, dockerImage string, terminal bool) (*exec.Cmd, *connection.SecretFile, error) {
+	sslFiles := buildDockerSSLFiles(conn)
+	credentials, err := buildDockerCredentials(conn, sslFiles)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	args := []string{
+		"run",
+		"--rm",
+		"--network", "host",
+		"-i",
+	}
+	// docker refuses -t when its input is not a terminal
+	if terminal {
+		args = append(args, "-t")
+	}
+	args = append(args, sslFiles.mountArgs...)
+	args = append(args, dockerSocketMountArgs(conn)...)
+	args = append(args, credentials.runArgs...)
+
+	switch conn.Type {
+	case config.TypePostgreSQL:
+		args = append(args, dockerImage, "psql")
+	case config.TypeMySQL:
+		args = append(args, dockerImage, "mysql")
+		args = append(args, credentials.clientArgs...)
+		args = append(args, mySQLAddressArgs(conn)...)
+		args = append(args, "-u", conn.Username)
+		args = append(args, mySQLSSLArgs(conn, sslFiles)...)
+		args = append(args, conn.Database)
+	default:
+		credentials.file.Remove()
+		return nil, nil, fmt.Errorf("unsupported database type for docker client: %s", conn.Type)
+	}
+
+	return exec.Command("docker", args...), credentials.file, nil
+}
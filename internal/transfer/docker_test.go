@@ -0,0 +1,77 @@
+package transfer
+
+import (
+	"os/exec"
+	"testing"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+	"dbear/internal/testutil"
+)
+
+// checkDockerPassword fails the test when the password is on the command line
+// or in the environment of cmd, or is missing from file
+func checkDockerPassword(t *testing.T, cmd *exec.Cmd, file *connection.SecretFile) {
+	t.Helper()
+	testutil.CheckCommandLine(t, cmd)
+	if testutil.EnvHoldsPassword(cmd) {
+		t.Fatalf("password in the environment of docker: %q", testutil.AddedEnv(cmd))
+	}
+	if !testutil.SecretFileHoldsPassword(t, file) {
+		t.Fatal("password missing from the secret file")
+	}
+}
+
+func TestDockerCommandsKeepPasswordOffCommandLine(t *testing.T) {
+	connections := map[string]config.Connection{
+		config.TypePostgreSQL: {
+			Name: "pg", Type: config.TypePostgreSQL, Host: "localhost", Port: 5432,
+			Username: "app", Password: testutil.Sentinel, Database: "app", SSLMode: "require",
+		},
+		config.TypeMySQL: {
+			Name: "my", Type: config.TypeMySQL, Host: "localhost", Port: 3306,
+			Username: "app", Password: testutil.Sentinel, Database: "app", SSLMode: "require",
+		},
+	}
+	options := DumpOptions{Tables: []string{"users"}, ExcludeTables: []string{"logs"}}
+
+	tests := []struct {
+		name   string
+		dbType string
+		build  func(conn config.Connection) (*exec.Cmd, *connection.SecretFile, error)
+	}{
+		{"postgresql dump", config.TypePostgreSQL, func(conn config.Connection) (*exec.Cmd, *connection.SecretFile, error) {
+			cmd, credentials, err := buildPostgreSQLDumpCommand(conn, "postgres:16", options)
+			return cmd, credentials.file, err
+		}},
+		{"postgresql restore", config.TypePostgreSQL, func(conn config.Connection) (*exec.Cmd, *connection.SecretFile, error) {
+			cmd, credentials, err := buildPostgreSQLRestoreCommand(conn, "postgres:16", nil)
+			return cmd, credentials.file, err
+		}},
+		{"postgresql client", config.TypePostgreSQL, func(conn config.Connection) (*exec.Cmd, *connection.SecretFile, error) {
+			return buildDockerClientCommand(conn, "postgres:16", false)
+		}},
+		{"mysql dump", config.TypeMySQL, func(conn config.Connection) (*exec.Cmd, *connection.SecretFile, error) {
+			cmd, credentials, err := buildMySQLDumpCommand(conn, "mysql:8.0", []string{"users"}, []string{"logs"})
+			return cmd, credentials.file, err
+		}},
+		{"mysql restore", config.TypeMySQL, func(conn config.Connection) (*exec.Cmd, *connection.SecretFile, error) {
+			cmd, credentials, err := buildMySQLRestoreCommand(conn, "mysql:8.0", nil)
+			return cmd, credentials.file, err
+		}},
+		{"mysql client", config.TypeMySQL, func(conn config.Connection) (*exec.Cmd, *connection.SecretFile, error) {
+			return buildDockerClientCommand(conn, "mysql:8.0", false)
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd, file, err := test.build(connections[test.dbType])
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Remove()
+			checkDockerPassword(t, cmd, file)
+		})
+	}
+}
@@ -6,18 +6,30 @@ import (
 	"dbear/internal/config"
 )
 
-func Dump(conn config.Connection, schemas []string) ([]byte, error) {
+// DumpOptions narrows what a dump or transfer copies
+type DumpOptions struct {
+	// Schemas limits PostgreSQL dumps to these schemas
+	Schemas []string
+	// Tables limits the dump to the tables matching these patterns, which may
+	// use * and ? wildcards
+	Tables []string
+	// ExcludeTables skips the tables matching these patterns, which may use
+	// * and ? wildcards
+	ExcludeTables []string
+}
+
+func Dump(conn config.Connection, options DumpOptions) ([]byte, error) {
 	switch conn.Type {
 	case config.TypePostgreSQL, config.TypeMySQL:
-		return dumpWithDocker(conn, schemas)
+		return dumpWithDocker(conn, options)
 	case config.TypeSQLite:
-		return DumpSQLite(conn)
+		return DumpSQLite(conn, options)
 	default:
 		return nil, fmt.Errorf("unsupported database type for dump: %s", conn.Type)
 	}
 }
 
-func dumpWithDocker(conn config.Connection, schemas []string) ([]byte, error) {
+func dumpWithDocker(conn config.Connection, options DumpOptions) ([]byte, error) {
 	version, err := DetectVersion(conn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect database version: %w", err)
@@ -28,7 +40,7 @@ func dumpWithDocker(conn config.Connection, schemas []string) ([]byte, error) {
 		return nil, fmt.Errorf("failed to determine docker image for database")
 	}
 
-	return DumpDatabase(conn, image, schemas)
+	return DumpDatabase(conn, image, options)
 }
 
 func DumpFileExtension(connType string) string {
@@ -0,0 +1,123 @@
+package transfer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"dbear/internal/config"
+	"dbear/internal/connection"
+)
+
+// matchTables returns the tables matching any of patterns, which use * and ?
+// wildcards
+func matchTables(tables, patterns []string) []string {
+	matched := []string{}
+	for _, table := range tables {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, table); ok {
+				matched = append(matched, table)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// matchingMySQLTables lists the tables of the database of conn matching
+// patterns
+func matchingMySQLTables(conn config.Connection, patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	db, err := connection.OpenDB(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SHOW TABLES")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := []string{}
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return matchTables(tables, patterns), nil
+}
+
+// keptSQLiteTables lists the tables of the SQLite database of conn that
+// match include, or all of them when it is empty, but not exclude
+func keptSQLiteTables(conn config.Connection, include, exclude []string) ([]string, error) {
+	db, err := connection.OpenDB(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sqlite tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := []string{}
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return KeptTables(tables, DumpOptions{Tables: include, ExcludeTables: exclude}), nil
+}
+
+// KeptTables returns those of tables that options copy, leaving schemas
+// aside. Tables are named as catalog.ListTables names them, qualified by
+// their schema on PostgreSQL, where patterns without a schema match the
+// tables of every schema like pg_dump does.
+func KeptTables(tables []string, options DumpOptions) []string {
+	kept := []string{}
+	for _, table := range tables {
+		if len(options.Tables) > 0 && !matchesTable(table, options.Tables) {
+			continue
+		}
+		if matchesTable(table, options.ExcludeTables) {
+			continue
+		}
+		kept = append(kept, table)
+	}
+	return kept
+}
+
+// matchesTable tells whether table matches any of patterns, which may quote
+// their names like the tables picked for pg_dump
+func matchesTable(table string, patterns []string) bool {
+	_, name, qualified := strings.Cut(table, ".")
+	for _, pattern := range patterns {
+		pattern = strings.ReplaceAll(pattern, `"`, "")
+		if ok, _ := path.Match(pattern, table); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, name); ok && qualified && !strings.Contains(pattern, ".") {
+			return true
+		}
+	}
+	return false
+}
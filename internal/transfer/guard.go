@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"dbear/internal/config"
+	"dbear/internal/connection"
 )
 
 var allowedDestinationHosts = map[string]struct{}{
@@ -14,6 +15,14 @@ var allowedDestinationHosts = map[string]struct{}{
 
 func ValidateDestination(destination config.Connection) error {
 	if destination.Type == config.TypeSQLite {
+		if destination.ReadOnly {
+			return fmt.Errorf("refusing to transfer to destination %q: the sqlite connection is read-only", destination.Name)
+		}
+		return nil
+	}
+
+	// Unix sockets are only reachable on this machine.
+	if connection.UsesSocket(destination) {
 		return nil
 	}
 
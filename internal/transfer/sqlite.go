@@ -4,12 +4,44 @@ import (
 	"bytes"
 	"fmt"
 	"os/exec"
+	"strings"
 
 	"dbear/internal/config"
+	"dbear/internal/connection"
 )
 
-func DumpSQLite(conn config.Connection) ([]byte, error) {
-	cmd := exec.Command("sqlite3", conn.Database, ".dump")
+// DumpSQLite dumps a SQLite database as SQL, keeping the tables selected by
+// options
+func DumpSQLite(conn config.Connection, options DumpOptions) ([]byte, error) {
+	path, err := connection.CheckSQLiteFile(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	dumpCommand := ".dump"
+	if len(options.Tables) > 0 || len(options.ExcludeTables) > 0 {
+		// .dump takes the tables to keep rather than the ones to skip
+		tables, err := keptSQLiteTables(conn, options.Tables, options.ExcludeTables)
+		if err != nil {
+			return nil, err
+		}
+		if len(tables) == 0 {
+			return nil, fmt.Errorf("no table of '%s' is left to dump", conn.Name)
+		}
+		// .dump reads its arguments as LIKE patterns escaped with \
+		escaper := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "'", "''")
+		for _, table := range tables {
+			dumpCommand += " '" + escaper.Replace(table) + "'"
+		}
+	}
+
+	args := []string{}
+	if conn.ReadOnly {
+		args = append(args, "-readonly")
+	}
+	args = append(args, path, dumpCommand)
+
+	cmd := exec.Command("sqlite3", args...)
 
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -24,7 +56,16 @@ func DumpSQLite(conn config.Connection) ([]byte, error) {
 }
 
 func RestoreSQLite(conn config.Connection, dumpData []byte) error {
-	cmd := exec.Command("sqlite3", conn.Database)
+	if conn.ReadOnly {
+		return fmt.Errorf("sqlite connection '%s' is read-only", conn.Name)
+	}
+
+	path, err := connection.SQLitePath(conn)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("sqlite3", path)
 	cmd.Stdin = bytes.NewReader(dumpData)
 
 	var stderr bytes.Buffer
@@ -6,7 +6,7 @@ import (
 	"dbear/internal/config"
 )
 
-func Transfer(source, dest config.Connection, schemas []string) error {
+func Transfer(source, dest config.Connection, options DumpOptions) error {
 	if err := ValidateDestination(dest); err != nil {
 		return err
 	}
@@ -17,15 +17,15 @@ func Transfer(source, dest config.Connection, schemas []string) error {
 
 	switch source.Type {
 	case config.TypePostgreSQL, config.TypeMySQL:
-		return transferWithDocker(source, dest, schemas)
+		return transferWithDocker(source, dest, options)
 	case config.TypeSQLite:
-		return transferSQLite(source, dest)
+		return transferSQLite(source, dest, options)
 	default:
 		return fmt.Errorf("unsupported database type: %s", source.Type)
 	}
 }
 
-func transferWithDocker(source, dest config.Connection, schemas []string) error {
+func transferWithDocker(source, dest config.Connection, options DumpOptions) error {
 	sourceVersion, err := DetectVersion(source)
 	if err != nil {
 		return fmt.Errorf("failed to detect source version: %w", err)
@@ -46,7 +46,7 @@ func transferWithDocker(source, dest config.Connection, schemas []string) error
 		return fmt.Errorf("failed to determine docker image for destination database")
 	}
 
-	dumpData, err := DumpDatabase(source, sourceImage, schemas)
+	dumpData, err := DumpDatabase(source, sourceImage, options)
 	if err != nil {
 		return fmt.Errorf("failed to dump source database: %w", err)
 	}
@@ -58,8 +58,8 @@ func transferWithDocker(source, dest config.Connection, schemas []string) error
 	return nil
 }
 
-func transferSQLite(source, dest config.Connection) error {
-	dumpData, err := DumpSQLite(source)
+func transferSQLite(source, dest config.Connection, options DumpOptions) error {
+	dumpData, err := DumpSQLite(source, options)
 	if err != nil {
 		return fmt.Errorf("failed to dump source database: %w", err)
 	}
@@ -1,14 +1,13 @@
 package transfer
 
 import (
-	"database/sql"
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
 
 	"dbear/internal/config"
-	_ "github.com/go-sql-driver/mysql"
-	_ "github.com/lib/pq"
+	"dbear/internal/connection"
 )
 
 func DetectVersion(conn config.Connection) (string, error) {
@@ -25,16 +24,7 @@ func DetectVersion(conn config.Connection) (string, error) {
 }
 
 func detectPostgreSQLVersion(conn config.Connection) (string, error) {
-	dsn := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		conn.Host,
-		conn.Port,
-		conn.Username,
-		conn.Password,
-		conn.Database,
-	)
-
-	db, err := sql.Open("postgres", dsn)
+	db, err := connection.OpenDB(conn)
 	if err != nil {
 		return "", fmt.Errorf("failed to connect: %w", err)
 	}
@@ -55,16 +45,7 @@ func detectPostgreSQLVersion(conn config.Connection) (string, error) {
 }
 
 func detectMySQLVersion(conn config.Connection) (string, error) {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%d)/%s",
-		conn.Username,
-		conn.Password,
-		conn.Host,
-		conn.Port,
-		conn.Database,
-	)
-
-	db, err := sql.Open("mysql", dsn)
+	db, err := connection.OpenDB(conn)
 	if err != nil {
 		return "", fmt.Errorf("failed to connect: %w", err)
 	}
@@ -84,7 +65,18 @@ func detectMySQLVersion(conn config.Connection) (string, error) {
 	return majorVersion, nil
 }
 
+// detectSQLiteVersion accepts a missing file, since sqlite3 creates it when the
+// connection is used as a transfer destination.
 func detectSQLiteVersion(conn config.Connection) (string, error) {
+	path, err := connection.SQLitePath(conn)
+	if err != nil {
+		return "", err
+	}
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return "", fmt.Errorf("sqlite database path %s is a directory", path)
+	}
+
 	return "native", nil
 }
 
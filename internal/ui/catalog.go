@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"dbear/internal/catalog"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// DisplayCatalog prints the schemas, tables, views and functions of c
+func DisplayCatalog(w io.Writer, c *catalog.Catalog) {
+	fmt.Fprintln(w, headerStyle.Render("Schemas: "+strings.Join(c.Schemas, ", ")))
+	if len(c.Tables) == 0 && len(c.Views) == 0 && len(c.Functions) == 0 {
+		fmt.Fprintln(w, "No tables found.")
+		return
+	}
+
+	for _, table := range c.Tables {
+		info := []string{formatRowEstimate(table.RowEstimate)}
+		if table.Size >= 0 {
+			info = append(info, FormatSize(table.Size))
+		}
+		if table.Comment != "" {
+			info = append(info, table.Comment)
+		}
+		fmt.Fprintln(w, nameStyle.Render(c.QualifiedName(table.Schema, table.Name))+" "+
+			typeStyle.Render("(table)")+" "+infoStyle.Render(strings.Join(info, " | ")))
+
+		printColumns(w, table.Columns)
+		if len(table.PrimaryKey) > 0 {
+			fmt.Fprintf(w, "  Primary key: (%s)\n", strings.Join(table.PrimaryKey, ", "))
+		}
+		for _, index := range table.Indexes {
+			if index.Primary {
+				continue
+			}
+			kind := "Index"
+			if index.Unique {
+				kind = "Unique index"
+			}
+			fmt.Fprintf(w, "  %s %s: (%s)\n", kind, index.Name, strings.Join(index.Columns, ", "))
+		}
+		for _, constraint := range table.Constraints {
+			fmt.Fprintf(w, "  Constraint %s: %s\n", constraint.Name, constraint.Definition)
+		}
+		for _, key := range table.ForeignKeys {
+			line := "  Foreign key"
+			if key.Name != "" {
+				line += " " + key.Name
+			}
+			line += fmt.Sprintf(": (%s) -> %s (%s)", strings.Join(key.Columns, ", "),
+				c.QualifiedName(key.ReferencedSchema, key.ReferencedTable), strings.Join(key.ReferencedColumns, ", "))
+			if key.OnDelete != "" && key.OnDelete != "NO ACTION" {
+				line += " on delete " + key.OnDelete
+			}
+			fmt.Fprintln(w, line)
+		}
+		fmt.Fprintln(w)
+	}
+
+	for _, view := range c.Views {
+		kind := "(view)"
+		if view.Materialized {
+			kind = "(materialized view)"
+		}
+		fmt.Fprintln(w, nameStyle.Render(c.QualifiedName(view.Schema, view.Name))+" "+typeStyle.Render(kind))
+		printColumns(w, view.Columns)
+		fmt.Fprintln(w)
+	}
+
+	for _, function := range c.Functions {
+		fmt.Fprintln(w, nameStyle.Render(c.QualifiedName(function.Schema, function.Name)+"("+function.Arguments+")")+" "+
+			typeStyle.Render("("+function.Kind+")"))
+	}
+}
+
+// printColumns prints one aligned line per column
+func printColumns(w io.Writer, columns []catalog.Column) {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, column := range columns {
+		nullable := "not null"
+		if column.Nullable {
+			nullable = "null"
+		}
+		cells := []string{"    " + column.Name, column.Type, nullable, "", ""}
+		if column.Default != nil {
+			cells[3] = "default " + *column.Default
+		}
+		if column.Comment != "" {
+			cells[4] = "-- " + column.Comment
+		}
+		for len(cells) > 0 && cells[len(cells)-1] == "" {
+			cells = cells[:len(cells)-1]
+		}
+		line := strings.Join(cells, "\t")
+		fmt.Fprintln(writer, line)
+	}
+	writer.Flush()
+}
+
+func formatRowEstimate(estimate int64) string {
+	switch {
+	case estimate < 0:
+		return "rows unknown"
+	case estimate == 1:
+		return "~1 row"
+	default:
+		return fmt.Sprintf("~%d rows", estimate)
+	}
+}
+
+// FormatSize renders a size in bytes with a binary unit, such as 1.5 MiB
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size) / unit
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB"}
+	i := 0
+	for value >= unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
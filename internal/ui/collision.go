@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"dbear/internal/config"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+// Collision resolutions offered by ResolveNameCollision
+const (
+	CollisionRename    = "rename"
+	CollisionOverwrite = "overwrite"
+	CollisionSkip      = "skip"
+)
+
+// ResolveNameCollision asks what to do with a connection whose name is already
+// taken: rename it, overwrite the existing connection or skip it. For renames it
+// returns the new name, which is checked against taken.
+func ResolveNameCollision(conn config.Connection, taken func(name string) bool) (string, string, error) {
+	action := CollisionRename
+	name := SuggestConnectionName(conn.Name, taken)
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title(fmt.Sprintf("Connection '%s' already exists", conn.Name)).
+				Options(
+					huh.NewOption("Import under another name", CollisionRename),
+					huh.NewOption("Overwrite the existing connection", CollisionOverwrite),
+					huh.NewOption("Skip this connection", CollisionSkip),
+				).
+				Value(&action),
+		),
+		huh.NewGroup(
+			huh.NewInput().
+				Title("New Name").
+				Value(&name).
+				Validate(func(s string) error {
+					s = strings.TrimSpace(s)
+					if s == "" {
+						return fmt.Errorf("connection name is required")
+					}
+					if taken(s) {
+						return fmt.Errorf("connection '%s' already exists", s)
+					}
+					return nil
+				}),
+		).WithHideFunc(func() bool {
+			return action != CollisionRename
+		}),
+	).WithTheme(huh.ThemeCharm())
+
+	if err := form.Run(); err != nil {
+		return "", "", err
+	}
+
+	return action, strings.TrimSpace(name), nil
+}
+
+// SuggestConnectionName appends a counter to name until it is free
+func SuggestConnectionName(name string, taken func(name string) bool) string {
+	suggestion := name
+	for i := 2; taken(suggestion); i++ {
+		suggestion = fmt.Sprintf("%s-%d", name, i)
+	}
+	return suggestion
+}
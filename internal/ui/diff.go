@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"dbear/internal/diff"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var diffMarkers = map[diff.Action]string{
+	diff.Create: lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Render("+"),
+	diff.Drop:   lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("-"),
+	diff.Alter:  lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("~"),
+}
+
+// DisplaySchemaDiff prints changes as a tree, marking what the destination
+// lacks with +, what it has in excess with - and what differs with ~
+func DisplaySchemaDiff(w io.Writer, changes []diff.Change) {
+	for _, change := range changes {
+		fmt.Fprintln(w, formatChange(change))
+		for i, tableChange := range change.Changes {
+			branch := "├─ "
+			if i == len(change.Changes)-1 {
+				branch = "└─ "
+			}
+			fmt.Fprintln(w, "  "+infoStyle.Render(branch)+formatChange(tableChange))
+		}
+	}
+
+	created, dropped, altered := countChanges(changes)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, infoStyle.Render(fmt.Sprintf("%d to create, %d to drop, %d to alter", created, dropped, altered)))
+}
+
+func formatChange(change diff.Change) string {
+	line := diffMarkers[change.Action] + " " + typeStyle.Render(change.Object) + " " + nameStyle.Render(change.Name)
+	if len(change.Details) > 0 {
+		line += " " + infoStyle.Render(strings.Join(change.Details, ", "))
+	}
+	return line
+}
+
+// countChanges counts the changes by action, those of altered tables
+// included
+func countChanges(changes []diff.Change) (created, dropped, altered int) {
+	for _, change := range changes {
+		if len(change.Changes) > 0 {
+			c, d, a := countChanges(change.Changes)
+			created, dropped, altered = created+c, dropped+d, altered+a
+			continue
+		}
+		switch change.Action {
+		case diff.Create:
+			created++
+		case diff.Drop:
+			dropped++
+		case diff.Alter:
+			altered++
+		}
+	}
+	return created, dropped, altered
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"dbear/internal/config"
+	"dbear/internal/connection"
 	"fmt"
 	"strconv"
 	"github.com/charmbracelet/huh"
@@ -9,6 +10,9 @@ import (
 
 func CreateConnectionForm() (*config.Connection, error) {
 	var name, dbType, host, portStr, database, username, password string
+	var sslMode, sslRootCert, sslCert, sslKey string
+	var paramsStr, socket, sqlitePath string
+	var readOnly bool
 
 	form := huh.NewForm(
 		huh.NewGroup(
@@ -31,6 +35,8 @@ func CreateConnectionForm() (*config.Connection, error) {
 					huh.NewOption("SQLite", config.TypeSQLite),
 				).
 				Value(&dbType),
+		),
+		huh.NewGroup(
 			huh.NewInput().
 				Title("Host").
 				Description("Database host address").
@@ -40,7 +46,13 @@ func CreateConnectionForm() (*config.Connection, error) {
 				Title("Port").
 				Description("Database port number").
 				Value(&portStr).
-				Placeholder("5432"),
+				PlaceholderFunc(func() string {
+					return strconv.Itoa(defaultPort(dbType))
+				}, &dbType),
+			huh.NewInput().
+				Title("Socket").
+				Description("Unix socket path, used instead of host and port (optional)").
+				Value(&socket),
 			huh.NewInput().
 				Title("Database").
 				Description("Database name").
@@ -54,6 +66,69 @@ func CreateConnectionForm() (*config.Connection, error) {
 				Description("Database password").
 				Value(&password).
 				EchoMode(huh.EchoModePassword),
+		).WithHideFunc(func() bool {
+			return dbType == config.TypeSQLite
+		}),
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Database File").
+				Description("Path to the SQLite database file").
+				Value(&sqlitePath).
+				Validate(func(s string) error {
+					if s == "" {
+						return fmt.Errorf("database file is required")
+					}
+					return nil
+				}),
+			huh.NewConfirm().
+				Title("Read-only").
+				Description("Open the database file in read-only mode").
+				Value(&readOnly),
+		).WithHideFunc(func() bool {
+			return dbType != config.TypeSQLite
+		}),
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("SSL Mode").
+				Description("TLS requirements for this connection").
+				Options(
+					huh.NewOption("Default", ""),
+					huh.NewOption("disable", config.SSLModeDisable),
+					huh.NewOption("allow", config.SSLModeAllow),
+					huh.NewOption("prefer", config.SSLModePrefer),
+					huh.NewOption("require", config.SSLModeRequire),
+					huh.NewOption("verify-ca", config.SSLModeVerifyCA),
+					huh.NewOption("verify-full", config.SSLModeVerifyFull),
+				).
+				Value(&sslMode),
+			huh.NewInput().
+				Title("CA Certificate").
+				Description("Path to the CA certificate used to verify the server (optional)").
+				Value(&sslRootCert),
+			huh.NewInput().
+				Title("Client Certificate").
+				Description("Path to the client certificate (optional)").
+				Value(&sslCert),
+			huh.NewInput().
+				Title("Client Key").
+				Description("Path to the client certificate key (optional)").
+				Value(&sslKey),
+		).WithHideFunc(func() bool {
+			return dbType == config.TypeSQLite
+		}),
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Parameters").
+				Description("Extra driver parameters as a query string (optional)").
+				Value(&paramsStr).
+				Placeholder("application_name=dbear&connect_timeout=5").
+				Validate(func(s string) error {
+					params, err := connection.ParseParams(s)
+					if err != nil || dbType != config.TypeMySQL {
+						return err
+					}
+					return connection.CheckMySQLParams(params)
+				}),
 		),
 	).WithTheme(huh.ThemeCharm())
 
@@ -61,30 +136,63 @@ func CreateConnectionForm() (*config.Connection, error) {
 		return nil, err
 	}
 
-	portValue := 5432
-	if portStr != "" {
-		if parsed, err := strconv.Atoi(portStr); err == nil {
-			portValue = parsed
+	var conn *config.Connection
+	if dbType == config.TypeSQLite {
+		path, err := connection.ExpandPath(sqlitePath, "")
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	if dbType == config.TypeSQLite {
-		portValue = 0
-		if host == "" {
-			host = "localhost"
+		conn = &config.Connection{
+			Name:     name,
+			Type:     dbType,
+			Database: path,
+			ReadOnly: readOnly,
 		}
+	} else {
+		portValue := defaultPort(dbType)
+		if portStr != "" {
+			if parsed, err := strconv.Atoi(portStr); err == nil {
+				portValue = parsed
+			}
+		}
+
+		socketPath, err := connection.ExpandPath(socket, "")
+		if err != nil {
+			return nil, err
+		}
+
+		conn = &config.Connection{
+			Name:     name,
+			Type:     dbType,
+			Host:     host,
+			Port:     portValue,
+			Socket:   socketPath,
+			Database: database,
+			Username: username,
+			Password: password,
+		}
+	}
+
+	params, err := connection.ParseParams(paramsStr)
+	if err != nil {
+		return nil, err
 	}
+	conn.Params = params
 
-	conn := &config.Connection{
-		Name:     name,
-		Type:     dbType,
-		Host:     host,
-		Port:     portValue,
-		Database: database,
-		Username: username,
-		Password: password,
+	if dbType != config.TypeSQLite {
+		conn.SSLMode = sslMode
+		conn.SSLRootCert = sslRootCert
+		conn.SSLCert = sslCert
+		conn.SSLKey = sslKey
 	}
 
 	return conn, nil
 }
 
+func defaultPort(dbType string) int {
+	if dbType == config.TypeMySQL {
+		return 3306
+	}
+	return 5432
+}
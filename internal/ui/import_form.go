@@ -21,6 +21,7 @@ func ImportEnvForm() (*importer.EnvImportOptions, error) {
 	var connectionStringKey string
 	var hostKey string
 	var portKey string
+	var socketKey string
 	var databaseKey string
 	var usernameKey string
 	var passwordKey string
@@ -135,6 +136,11 @@ func ImportEnvForm() (*importer.EnvImportOptions, error) {
 					Description("Environment variable name for database port").
 					Value(&portKey).
 					Placeholder("DB_PORT"),
+				huh.NewInput().
+					Title("Socket Key").
+					Description("Environment variable name for the unix socket path").
+					Value(&socketKey).
+					Placeholder("DB_SOCKET"),
 				huh.NewInput().
 					Title("Database Key").
 					Description("Environment variable name for database name").
@@ -164,6 +170,9 @@ func ImportEnvForm() (*importer.EnvImportOptions, error) {
 		if portKey == "" {
 			portKey = "DB_PORT"
 		}
+		if socketKey == "" {
+			socketKey = "DB_SOCKET"
+		}
 		if databaseKey == "" {
 			databaseKey = "DB_DATABASE"
 		}
@@ -184,6 +193,7 @@ func ImportEnvForm() (*importer.EnvImportOptions, error) {
 		ConnectionStringKey: connectionStringKey,
 		HostKey:             hostKey,
 		PortKey:             portKey,
+		SocketKey:           socketKey,
 		DatabaseKey:         databaseKey,
 		UsernameKey:         usernameKey,
 		PasswordKey:         passwordKey,
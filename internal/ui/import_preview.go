@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"dbear/internal/config"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+// SelectConnectionsToImport lets the user pick which of the proposed connections
+// to import. All connections are selected initially, and imported without
+// asking outside a terminal.
+func SelectConnectionsToImport(connections []config.Connection) ([]config.Connection, error) {
+	if len(connections) == 0 {
+		return nil, fmt.Errorf("no connections to import")
+	}
+	if !IsInteractive() {
+		return connections, nil
+	}
+
+	options := make([]huh.Option[int], len(connections))
+	selected := make([]int, len(connections))
+	for i, conn := range connections {
+		options[i] = huh.NewOption(importPreviewLabel(conn), i).Selected(true)
+		selected[i] = i
+	}
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[int]().
+				Title("Connections to import").
+				Description("Space toggles a connection, enter confirms").
+				Options(options...).
+				Value(&selected),
+		),
+	).WithTheme(huh.ThemeCharm())
+
+	if err := form.Run(); err != nil {
+		return nil, err
+	}
+
+	sort.Ints(selected)
+	chosen := make([]config.Connection, 0, len(selected))
+	for _, index := range selected {
+		chosen = append(chosen, connections[index])
+	}
+
+	return chosen, nil
+}
+
+func importPreviewLabel(conn config.Connection) string {
+	label := fmt.Sprintf("%s (%s) - %s/%s", conn.Name, conn.Type, connectionAddress(conn), conn.Database)
+	if conn.Type == config.TypeSQLite {
+		label = fmt.Sprintf("%s (%s) - %s", conn.Name, conn.Type, conn.Database)
+	}
+	if len(conn.Tags) > 0 {
+		label += " [" + strings.Join(conn.Tags, ", ") + "]"
+	}
+	return label
+}
@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/huh"
+)
+
+// SelectNames lets the user pick some of names, such as schemas or tables.
+// All names are selected initially, and the chosen ones are returned in the
+// order of names.
+func SelectNames(title string, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("nothing to select")
+	}
+
+	options := make([]huh.Option[string], len(names))
+	selected := make([]string, len(names))
+	for i, name := range names {
+		options[i] = huh.NewOption(name, name).Selected(true)
+		selected[i] = name
+	}
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title(title).
+				Description("Space toggles an entry, / filters, enter confirms").
+				Options(options...).
+				Filterable(true).
+				Value(&selected).
+				Validate(func(chosen []string) error {
+					if len(chosen) == 0 {
+						return fmt.Errorf("select at least one entry")
+					}
+					return nil
+				}),
+		),
+	).WithTheme(huh.ThemeCharm())
+
+	if err := form.Run(); err != nil {
+		return nil, err
+	}
+
+	chosen := map[string]bool{}
+	for _, name := range selected {
+		chosen[name] = true
+	}
+	ordered := make([]string, 0, len(selected))
+	for _, name := range names {
+		if chosen[name] {
+			ordered = append(ordered, name)
+		}
+	}
+	return ordered, nil
+}
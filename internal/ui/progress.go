@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
@@ -73,6 +74,12 @@ func (m progressModel) View() string {
 }
 
 func RunWithSpinner(message string, task progressTask) (interface{}, error) {
+	// Scripts and CI get a plain message instead of an animation
+	if !IsTerminal(os.Stdout) {
+		fmt.Println(message)
+		return task()
+	}
+
 	m := newProgressModel(message, task)
 	p := tea.NewProgram(m)
 	final, err := p.Run()
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"dbear/internal/config"
+	"fmt"
+
+	"github.com/charmbracelet/huh"
+)
+
+// PromptPasswords asks for the password of each connection at the given
+// indexes and stores the answers in connections. Empty answers leave the
+// password empty.
+func PromptPasswords(connections []config.Connection, indexes []int) error {
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	passwords := make([]string, len(indexes))
+	fields := make([]huh.Field, len(indexes))
+	for i, index := range indexes {
+		conn := connections[index]
+		fields[i] = huh.NewInput().
+			Title(fmt.Sprintf("Password for '%s'", conn.Name)).
+			Description(fmt.Sprintf("%s@%s/%s", conn.Username, connectionAddress(conn), conn.Database)).
+			Value(&passwords[i]).
+			EchoMode(huh.EchoModePassword)
+	}
+
+	form := huh.NewForm(huh.NewGroup(fields...)).WithTheme(huh.ThemeCharm())
+	if err := form.Run(); err != nil {
+		return err
+	}
+
+	for i, index := range indexes {
+		connections[index].Password = passwords[i]
+	}
+	return nil
+}
@@ -25,14 +25,19 @@ func (i connectionItem) FilterValue() string {
 }
 
 func (i connectionItem) Title() string {
-	portStr := ""
-	if i.conn.Port > 0 {
-		portStr = fmt.Sprintf(":%d", i.conn.Port)
+	if i.conn.Type == config.TypeSQLite {
+		return fmt.Sprintf("%s (%s) - %s", i.conn.Name, i.conn.Type, i.conn.Database)
 	}
-	return fmt.Sprintf("%s (%s) - %s%s/%s", i.conn.Name, i.conn.Type, i.conn.Host, portStr, i.conn.Database)
+	return fmt.Sprintf("%s (%s) - %s/%s", i.conn.Name, i.conn.Type, connectionAddress(i.conn), i.conn.Database)
 }
 
 func (i connectionItem) Description() string {
+	if i.conn.Type == config.TypeSQLite {
+		return fmt.Sprintf("%s file", i.conn.Type)
+	}
+	if i.conn.Socket != "" {
+		return fmt.Sprintf("%s on %s", i.conn.Type, i.conn.Socket)
+	}
 	return fmt.Sprintf("%s on %s", i.conn.Type, i.conn.Host)
 }
 
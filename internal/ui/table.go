@@ -3,6 +3,8 @@ package ui
 import (
 	"dbear/internal/config"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -24,29 +26,49 @@ var (
 			MarginBottom(1)
 )
 
-func DisplayConnectionsList(connections []config.Connection) error {
+func DisplayConnectionsList(w io.Writer, connections []config.Connection) error {
 	if len(connections) == 0 {
-		fmt.Println("No connections found.")
+		fmt.Fprintln(w, "No connections found.")
 		return nil
 	}
 
-	fmt.Println(headerStyle.Render("Database Connections"))
-	fmt.Println()
+	fmt.Fprintln(w, headerStyle.Render("Database Connections"))
+	fmt.Fprintln(w)
 
 	for _, conn := range connections {
-		portStr := ""
-		if conn.Port > 0 {
-			portStr = fmt.Sprintf(":%d", conn.Port)
+		info := "  Host: " + connectionAddress(conn) + " | Database: " + conn.Database
+		if conn.Type == config.TypeSQLite {
+			info = "  File: " + conn.Database
+			if conn.ReadOnly {
+				info += " (read-only)"
+			}
+		}
+		if len(conn.Tags) > 0 {
+			info += " | Tags: " + strings.Join(conn.Tags, ", ")
+		}
+		if conn.Origin != "" {
+			info += " | From: " + conn.Origin
 		}
-		hostDisplay := conn.Host + portStr
 
 		output := itemStyle.Render(
 			nameStyle.Render(conn.Name) + " " +
 				typeStyle.Render("("+conn.Type+")") + "\n" +
-				infoStyle.Render("  Host: "+hostDisplay+" | Database: "+conn.Database),
+				infoStyle.Render(info),
 		)
-		fmt.Println(output)
+		fmt.Fprintln(w, output)
 	}
 
 	return nil
 }
+
+// connectionAddress describes where a server connection is reached: its unix
+// socket when one is configured, otherwise host and port.
+func connectionAddress(conn config.Connection) string {
+	if conn.Socket != "" {
+		return conn.Socket
+	}
+	if conn.Port > 0 {
+		return fmt.Sprintf("%s:%d", conn.Host, conn.Port)
+	}
+	return conn.Host
+}
@@ -0,0 +1,18 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// IsInteractive reports whether dbear runs attached to a terminal, where it can
+// show forms, pickers and spinners
+func IsInteractive() bool {
+	return IsTerminal(os.Stdin) && IsTerminal(os.Stdout)
+}
+
+// IsTerminal reports whether file is a terminal
+func IsTerminal(file *os.File) bool {
+	return isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())
+}
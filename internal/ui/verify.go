@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"dbear/internal/verify"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	okStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	differsStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+)
+
+// DisplayVerification prints one aligned line per compared table, with the
+// tables that differ marked as such
+func DisplayVerification(w io.Writer, results []verify.Result) {
+	checksums := false
+	for _, result := range results {
+		checksums = checksums || result.Checksum != ""
+	}
+
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := []string{"TABLE", "SOURCE ROWS", "DESTINATION ROWS"}
+	if checksums {
+		header = append(header, "CHECKSUM")
+	}
+	fmt.Fprintln(writer, strings.Join(append(header, "STATUS"), "\t"))
+
+	for _, result := range results {
+		destRows := "missing"
+		if result.DestRows >= 0 {
+			destRows = fmt.Sprint(result.DestRows)
+		}
+		cells := []string{result.Table, fmt.Sprint(result.SourceRows), destRows}
+		if checksums && result.Checksum == "" {
+			cells = append(cells, "-")
+		} else if checksums {
+			cells = append(cells, result.Checksum)
+		}
+		// Styles go last, as tabwriter counts their escape codes as width
+		status := okStyle.Render("ok")
+		if result.Differs() {
+			status = differsStyle.Render("differs")
+			if result.Difference != "" {
+				status += " " + infoStyle.Render(result.Difference)
+			}
+		}
+		fmt.Fprintln(writer, strings.Join(append(cells, status), "\t"))
+	}
+	writer.Flush()
+}
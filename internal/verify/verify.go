@@ -0,0 +1,252 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"dbear/internal/catalog"
+	"dbear/internal/config"
+	"dbear/internal/query"
+)
+
+// DefaultChunkSize is the number of rows per checksum chunk
+const DefaultChunkSize = 10000
+
+// Checksum outcomes of a table
+const (
+	ChecksumMatch        = "match"
+	ChecksumDiffers      = "differs"
+	ChecksumNoPrimaryKey = "no primary key"
+)
+
+// Options tells Compare how closely to compare tables
+type Options struct {
+	// Checksums compares the rows of tables with a primary key, besides
+	// counting them
+	Checksums bool
+	// ChunkSize is the number of rows per checksum, DefaultChunkSize when 0
+	ChunkSize int
+}
+
+// Result is how a table of the source compares with the destination
+type Result struct {
+	Table      string `json:"table"`
+	SourceRows int64  `json:"source_rows"`
+	// DestRows is -1 when the destination has no such table
+	DestRows int64 `json:"destination_rows"`
+	// Checksum is empty when the rows were not compared
+	Checksum string `json:"checksum,omitempty"`
+	// Difference locates the first chunk of rows whose checksums differ
+	Difference string `json:"difference,omitempty"`
+}
+
+// Differs tells whether the destination table is missing, or holds other rows
+// than the source as far as they were compared
+func (r Result) Differs() bool {
+	return r.DestRows != r.SourceRows || r.Checksum == ChecksumDiffers
+}
+
+// Compare counts the rows of the tables of c, read from source, and those of
+// the tables of the same names in dest. With checksums, tables whose counts
+// match are read from both sides in primary key order and compared chunk by
+// chunk.
+func Compare(ctx context.Context, source, dest *sql.DB, c *catalog.Catalog, options Options) ([]Result, error) {
+	if options.ChunkSize <= 0 {
+		options.ChunkSize = DefaultChunkSize
+	}
+
+	destTables, err := catalog.ListTables(ctx, dest, c.Type, catalog.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list destination tables: %w", err)
+	}
+	existing := map[string]bool{}
+	for _, table := range destTables {
+		existing[table] = true
+	}
+
+	results := []Result{}
+	for i := range c.Tables {
+		table := &c.Tables[i]
+		result := Result{Table: c.QualifiedName(table.Schema, table.Name), DestRows: -1}
+		name := quoteTable(c.Type, table)
+
+		if err := source.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+name).Scan(&result.SourceRows); err != nil {
+			return nil, fmt.Errorf("failed to count rows of %s in source: %w", result.Table, err)
+		}
+		if !existing[result.Table] {
+			results = append(results, result)
+			continue
+		}
+		if err := dest.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+name).Scan(&result.DestRows); err != nil {
+			return nil, fmt.Errorf("failed to count rows of %s in destination: %w", result.Table, err)
+		}
+
+		if options.Checksums && result.DestRows == result.SourceRows {
+			if len(table.PrimaryKey) == 0 {
+				result.Checksum = ChecksumNoPrimaryKey
+			} else {
+				difference, err := compareChunks(ctx, source, dest, c.Type, table, options.ChunkSize)
+				if err != nil {
+					return nil, fmt.Errorf("failed to compare rows of %s: %w", result.Table, err)
+				}
+				result.Checksum = ChecksumMatch
+				if difference != "" {
+					result.Checksum = ChecksumDiffers
+					result.Difference = difference
+				}
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// quoteTable quotes the name of table, qualified by its schema on PostgreSQL
+func quoteTable(dbType string, table *catalog.Table) string {
+	name := query.QuoteName(dbType, table.Name)
+	if dbType == config.TypePostgreSQL {
+		name = query.QuoteName(dbType, table.Schema) + "." + name
+	}
+	return name
+}
+
+// compareChunks reads table from both databases in primary key order and
+// returns where the first chunk of rows differing between them lies, or ""
+// when they hold the same rows
+func compareChunks(ctx context.Context, source, dest *sql.DB, dbType string, table *catalog.Table, chunkSize int) (string, error) {
+	columns := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		columns[i] = query.QuoteName(dbType, column.Name)
+	}
+	keys := make([]string, len(table.PrimaryKey))
+	for i, column := range table.PrimaryKey {
+		keys[i] = query.QuoteName(dbType, column)
+	}
+	statement := fmt.Sprintf("SELECT %s FROM %s ORDER BY %s",
+		strings.Join(columns, ", "), quoteTable(dbType, table), strings.Join(keys, ", "))
+
+	sourceReader, err := newChunkReader(ctx, source, statement, table)
+	if err != nil {
+		return "", err
+	}
+	defer sourceReader.close()
+	destReader, err := newChunkReader(ctx, dest, statement, table)
+	if err != nil {
+		return "", err
+	}
+	defer destReader.close()
+
+	for offset := int64(0); ; offset += int64(chunkSize) {
+		sourceChunk, err := sourceReader.next(chunkSize)
+		if err != nil {
+			return "", err
+		}
+		destChunk, err := destReader.next(chunkSize)
+		if err != nil {
+			return "", err
+		}
+		if sourceChunk.sum != destChunk.sum || sourceChunk.rows != destChunk.rows {
+			return fmt.Sprintf("rows %d to %d of the source, from %s to %s",
+				offset+1, offset+int64(sourceChunk.rows), sourceChunk.firstKey, sourceChunk.lastKey), nil
+		}
+		if sourceChunk.rows < chunkSize {
+			return "", nil
+		}
+	}
+}
+
+// chunk is the checksum of consecutive rows
+type chunk struct {
+	sum               [sha256.Size]byte
+	rows              int
+	firstKey, lastKey string
+}
+
+// chunkReader reads the rows of a table chunk by chunk
+type chunkReader struct {
+	rows       *sql.Rows
+	values     []any
+	pointers   []any
+	keyColumns []int
+	table      *catalog.Table
+}
+
+func newChunkReader(ctx context.Context, db *sql.DB, statement string, table *catalog.Table) (*chunkReader, error) {
+	rows, err := db.QueryContext(ctx, statement)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &chunkReader{rows: rows, table: table, values: make([]any, len(table.Columns)), pointers: make([]any, len(table.Columns))}
+	for i := range reader.values {
+		reader.pointers[i] = &reader.values[i]
+	}
+	for _, key := range table.PrimaryKey {
+		for i, column := range table.Columns {
+			if column.Name == key {
+				reader.keyColumns = append(reader.keyColumns, i)
+			}
+		}
+	}
+	return reader, nil
+}
+
+// next reads up to size rows and sums them up
+func (r *chunkReader) next(size int) (chunk, error) {
+	var c chunk
+	hash := sha256.New()
+	var row bytes.Buffer
+	for c.rows < size && r.rows.Next() {
+		if err := r.rows.Scan(r.pointers...); err != nil {
+			return c, err
+		}
+		// NULL is told apart from empty values, and values from each other
+		row.Reset()
+		for _, value := range r.values {
+			if value == nil {
+				row.WriteByte(0)
+			} else {
+				row.WriteByte(1)
+				row.WriteString(valueText(value))
+			}
+			row.WriteByte(0x1f)
+		}
+		hash.Write(row.Bytes())
+
+		key := r.key()
+		if c.rows == 0 {
+			c.firstKey = key
+		}
+		c.lastKey = key
+		c.rows++
+	}
+	if err := r.rows.Err(); err != nil {
+		return c, err
+	}
+	copy(c.sum[:], hash.Sum(nil))
+	return c, nil
+}
+
+// key describes the primary key of the row last read, such as id=42
+func (r *chunkReader) key() string {
+	parts := make([]string, len(r.keyColumns))
+	for i, column := range r.keyColumns {
+		parts[i] = r.table.Columns[column].Name + "=" + valueText(r.values[column])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (r *chunkReader) close() {
+	r.rows.Close()
+}
+
+func valueText(value any) string {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return query.FormatValue(value, "NULL")
+}
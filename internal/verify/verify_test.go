@@ -0,0 +1,119 @@
+package verify
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dbear/internal/catalog"
+	"dbear/internal/config"
+
+	_ "modernc.org/sqlite"
+)
+
+// openSQLite creates a database from the statements of script
+func openSQLite(t *testing.T, name, script string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for _, statement := range strings.Split(script, ";\n") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("%s: %s", statement, err)
+		}
+	}
+	return db
+}
+
+const verifyTables = `CREATE TABLE same (id integer PRIMARY KEY, name text);
+CREATE TABLE changed (id integer PRIMARY KEY, name text);
+CREATE TABLE nulls (id integer PRIMARY KEY, name text);
+CREATE TABLE counted (id integer PRIMARY KEY);
+CREATE TABLE heap (name text);
+`
+
+func TestCompare(t *testing.T) {
+	source := openSQLite(t, "source.db", verifyTables+`CREATE TABLE missing (id integer);
+INSERT INTO same VALUES (1, 'a'), (2, 'b'), (3, 'c'), (4, 'd'), (5, 'e');
+INSERT INTO changed VALUES (1, 'a'), (2, 'b'), (3, 'c'), (4, 'd'), (5, 'e');
+INSERT INTO nulls VALUES (1, '');
+INSERT INTO counted VALUES (1), (2);
+INSERT INTO heap VALUES ('a');
+INSERT INTO missing VALUES (1);
+`)
+	dest := openSQLite(t, "dest.db", verifyTables+`INSERT INTO same VALUES (1, 'a'), (2, 'b'), (3, 'c'), (4, 'd'), (5, 'e');
+INSERT INTO changed VALUES (1, 'a'), (2, 'b'), (3, 'C'), (4, 'd'), (5, 'e');
+INSERT INTO nulls VALUES (1, NULL);
+INSERT INTO counted VALUES (1);
+INSERT INTO heap VALUES ('b');
+`)
+
+	c, err := catalog.Inspect(context.Background(), source, config.TypeSQLite, catalog.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Compare(context.Background(), source, dest, c, Options{Checksums: true, ChunkSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Result{
+		{Table: "changed", SourceRows: 5, DestRows: 5, Checksum: ChecksumDiffers, Difference: "rows 3 to 4 of the source, from id=3 to id=4"},
+		{Table: "counted", SourceRows: 2, DestRows: 1},
+		{Table: "heap", SourceRows: 1, DestRows: 1, Checksum: ChecksumNoPrimaryKey},
+		{Table: "missing", SourceRows: 1, DestRows: -1},
+		{Table: "nulls", SourceRows: 1, DestRows: 1, Checksum: ChecksumDiffers, Difference: "rows 1 to 1 of the source, from id=1 to id=1"},
+		{Table: "same", SourceRows: 5, DestRows: 5, Checksum: ChecksumMatch},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("got %d results, expected %d: %+v", len(results), len(expected), results)
+	}
+	for i, result := range results {
+		if result != expected[i] {
+			t.Errorf("got %+v, expected %+v", result, expected[i])
+		}
+	}
+
+	differs := map[string]bool{"changed": true, "counted": true, "missing": true, "nulls": true}
+	for _, result := range results {
+		if result.Differs() != differs[result.Table] {
+			t.Errorf("%s: got Differs %t", result.Table, result.Differs())
+		}
+	}
+
+	counts, err := Compare(context.Background(), source, dest, c, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range counts {
+		if result.Checksum != "" {
+			t.Errorf("%s: rows compared without checksums: %+v", result.Table, result)
+		}
+	}
+}
+
+func TestQuoteTable(t *testing.T) {
+	tests := []struct {
+		dbType   string
+		expected string
+	}{
+		{config.TypePostgreSQL, `"sales"."order lines"`},
+		{config.TypeMySQL, "`order lines`"},
+		{config.TypeSQLite, `"order lines"`},
+	}
+
+	table := &catalog.Table{Schema: "sales", Name: "order lines"}
+	for _, test := range tests {
+		if name := quoteTable(test.dbType, table); name != test.expected {
+			t.Errorf("%s: got %s, expected %s", test.dbType, name, test.expected)
+		}
+	}
+}